@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"monitor/internal/agent"
+	"monitor/internal/buildinfo"
+	"monitor/internal/config"
+	"monitor/internal/scheduler"
+)
+
+// runAgent 执行 agent 子命令：作为远程探测节点运行，探测结果签名上报到中心服务
+//
+// 用法：monitor agent [--config config.yaml]
+// 节点不连接数据库、不发送告警，中心服务地址、节点标识、地域和密钥见配置文件 agent 段
+func runAgent(args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	log.Printf("🚀 Relay Pulse Agent")
+	log.Printf("📦 Version: %s", buildinfo.GetVersion())
+	log.Println()
+
+	loader := config.NewLoader()
+	cfg, err := loader.Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("无法加载配置文件: %w", err)
+	}
+	if cfg.Agent.ServerURL == "" {
+		return fmt.Errorf("agent 模式需要配置 agent.server_url、agent.id、agent.region 和 agent.secret")
+	}
+
+	log.Printf("✅ 已加载 %d 个监控任务，地域 %s", len(cfg.Monitors), cfg.Agent.Region)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interval := cfg.IntervalDuration
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	// 探测结果交给上报器，不写入本地存储
+	pusher := agent.NewPusher(cfg.Agent)
+	sched := scheduler.NewScheduler(nil, interval)
+	sched.SetResultSink(pusher.Add)
+
+	pusherDone := make(chan struct{})
+	go func() {
+		defer close(pusherDone)
+		pusher.Run(ctx, cfg.Agent.FlushIntervalDuration)
+	}()
+
+	sched.Start(ctx, cfg)
+
+	// 配置热更新（仅更新监控项和巡检参数，上报目标修改后需重启）
+	watcher, err := config.NewWatcher(loader, *configFile, func(newCfg *config.AppConfig) {
+		sched.UpdateConfig(newCfg)
+		sched.TriggerNow()
+	})
+	if err != nil {
+		log.Printf("⚠️  配置监听器创建失败: %v (热更新功能不可用)", err)
+	} else if err := watcher.Start(ctx); err != nil {
+		log.Printf("⚠️  配置监听器启动失败: %v (热更新功能不可用)", err)
+	} else {
+		log.Printf("✅ 配置热更新已启用")
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+	log.Println("\n⚠️  收到关闭信号，正在优雅退出...")
+
+	// 先停止探测，再上报剩余结果
+	sched.Stop()
+	cancel()
+	<-pusherDone
+
+	log.Println("👋 探测节点已安全退出")
+	return nil
+}
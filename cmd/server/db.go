@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// runDB 执行 db 子命令
+//
+// 用法：
+//
+//	monitor db migrate [--config config.yaml]  初始化/升级表结构并迁移 channel 数据
+//	monitor db stats   [--config config.yaml]  按监控项统计记录数和时间范围
+func runDB(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("缺少 db 子命令（migrate 或 stats）")
+	}
+
+	action, args := args[0], args[1:]
+	fs := flag.NewFlagSet("db "+action, flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "migrate", "stats":
+	default:
+		return fmt.Errorf("未知的 db 子命令: %s（支持 migrate、stats）", action)
+	}
+
+	cfg, err := config.NewLoader().Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("无法加载配置文件: %w", err)
+	}
+
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("初始化存储失败: %w", err)
+	}
+	defer store.Close()
+
+	if action == "migrate" {
+		return runDBMigrate(store, cfg)
+	}
+	return runDBStats(store)
+}
+
+// runDBMigrate 执行建表、列升级和 channel 数据迁移（与服务启动时相同，可重复执行）
+func runDBMigrate(store storage.Storage, cfg *config.AppConfig) error {
+	if err := store.Init(); err != nil {
+		return fmt.Errorf("初始化数据库失败: %w", err)
+	}
+	if err := store.MigrateChannelData(buildChannelMigrationMappings(cfg.Monitors)); err != nil {
+		return fmt.Errorf("channel 数据迁移失败: %w", err)
+	}
+
+	fmt.Printf("✅ %s 数据库迁移完成\n", cfg.Storage.Type)
+	return nil
+}
+
+// runDBStats 打印数据库记录统计
+func runDBStats(store storage.Storage) error {
+	stats, err := store.Stats()
+	if err != nil {
+		return err
+	}
+
+	formatTS := func(ts int64) string {
+		if ts == 0 {
+			return "-"
+		}
+		return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+	}
+
+	fmt.Printf("总记录数: %d\n", stats.TotalRecords)
+	fmt.Printf("时间范围: %s ~ %s\n", formatTS(stats.Oldest), formatTS(stats.Newest))
+	fmt.Printf("监控项数: %d\n\n", len(stats.Series))
+
+	fmt.Printf("%-24s %-16s %-20s %10s  %-19s  %-19s\n", "PROVIDER", "SERVICE", "CHANNEL", "RECORDS", "OLDEST", "NEWEST")
+	for _, ss := range stats.Series {
+		fmt.Printf("%-24s %-16s %-20s %10d  %-19s  %-19s\n",
+			ss.Provider, ss.Service, ss.Channel, ss.Count, formatTS(ss.Oldest), formatTS(ss.Newest))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/export"
+	"monitor/internal/storage"
+)
+
+// runExport 执行 export 子命令：将原始探测记录导出到文件或标准输出
+//
+// 用法：monitor export [--config config.yaml] [--format csv|ndjson|json]
+//
+//	[--since 2025-01-01] [--until 2025-02-01]
+//	[--provider X] [--service Y] [--channel Z] [--region R] [--output file]
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径")
+	format := fs.String("format", export.FormatCSV, "导出格式：csv、ndjson、json")
+	sinceStr := fs.String("since", "", "起始时间（RFC3339、2006-01-02 或 Unix 秒），默认 until 前 24 小时")
+	untilStr := fs.String("until", "", "结束时间（不含），默认当前时间")
+	provider := fs.String("provider", "", "按 provider 过滤")
+	service := fs.String("service", "", "按 service 过滤")
+	channel := fs.String("channel", "", "按 channel 过滤")
+	region := fs.String("region", "", "按探测地域过滤")
+	output := fs.String("output", "", "输出文件路径，默认标准输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	until, err := export.ParseTime(*untilStr)
+	if err != nil {
+		return fmt.Errorf("--until 无效: %w", err)
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since, err := export.ParseTime(*sinceStr)
+	if err != nil {
+		return fmt.Errorf("--since 无效: %w", err)
+	}
+	if since.IsZero() {
+		since = until.Add(-24 * time.Hour)
+	}
+	if !since.Before(until) {
+		return fmt.Errorf("--since 必须早于 --until")
+	}
+
+	cfg, err := config.NewLoader().Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("无法加载配置文件: %w", err)
+	}
+
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("初始化存储失败: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.Init(); err != nil {
+		return fmt.Errorf("初始化数据库失败: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("创建输出文件失败: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	filter := storage.ExportFilter{
+		Provider: *provider,
+		Service:  *service,
+		Channel:  *channel,
+		Region:   *region,
+		Since:    since,
+		Until:    until,
+	}
+	count, err := export.Export(store, cfg.Monitors, filter, exportFormat, w)
+	if err != nil {
+		return err
+	}
+
+	// 日志输出到 stderr，不污染标准输出中的导出数据
+	log.Printf("✅ 已导出 %d 条探测记录 (%s ~ %s)", count,
+		since.Format(time.RFC3339), until.Format(time.RFC3339))
+	return nil
+}
@@ -1,18 +1,13 @@
 package main
 
 import (
-	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	"monitor/internal/api"
-	"monitor/internal/buildinfo"
 	"monitor/internal/config"
-	"monitor/internal/notifier"
-	"monitor/internal/scheduler"
 	"monitor/internal/storage"
 )
 
@@ -43,171 +38,55 @@ func buildChannelMigrationMappings(monitors []config.ServiceConfig) []storage.Ch
 	return mappings
 }
 
-func main() {
-	// 打印版本信息
-	log.Printf("🚀 Relay Pulse Monitor")
-	log.Printf("📦 Version: %s", buildinfo.GetVersion())
-	log.Printf("🔖 Git Commit: %s", buildinfo.GetGitCommit())
-	log.Printf("🕐 Build Time: %s", buildinfo.GetBuildTime())
-	log.Println()
-	// 配置文件路径
-	configFile := "config.yaml"
-	if len(os.Args) > 1 {
-		configFile = os.Args[1]
-	}
-
-	// 创建配置加载器
-	loader := config.NewLoader()
-
-	// 初始加载配置
-	cfg, err := loader.Load(configFile)
-	if err != nil {
-		log.Fatalf("❌ 无法加载配置文件: %v", err)
-	}
-
-	log.Printf("✅ 已加载 %d 个监控任务", len(cfg.Monitors))
-
-	// 初始化存储（支持 SQLite 和 PostgreSQL）
-	store, err := storage.New(&cfg.Storage)
-	if err != nil {
-		log.Fatalf("❌ 初始化存储失败: %v", err)
-	}
-	defer store.Close()
-
-	if err := store.Init(); err != nil {
-		log.Fatalf("❌ 初始化数据库失败: %v", err)
-	}
-
-	// 自动迁移旧数据的 channel
-	if err := store.MigrateChannelData(buildChannelMigrationMappings(cfg.Monitors)); err != nil {
-		log.Printf("⚠️ channel 数据迁移失败: %v", err)
-	}
-
-	storageType := cfg.Storage.Type
-	if storageType == "" {
-		storageType = "sqlite"
-	}
-	log.Printf("✅ %s 存储已就绪", storageType)
-
-	// 创建上下文（用于优雅关闭）
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// 创建调度器（支持通过 config.yaml 配置 interval）
-	interval := cfg.IntervalDuration
-	if interval <= 0 {
-		interval = time.Minute
-	}
-	sched := scheduler.NewScheduler(store, interval)
-
-	// 初始化通知管理器（可选）
-	if cfg.Notifier.Enabled {
-		notifierMgr, err := notifier.NewManager(&cfg.Notifier)
-		if err != nil {
-			log.Printf("⚠️ 通知管理器初始化失败: %v", err)
-		} else {
-			sched.SetNotifier(notifierMgr)
-			log.Printf("✅ 企业微信告警已启用")
-		}
-	}
-
-	sched.Start(ctx, cfg)
-
-	// 创建API服务器
-	server := api.NewServer(store, cfg, "8081")
-
-	// 启动配置监听器（热更新）
-	watcher, err := config.NewWatcher(loader, configFile, func(newCfg *config.AppConfig) {
-		// 配置热更新回调
-		sched.UpdateConfig(newCfg)
-		server.UpdateConfig(newCfg)
-
-		// 热更新通知器
-		if newCfg.Notifier.Enabled && sched.GetNotifier() == nil {
-			// 新启用告警
-			notifierMgr, err := notifier.NewManager(&newCfg.Notifier)
-			if err != nil {
-				log.Printf("⚠️ 热更新时通知管理器初始化失败: %v", err)
-			} else {
-				sched.SetNotifier(notifierMgr)
-				log.Printf("✅ 企业微信告警已启用（热更新）")
-			}
-		} else if !newCfg.Notifier.Enabled && sched.GetNotifier() != nil {
-			// 关闭告警
-			if err := sched.GetNotifier().Close(); err != nil {
-				log.Printf("⚠️ 关闭通知管理器失败: %v", err)
-			}
-			sched.SetNotifier(nil)
-			log.Printf("⚠️ 企业微信告警已禁用（热更新）")
-		}
-
-		// 重新运行 channel 迁移（支持运行时添加 channel）
-		if err := store.MigrateChannelData(buildChannelMigrationMappings(newCfg.Monitors)); err != nil {
-			log.Printf("⚠️ 热更新时 channel 迁移失败: %v", err)
-		}
-
-		// 立即触发一次巡检，确保新配置立即生效
-		sched.TriggerNow()
-	})
+// usage 子命令说明
+const usage = `Relay Pulse Monitor
+
+用法:
+  monitor <命令> [参数]
+
+命令:
+  serve       启动探测调度器和 HTTP 服务（默认命令）
+  agent       作为远程探测节点运行，探测结果上报到中心服务
+  validate    校验配置文件并打印解析后的监控项（敏感信息已脱敏）
+  probe       立即探测单个监控项
+  export      导出原始探测记录（CSV / NDJSON / JSON）
+  db migrate  初始化/升级数据库表结构并迁移 channel 数据
+  db stats    查看数据库记录统计
+  version     打印版本信息
+
+使用 "monitor <命令> -h" 查看命令参数。
+兼容旧用法: "monitor [config.yaml]" 等同于 "monitor serve --config config.yaml"
+`
+
+// commands 子命令表
+var commands = map[string]func(args []string) error{
+	"serve":    runServe,
+	"agent":    runAgent,
+	"validate": runValidate,
+	"probe":    runProbe,
+	"export":   runExport,
+	"db":       runDB,
+	"version":  runVersion,
+}
 
-	if err != nil {
-		log.Printf("⚠️  配置监听器创建失败: %v (热更新功能不可用)", err)
-	} else {
-		if err := watcher.Start(ctx); err != nil {
-			log.Printf("⚠️  配置监听器启动失败: %v (热更新功能不可用)", err)
-		} else {
-			log.Printf("✅ 配置热更新已启用")
+func main() {
+	args := os.Args[1:]
+
+	// 未指定子命令（或第一个参数是配置文件路径 / serve 参数）时默认执行 serve
+	name := "serve"
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		} else if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Print(usage)
+			return
 		}
 	}
 
-	// 启动定期清理任务（保留30天数据）
-	go func() {
-		ticker := time.NewTicker(24 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if err := store.CleanOldRecords(30); err != nil {
-					log.Printf("⚠️  清理旧记录失败: %v", err)
-				}
-			}
+	if err := commands[name](args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
 		}
-	}()
-
-	// 监听中断信号（优雅关闭）
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	// 启动HTTP服务器（阻塞）
-	go func() {
-		if err := server.Start(); err != nil {
-			log.Printf("❌ HTTP服务器错误: %v", err)
-			cancel()
-			// 向信号通道发送信号，确保进程退出
-			sigChan <- syscall.SIGTERM
-		}
-	}()
-
-	// 等待中断信号
-	<-sigChan
-	log.Println("\n⚠️  收到关闭信号，正在优雅退出...")
-
-	// 取消上下文
-	cancel()
-
-	// 停止调度器
-	sched.Stop()
-
-	// 停止HTTP服务器
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-
-	if err := server.Stop(shutdownCtx); err != nil {
-		log.Printf("⚠️  HTTP服务器关闭错误: %v", err)
+		log.Fatalf("❌ %s 失败: %v", name, err)
 	}
-
-	log.Println("👋 服务已安全退出")
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"monitor/internal/api"
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+// runProbe 执行 probe 子命令：立即探测单个监控项并输出结果
+//
+// 用法：monitor probe [--config config.yaml] --provider X --service Y [--channel Z] [--persist] [--json]
+func runProbe(args []string) error {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径")
+	provider := fs.String("provider", "", "监控项 provider（必填）")
+	service := fs.String("service", "", "监控项 service（必填）")
+	channel := fs.String("channel", "", "监控项 channel")
+	persist := fs.Bool("persist", false, "将结果写入数据库（与周期巡检一致）")
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出结果")
+	timeout := fs.Duration("timeout", 60*time.Second, "探测超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *provider == "" || *service == "" {
+		return fmt.Errorf("--provider 和 --service 不能为空")
+	}
+
+	cfg, err := config.NewLoader().Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("无法加载配置文件: %w", err)
+	}
+
+	key := config.MonitorKey{Provider: *provider, Service: *service, Channel: *channel}
+	var task *config.ServiceConfig
+	for i := range cfg.Monitors {
+		if config.KeyOf(cfg.Monitors[i]) == key {
+			task = &cfg.Monitors[i]
+			break
+		}
+	}
+	if task == nil {
+		return fmt.Errorf("监控项不存在或已停用: %s", key)
+	}
+
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("初始化存储失败: %w", err)
+	}
+	defer store.Close()
+
+	if *persist {
+		if err := store.Init(); err != nil {
+			return fmt.Errorf("初始化数据库失败: %w", err)
+		}
+	}
+
+	prober := monitor.NewProber(store)
+	defer prober.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := prober.Probe(ctx, task)
+	duration := time.Since(start)
+
+	var saveErr error
+	if *persist {
+		saveErr = prober.SaveResult(result)
+	}
+
+	report := api.NewProbeReport(result, duration, *persist, saveErr)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printProbeReport(report)
+	}
+
+	if saveErr != nil {
+		return fmt.Errorf("保存探测结果失败: %w", saveErr)
+	}
+	return nil
+}
+
+// printProbeReport 以可读格式输出探测结果
+func printProbeReport(r api.ProbeReport) {
+	statusText := map[int]string{1: "🟢 可用", 2: "🟡 波动", 0: "🔴 不可用"}[r.Status]
+	if statusText == "" {
+		statusText = fmt.Sprintf("未知(%d)", r.Status)
+	}
+
+	fmt.Printf("监控项:   %s / %s / %s\n", r.Provider, r.Service, r.Channel)
+	fmt.Printf("状态:     %s\n", statusText)
+	if r.SubStatus != "" {
+		fmt.Printf("细分原因: %s\n", r.SubStatus)
+	}
+	if r.HTTPStatus > 0 {
+		fmt.Printf("HTTP:     %d\n", r.HTTPStatus)
+	}
+	if r.Error != "" {
+		fmt.Printf("错误:     %s\n", r.Error)
+	}
+	fmt.Printf("延迟:     %d ms（总耗时 %d ms）\n", r.Latency, r.DurationMs)
+	fmt.Printf("时间:     %s\n", time.Unix(r.Timestamp, 0).Format(time.RFC3339))
+	fmt.Printf("已保存:   %v\n", r.Persisted)
+	if r.ResponseSnippet != "" {
+		fmt.Printf("响应:     %s\n", strings.ReplaceAll(r.ResponseSnippet, "\n", " "))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// revisionRecorder 记录每次生效配置与上一版本的差异（存储支持 RevisionStore 时写入配置修订历史）
+type revisionRecorder struct {
+	store  storage.RevisionStore // 存储不支持时为 nil，仅输出日志
+	nodeID string
+
+	mu   sync.Mutex
+	last *config.AppConfig // 上一次生效的配置
+}
+
+// newRevisionRecorder 创建修订记录器，initial 为启动时加载的配置
+func newRevisionRecorder(store storage.Storage, initial *config.AppConfig) *revisionRecorder {
+	r := &revisionRecorder{nodeID: initial.Cluster.NodeID, last: initial}
+	if rs, ok := store.(storage.RevisionStore); ok {
+		r.store = rs
+	}
+	return r
+}
+
+// recordStartup 启动时配置版本与最近一次记录不同（含首次启动）时写入一条 startup 修订
+func (r *revisionRecorder) recordStartup() {
+	if r.store == nil {
+		return
+	}
+	latest, err := r.store.ListRevisions(1)
+	if err != nil {
+		log.Printf("⚠️ 查询配置修订历史失败: %v", err)
+		return
+	}
+	if len(latest) > 0 && latest[0].Version == r.last.Version {
+		return
+	}
+	r.save(&storage.ConfigRevision{
+		Version: r.last.Version,
+		Source:  storage.RevisionSourceStartup,
+		Summary: fmt.Sprintf("服务启动，已加载 %d 个监控项", len(r.last.Monitors)),
+	})
+}
+
+// record 记录新配置生效（source 为 reload 或 admin），返回与上一版本的差异
+// 配置内容无变化（如只调整了注释）时不写入修订记录
+func (r *revisionRecorder) record(source string, newCfg *config.AppConfig) *config.ConfigDiff {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	diff := config.Diff(r.last, newCfg)
+	r.last = newCfg
+	if diff.Empty() || r.store == nil {
+		return diff
+	}
+	r.save(&storage.ConfigRevision{
+		Version: newCfg.Version,
+		Source:  source,
+		Summary: diff.Summary(),
+		Diff:    diff.JSON(),
+	})
+	return diff
+}
+
+func (r *revisionRecorder) save(rev *storage.ConfigRevision) {
+	rev.NodeID = r.nodeID
+	rev.CreatedAt = time.Now()
+	if err := r.store.SaveRevision(rev); err != nil {
+		log.Printf("⚠️ 保存配置修订失败: %v", err)
+	}
+}
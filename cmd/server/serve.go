@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"monitor/internal/api"
+	"monitor/internal/buildinfo"
+	"monitor/internal/cluster"
+	"monitor/internal/config"
+	"monitor/internal/notifier"
+	"monitor/internal/scheduler"
+	"monitor/internal/storage"
+)
+
+// runServe 执行 serve 子命令：启动调度器和 HTTP 服务（默认命令）
+//
+// 用法：monitor serve [--config config.yaml] [--listen :8081] [--no-scheduler] [--no-api] [config.yaml]
+// 监听地址、TLS、Unix socket 和超时见配置文件 server 段，--listen 仅覆盖 TCP 监听地址
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径（也可作为位置参数传入）")
+	listen := fs.String("listen", "", "HTTP 监听地址（覆盖配置文件中的 server.listen）")
+	noScheduler := fs.Bool("no-scheduler", false, "不运行探测调度器（只读 API 副本）")
+	noAPI := fs.Bool("no-api", false, "不启动 HTTP 服务（仅探测）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// 兼容旧用法：monitor config.yaml
+	if fs.NArg() > 0 {
+		*configFile = fs.Arg(0)
+	}
+	if *noScheduler && *noAPI {
+		return fmt.Errorf("--no-scheduler 与 --no-api 不能同时使用")
+	}
+
+	// 打印版本信息
+	log.Printf("🚀 Relay Pulse Monitor")
+	log.Printf("📦 Version: %s", buildinfo.GetVersion())
+	log.Printf("🔖 Git Commit: %s", buildinfo.GetGitCommit())
+	log.Printf("🕐 Build Time: %s", buildinfo.GetBuildTime())
+	log.Println()
+
+	// 创建配置加载器
+	loader := config.NewLoader()
+
+	// 初始加载配置
+	cfg, err := loader.Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("无法加载配置文件: %w", err)
+	}
+
+	log.Printf("✅ 已加载 %d 个监控任务", len(cfg.Monitors))
+
+	// 配置修订以配置文件内容为准，不含命令行覆盖
+	fileCfg := cfg.Clone()
+
+	if *listen != "" {
+		cfg.Server.Listen = *listen
+	}
+
+	// 初始化存储（支持 SQLite 和 PostgreSQL）
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("初始化存储失败: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.Init(); err != nil {
+		return fmt.Errorf("初始化数据库失败: %w", err)
+	}
+
+	// 自动迁移旧数据的 channel
+	if err := store.MigrateChannelData(buildChannelMigrationMappings(cfg.Monitors)); err != nil {
+		log.Printf("⚠️ channel 数据迁移失败: %v", err)
+	}
+
+	storageType := cfg.Storage.Type
+	if storageType == "" {
+		storageType = "sqlite"
+	}
+	log.Printf("✅ %s 存储已就绪", storageType)
+
+	// 创建上下文（用于优雅关闭）
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 创建调度器（支持通过 config.yaml 配置 interval）
+	// --no-scheduler 时不启动调度器，管理 API 的即时探测返回 503（需发往运行调度器的副本）
+	interval := cfg.IntervalDuration
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	sched := scheduler.NewScheduler(store, interval)
+
+	// 初始化通知管理器（可选）
+	if cfg.Notifier.Enabled {
+		notifierMgr, err := notifier.NewManager(&cfg.Notifier)
+		if err != nil {
+			log.Printf("⚠️ 通知管理器初始化失败: %v", err)
+		} else {
+			sched.SetNotifier(notifierMgr)
+			log.Printf("✅ 企业微信告警已启用")
+		}
+	}
+
+	// 多副本部署：主备模式仅主节点巡检；分片模式各节点只巡检自己的分片
+	// ownsMaintenance 判断当前节点是否负责清理旧记录等维护任务
+	var clusterNode interface {
+		Run(ctx context.Context)
+		Status() cluster.Status
+	}
+	ownsMaintenance := func() bool { return true }
+
+	if cfg.Cluster.Mode != config.ClusterModeSingle && !*noScheduler {
+		switch cfg.Cluster.Mode {
+		case config.ClusterModeLeader:
+			leaseStore, ok := store.(storage.LeaseStore)
+			if !ok {
+				return fmt.Errorf("cluster.mode=%s 需要支持租约的存储（PostgreSQL）", cfg.Cluster.Mode)
+			}
+			sched.SetStandby(true) // 选主成功前不巡检
+			elector := cluster.NewElector(leaseStore, cfg.Cluster.NodeID, cfg.Cluster.LeaseTTLDuration, func(isLeader bool) {
+				sched.SetStandby(!isLeader)
+			})
+			clusterNode, ownsMaintenance = elector, elector.IsLeader
+
+		case config.ClusterModeShard:
+			memberStore, ok := store.(storage.MemberStore)
+			if !ok {
+				return fmt.Errorf("cluster.mode=%s 需要支持成员心跳的存储（PostgreSQL）", cfg.Cluster.Mode)
+			}
+			sharder := cluster.NewSharder(memberStore, cfg.Cluster.NodeID, cfg.Cluster.LeaseTTLDuration)
+			sched.SetTaskFilter(func(m config.ServiceConfig) bool {
+				return sharder.Owns(config.KeyOf(m).String())
+			})
+			clusterNode = sharder
+			ownsMaintenance = func() bool { return sharder.Owns(cluster.MaintenanceKey) }
+		}
+	}
+
+	clusterDone := make(chan struct{})
+	if clusterNode != nil {
+		go func() {
+			defer close(clusterDone)
+			clusterNode.Run(ctx)
+		}()
+	} else {
+		close(clusterDone)
+	}
+
+	if *noScheduler {
+		log.Printf("⚠️ 已禁用探测调度器（--no-scheduler）")
+	} else {
+		sched.Start(ctx, cfg)
+	}
+
+	// 创建API服务器
+	server := api.NewServer(store, cfg)
+
+	// 配置修订历史（与上一版本的差异写入存储，供管理 API 查询）
+	revisions := newRevisionRecorder(store, fileCfg)
+	revisions.recordStartup()
+
+	// 配置生效回调（文件热更新和管理 API 共用，串行执行），source 为变更来源
+	var applyMu sync.Mutex
+	applyConfig := func(source string, newCfg *config.AppConfig) {
+		applyMu.Lock()
+		defer applyMu.Unlock()
+
+		// 文件热更新的差异由 Watcher 输出日志，这里只补充管理 API 的变更
+		diff := revisions.record(source, newCfg)
+		if source == storage.RevisionSourceAdmin {
+			for _, line := range diff.Lines() {
+				log.Printf("[Admin]   %s", line)
+			}
+		}
+
+		sched.UpdateConfig(newCfg)
+		server.UpdateConfig(newCfg)
+
+		// 热更新通知器
+		if newCfg.Notifier.Enabled && sched.GetNotifier() == nil {
+			// 新启用告警
+			notifierMgr, err := notifier.NewManager(&newCfg.Notifier)
+			if err != nil {
+				log.Printf("⚠️ 热更新时通知管理器初始化失败: %v", err)
+			} else {
+				sched.SetNotifier(notifierMgr)
+				log.Printf("✅ 企业微信告警已启用（热更新）")
+			}
+		} else if !newCfg.Notifier.Enabled && sched.GetNotifier() != nil {
+			// 关闭告警
+			if err := sched.GetNotifier().Close(); err != nil {
+				log.Printf("⚠️ 关闭通知管理器失败: %v", err)
+			}
+			sched.SetNotifier(nil)
+			log.Printf("⚠️ 企业微信告警已禁用（热更新）")
+		}
+
+		// 重新运行 channel 迁移（支持运行时添加 channel）
+		if err := store.MigrateChannelData(buildChannelMigrationMappings(newCfg.Monitors)); err != nil {
+			log.Printf("⚠️ 热更新时 channel 迁移失败: %v", err)
+		}
+
+		// 立即触发一次巡检，确保新配置立即生效（调度器未运行时为空操作）
+		sched.TriggerNow()
+	}
+
+	// 管理 API（配置 admin.token 后可用）
+	server.EnableAdmin(config.NewEditor(loader, *configFile), func(newCfg *config.AppConfig) {
+		applyConfig(storage.RevisionSourceAdmin, newCfg)
+	})
+	server.SetProber(sched)
+	server.SetScheduler(sched)
+	if clusterNode != nil {
+		server.SetCluster(clusterNode)
+	}
+
+	// 启动配置监听器（热更新）
+	watcher, err := config.NewWatcher(loader, *configFile, func(newCfg *config.AppConfig) {
+		applyConfig(storage.RevisionSourceReload, newCfg)
+	})
+
+	if err != nil {
+		log.Printf("⚠️  配置监听器创建失败: %v (热更新功能不可用)", err)
+	} else {
+		// 重载失败时通过告警渠道通知（多副本部署时仅由负责维护任务的节点发送）
+		watcher.SetOnError(func(reloadErr error) {
+			if ownsMaintenance() {
+				sched.GetNotifier().NotifyConfigError(ctx, *configFile, reloadErr)
+			}
+		})
+		if err := watcher.Start(ctx); err != nil {
+			log.Printf("⚠️  配置监听器启动失败: %v (热更新功能不可用)", err)
+		} else {
+			log.Printf("✅ 配置热更新已启用")
+		}
+	}
+
+	// 启动定期清理任务（保留30天数据，由负责写入的调度器实例执行，多副本部署时仅由一个节点执行）
+	if !*noScheduler {
+		go func() {
+			ticker := time.NewTicker(24 * time.Hour)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if !ownsMaintenance() {
+						continue
+					}
+					if err := store.CleanOldRecords(30); err != nil {
+						log.Printf("⚠️  清理旧记录失败: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	// 监听中断信号（优雅关闭）
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// 启动HTTP服务器
+	if *noAPI {
+		log.Printf("⚠️ 已禁用 HTTP 服务（--no-api）")
+	} else {
+		go func() {
+			if err := server.Start(); err != nil {
+				log.Printf("❌ HTTP服务器错误: %v", err)
+				cancel()
+				// 向信号通道发送信号，确保进程退出
+				sigChan <- syscall.SIGTERM
+			}
+		}()
+	}
+
+	// 等待中断信号
+	<-sigChan
+	log.Println("\n⚠️  收到关闭信号，正在优雅退出...")
+
+	// 取消上下文
+	cancel()
+
+	// 停止调度器
+	sched.Stop()
+
+	// 等待释放租约/注销节点（便于其他副本立即接管）
+	select {
+	case <-clusterDone:
+	case <-time.After(5 * time.Second):
+		log.Printf("⚠️  退出集群超时")
+	}
+
+	// 停止HTTP服务器
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := server.Stop(shutdownCtx); err != nil {
+		log.Printf("⚠️  HTTP服务器关闭错误: %v", err)
+	}
+
+	log.Println("👋 服务已安全退出")
+	return nil
+}
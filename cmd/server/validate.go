@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"monitor/internal/config"
+)
+
+// runValidate 执行 validate 子命令：按服务启动时相同的流程加载配置，
+// 打印解析后的全局配置和监控项（敏感信息脱敏），校验失败时一次列出全部错误并返回错误（退出码非 0，便于 CI 使用）
+//
+// 用法：monitor validate [--config config.yaml] [--unknown-fields strict|warn] [--schema] [config.yaml]
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFile := fs.String("config", "config.yaml", "配置文件路径（也可作为位置参数传入）")
+	quiet := fs.Bool("quiet", false, "只输出校验结果，不打印配置详情")
+	unknownFields := fs.String("unknown-fields", "", "未知字段处理方式：strict（报错）或 warn（仅警告），默认读取 MONITOR_CONFIG_UNKNOWN_FIELDS，未设置时为 strict")
+	schema := fs.Bool("schema", false, "输出配置文件的 JSON Schema 后退出（不读取配置文件）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		*configFile = fs.Arg(0)
+	}
+
+	if *schema {
+		data, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	loader := config.NewLoader()
+	if *unknownFields != "" {
+		mode, err := config.ParseUnknownFieldMode(*unknownFields)
+		if err != nil {
+			return err
+		}
+		loader.SetUnknownFieldMode(mode)
+	}
+
+	cfg, err := loader.Load(*configFile)
+	if err != nil {
+		var errs config.ValidationErrors
+		if errors.As(err, &errs) && len(errs) > 1 {
+			fmt.Printf("❌ 配置无效: %s（共 %d 处错误）\n", *configFile, len(errs))
+			for _, e := range errs {
+				fmt.Printf("  - %v\n", e)
+			}
+			return fmt.Errorf("发现 %d 处配置错误", len(errs))
+		}
+		return err
+	}
+
+	if !*quiet {
+		printResolvedConfig(cfg)
+	}
+	for _, warning := range cfg.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+	fmt.Printf("✅ 配置有效: %s（%d 个监控项）\n", *configFile, len(cfg.Monitors))
+	return nil
+}
+
+// printResolvedConfig 打印规范化后的配置（默认值已填充，占位符已替换，敏感信息脱敏）
+func printResolvedConfig(cfg *config.AppConfig) {
+	secrets := configSecrets(cfg)
+
+	fmt.Println("全局配置:")
+	if cfg.IncludeDir != "" {
+		fmt.Printf("  include_dir:             %s（%d 个文件: %s）\n", cfg.IncludeDir, len(cfg.IncludeFiles), strings.Join(cfg.IncludeFiles, ", "))
+	}
+	fmt.Printf("  interval:                %v\n", cfg.IntervalDuration)
+	fmt.Printf("  slow_latency:            %v\n", cfg.SlowLatencyDuration)
+	fmt.Printf("  degraded_weight:         %.2f\n", cfg.DegradedWeight)
+	fmt.Printf("  max_concurrency:         %d\n", cfg.MaxConcurrency)
+	if cfg.MaxConcurrencyPerHost > 0 || cfg.MaxConcurrencyPerProvider > 0 || cfg.HostMinSpacingDuration > 0 {
+		fmt.Printf("  per-host limits:         host=%d provider=%d spacing=%v\n",
+			cfg.MaxConcurrencyPerHost, cfg.MaxConcurrencyPerProvider, cfg.HostMinSpacingDuration)
+	}
+	fmt.Printf("  stagger_probes:          %v\n", cfg.ShouldStaggerProbes())
+	if cfg.StaggerJitterDuration > 0 {
+		fmt.Printf("  stagger_jitter:          ±%v\n", cfg.StaggerJitterDuration)
+	}
+	fmt.Printf("  enable_concurrent_query: %v (limit %d)\n", cfg.EnableConcurrentQuery, cfg.ConcurrentQueryLimit)
+	fmt.Printf("  public_base_url:         %s\n", cfg.PublicBaseURL)
+	fmt.Printf("  admin.token:             %s\n", maskSecret(cfg.Admin.Token))
+	providers := make([]string, 0, len(cfg.ProviderBudgets))
+	for provider := range cfg.ProviderBudgets {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		fmt.Printf("  provider_budgets:        %s %d 次/小时\n", provider, cfg.ProviderBudgets[provider])
+	}
+
+	switch cfg.Storage.Type {
+	case "postgres":
+		pg := cfg.Storage.Postgres
+		fmt.Printf("  storage:                 postgres %s@%s:%d/%s (sslmode=%s, password=%s)\n",
+			pg.User, pg.Host, pg.Port, pg.Database, pg.SSLMode, maskSecret(pg.Password))
+	default:
+		fmt.Printf("  storage:                 sqlite %s\n", cfg.Storage.SQLite.Path)
+	}
+
+	fmt.Printf("  notifier:                enabled=%v wecom=%v webhook=%s\n",
+		cfg.Notifier.Enabled, cfg.Notifier.WeCom.Enabled, maskSecret(cfg.Notifier.WeCom.WebhookURL))
+	for _, r := range cfg.Notifier.Routes {
+		fmt.Printf("  notifier route:          %s match=%s wecom=%v webhook=%s continue=%v\n",
+			r.Name, config.FormatLabels(r.MatchLabels), r.WeCom.Enabled, maskSecret(r.WeCom.WebhookURL), r.Continue)
+	}
+	fmt.Println()
+
+	fmt.Println("监控项:")
+	for i, m := range cfg.Monitors {
+		fmt.Printf("  [%d] %s / %s / %s\n", i, m.Provider, m.Service, m.Channel)
+		fmt.Printf("      provider_slug:    %s\n", m.ProviderSlug)
+		if m.Extends != "" {
+			fmt.Printf("      extends:          %s\n", m.Extends)
+		}
+		fmt.Printf("      category:         %s\n", m.Category)
+		fmt.Printf("      sponsor:          %s\n", m.Sponsor)
+		if len(m.Labels) > 0 {
+			fmt.Printf("      labels:           %s\n", config.FormatLabels(m.Labels))
+		}
+		fmt.Printf("      request:          %s %s\n", strings.ToUpper(m.Method), maskValue(m.URL, secrets))
+		fmt.Printf("      api_key:          %s\n", maskSecret(m.APIKey))
+		fmt.Printf("      slow_latency:     %v\n", m.SlowLatencyDuration)
+		if n := m.ConfirmPolicy.RetryCount(); n > 0 {
+			fmt.Printf("      confirm:          %d 次，间隔 %v（%s）\n", n, m.ConfirmPolicy.DelayDuration, strings.Join(m.ConfirmPolicy.SubStatuses, ", "))
+		}
+		if p := m.AdaptivePolicy; p.IncidentIntervalDuration > 0 || p.BackoffIntervalDuration > 0 {
+			fmt.Printf("      adaptive:         故障 %v，连续绿色 %v 后 %v\n", p.IncidentIntervalDuration, p.BackoffAfterDuration, p.BackoffIntervalDuration)
+		}
+		if sc := m.Schedule; sc != nil {
+			fmt.Printf("      schedule:         cron=%q timezone=%s active_hours=%q active_days=%v\n",
+				sc.Cron, m.ScheduleSpec.Location(), sc.ActiveHours, sc.ActiveDays)
+		}
+		if m.SuccessContains != "" {
+			fmt.Printf("      success_contains: %q\n", m.SuccessContains)
+		}
+		if len(m.Vars) > 0 {
+			names := make([]string, 0, len(m.Vars))
+			for name := range m.Vars {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("      vars:             %s\n", strings.Join(names, ", ")) // 只打印名称，值可能是密钥
+		}
+
+		if len(m.Headers) > 0 {
+			names := make([]string, 0, len(m.Headers))
+			for name := range m.Headers {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			fmt.Println("      headers:")
+			for _, name := range names {
+				fmt.Printf("        %s: %s\n", name, maskHeader(name, m.Headers[name], secrets))
+			}
+		}
+
+		if m.Body != "" {
+			body := maskValue(m.Body, secrets)
+			if len(body) > 200 {
+				body = body[:200] + "..."
+			}
+			fmt.Printf("      body (%d bytes):  %s\n", len(m.Body), strings.ReplaceAll(body, "\n", " "))
+		}
+	}
+	fmt.Println()
+}
+
+// sensitiveHeaders 值需要整体脱敏的请求头（小写）
+var sensitiveHeaders = map[string]bool{
+	"authorization": true,
+	"x-api-key":     true,
+	"api-key":       true,
+	"cookie":        true,
+}
+
+// minMaskedLen 参与文本替换的最短密钥长度（过短的值如占位的 "x" 容易误伤普通内容）
+const minMaskedLen = 6
+
+// configSecrets 收集需要在输出中脱敏的值：${VAR} 和 file: 解析出的值、各监控项的 api_key 和 vars 值，以及全局密钥
+// 按长度从长到短排序，避免较短的密钥先替换掉较长密钥的一部分
+func configSecrets(cfg *config.AppConfig) []string {
+	seen := make(map[string]bool)
+	var secrets []string
+	add := func(values ...string) {
+		for _, v := range values {
+			if len(v) >= minMaskedLen && !seen[v] {
+				seen[v] = true
+				secrets = append(secrets, v)
+			}
+		}
+	}
+
+	add(cfg.ResolvedSecrets...)
+	add(cfg.Admin.Token, cfg.Storage.Postgres.Password, cfg.Notifier.WeCom.WebhookURL, cfg.Agent.Secret)
+	for _, a := range cfg.Ingest.Agents {
+		add(a.Secret)
+	}
+	for _, m := range cfg.Monitors {
+		add(m.APIKey)
+		for _, v := range m.Vars {
+			add(v)
+		}
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// maskHeader 脱敏请求头：敏感头保留认证方案（如 Bearer），其他头仅替换其中的密钥
+func maskHeader(name, value string, secrets []string) string {
+	if !sensitiveHeaders[strings.ToLower(name)] {
+		return maskValue(value, secrets)
+	}
+	if scheme, secret, ok := strings.Cut(value, " "); ok {
+		return scheme + " " + maskSecret(secret)
+	}
+	return maskSecret(value)
+}
+
+// maskValue 将文本中出现的密钥替换为脱敏形式
+func maskValue(value string, secrets []string) string {
+	for _, secret := range secrets {
+		value = strings.ReplaceAll(value, secret, maskSecret(secret))
+	}
+	return value
+}
+
+// maskSecret 脱敏敏感值：仅保留末尾 4 位（过短时全部隐藏）
+func maskSecret(secret string) string {
+	switch {
+	case secret == "":
+		return "(未设置)"
+	case len(secret) < 12:
+		return "****"
+	default:
+		return "****" + secret[len(secret)-4:]
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	"monitor/internal/buildinfo"
+)
+
+// runVersion 执行 version 子命令：打印版本信息
+func runVersion(args []string) error {
+	fmt.Printf("Version:    %s\n", buildinfo.GetVersion())
+	fmt.Printf("Git Commit: %s\n", buildinfo.GetGitCommit())
+	fmt.Printf("Build Time: %s\n", buildinfo.GetBuildTime())
+	fmt.Printf("Go Version: %s\n", buildinfo.GetGoVersion())
+	return nil
+}
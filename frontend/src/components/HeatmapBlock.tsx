@@ -41,12 +41,15 @@ export function HeatmapBlock({
     onHover(mouseEvent, point);
   };
 
+  // 探测日程之外的时间块：透明虚线框，区别于灰色的"无数据"
+  const notScheduled = point.status === 'NOT_SCHEDULED';
+
   return (
     <div
       role="button"
       tabIndex={0}
-      className={`${height} rounded-sm transition-all duration-200 hover:scale-110 active:scale-105 hover:z-10 cursor-pointer opacity-80 hover:opacity-100 active:opacity-100`}
-      style={{ width, ...availabilityToStyle(point.availability) }}
+      className={`${height} rounded-sm transition-all duration-200 hover:scale-110 active:scale-105 hover:z-10 cursor-pointer opacity-80 hover:opacity-100 active:opacity-100 ${notScheduled ? 'border border-dashed border-slate-600' : ''}`}
+      style={notScheduled ? { width } : { width, ...availabilityToStyle(point.availability) }}
       // 鼠标事件（仅桌面端，移动端禁用避免闪烁）
       onMouseEnter={isMobile ? undefined : (e) => onHover(e, point)}
       onMouseLeave={isMobile ? undefined : onLeave}
@@ -58,9 +61,11 @@ export function HeatmapBlock({
       onBlur={isMobile ? undefined : onLeave}
       // 无障碍标签
       aria-label={
-        point.availability >= 0
-          ? t('accessibility.uptimeBlock', { uptime: point.availability.toFixed(1) })
-          : t('accessibility.noDataBlock')
+        notScheduled
+          ? t('accessibility.notScheduledBlock')
+          : point.availability >= 0
+            ? t('accessibility.uptimeBlock', { uptime: point.availability.toFixed(1) })
+            : t('accessibility.noDataBlock')
       }
     />
   );
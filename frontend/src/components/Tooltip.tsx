@@ -68,6 +68,11 @@ export function Tooltip({ tooltip, slowLatencyMs, onClose }: TooltipProps) {
       <div className="text-slate-400 text-center">
         {new Date(tooltip.data!.timestampNum * 1000).toLocaleString(i18n.language)}
       </div>
+      {tooltip.data!.status === 'NOT_SCHEDULED' && (
+        <div className="text-slate-500 text-center text-[11px]">
+          {t('tooltip.notScheduled')}
+        </div>
+      )}
       {tooltip.data!.availability >= 0 && (
         <div
           className="font-medium text-center text-sm md:text-xs"
@@ -46,6 +46,13 @@ export const STATUS: Record<string, StatusConfig> = {
     label: '无数据',
     weight: 1,  // 算作可用（避免初期可用率过低）
   },
+  NOT_SCHEDULED: {
+    color: 'bg-slate-700',
+    text: 'text-slate-500',
+    glow: '',
+    label: '未排期',
+    weight: 1,
+  },
   UNAVAILABLE: {
     color: 'bg-slate-500',
     text: 'text-slate-400',
@@ -78,6 +85,13 @@ export const getStatusConfig = (t: TFunction): Record<string, StatusConfig> => (
     label: t('status.missing'),
     weight: 1,  // 算作可用（避免初期可用率过低）
   },
+  NOT_SCHEDULED: {
+    color: 'bg-slate-700',
+    text: 'text-slate-500',
+    glow: '',
+    label: t('status.notScheduled'),
+    weight: 1,
+  },
   UNAVAILABLE: {
     color: 'bg-slate-500',
     text: 'text-slate-400',
@@ -104,6 +118,11 @@ export const STATUS_COLORS = {
     text: 'text-slate-400',
     glow: 'shadow-[0_0_10px_rgba(148,163,184,0.4)]',
   },
+  NOT_SCHEDULED: {
+    color: 'bg-slate-700',
+    text: 'text-slate-500',
+    glow: '',
+  },
   UNAVAILABLE: {
     color: 'bg-slate-500',
     text: 'text-slate-400',
@@ -43,6 +43,7 @@ const statusMap: Record<number, StatusKey> = {
   0: 'UNAVAILABLE',
   3: 'MISSING',  // 未配置/认证失败
   '-1': 'MISSING',  // 缺失数据
+  '-2': 'NOT_SCHEDULED',  // 探测日程之外，未安排探测
 };
 
 // 映射状态计数，提供默认值以向后兼容
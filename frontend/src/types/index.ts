@@ -2,7 +2,7 @@
 export interface TimePoint {
   time: string;         // 格式化时间标签（如 "15:04" 或 "2006-01-02"）
   timestamp: number;    // Unix 时间戳（秒）
-  status: number;       // 1=可用, 0=不可用, 2=波动, -1=缺失（bucket内最后一条）
+  status: number;       // 1=可用, 0=不可用, 2=波动, -1=缺失, -2=未排期（bucket内最后一条）
   latency: number;      // 平均延迟(ms)
   availability: number; // 可用率百分比(0-100)，缺失时为 -1
   status_counts?: StatusCounts; // 各状态计数（可选，向后兼容）
@@ -42,6 +42,7 @@ export interface MonitorResult {
   sponsor: string;                     // 赞助者
   sponsor_url?: string;                // 赞助者链接
   channel: string;                     // 业务通道标识
+  labels?: Record<string, string>;     // 自定义标签（如 tier: vip）
   current_status: CurrentStatus | null;
   timeline: TimePoint[];
 }
@@ -56,7 +57,7 @@ export interface ApiResponse {
 }
 
 // 前端状态枚举
-export type StatusKey = 'AVAILABLE' | 'DEGRADED' | 'UNAVAILABLE' | 'MISSING';
+export type StatusKey = 'AVAILABLE' | 'DEGRADED' | 'UNAVAILABLE' | 'MISSING' | 'NOT_SCHEDULED';
 
 export interface StatusConfig {
   color: string;
@@ -71,6 +72,7 @@ export const STATUS_MAP: Record<number, StatusKey> = {
   2: 'DEGRADED',
   0: 'UNAVAILABLE',
   '-1': 'MISSING',  // 缺失数据
+  '-2': 'NOT_SCHEDULED',  // 探测日程之外，未安排探测
 };
 
 // 处理后的数据类型
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+)
+
+// TestSignVerify 验证签名校验和时间戳窗口
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1735689600, 0)
+	body := []byte(`{"agent_id":"sg-1"}`)
+	sig := Sign("s3cret", now.Unix(), body)
+	ts := "1735689600"
+
+	if err := Verify("s3cret", ts, sig, body, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := Verify("wrong", ts, sig, body, now); err == nil {
+		t.Error("密钥错误应校验失败")
+	}
+	if err := Verify("s3cret", ts, sig, []byte(`{"agent_id":"us-1"}`), now); err == nil {
+		t.Error("请求体被篡改应校验失败")
+	}
+	if err := Verify("s3cret", ts, sig, body, now.Add(MaxClockSkew+time.Second)); err == nil {
+		t.Error("时间戳过期应校验失败")
+	}
+	if err := Verify("s3cret", "abc", sig, body, now); err == nil {
+		t.Error("时间戳无效应校验失败")
+	}
+}
+
+// TestPusherFlush 验证批量上报、签名和失败重试
+func TestPusherFlush(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		fail     = true
+		received []Result
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("s3cret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Now()); err != nil {
+			t.Errorf("签名校验失败: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var payload Payload
+		_ = json.Unmarshal(body, &payload)
+		if payload.AgentID != "sg-1" || payload.Region != "sg" {
+			t.Errorf("payload = %+v", payload)
+		}
+		received = append(received, payload.Results...)
+		_ = json.NewEncoder(w).Encode(IngestResponse{Accepted: len(payload.Results)})
+	}))
+	defer srv.Close()
+
+	p := NewPusher(config.AgentConfig{ServerURL: srv.URL, ID: "sg-1", Region: "sg", Secret: "s3cret"})
+	for i := 0; i < maxBatchSize+10; i++ {
+		p.Add(&monitor.ProbeResult{Provider: "Demo", Service: "cc", Status: 1, Timestamp: int64(i)}, 1)
+	}
+
+	if err := p.Flush(context.Background()); err == nil {
+		t.Fatal("中心服务不可用时 Flush 应返回错误")
+	}
+	if got := p.Pending(); got != maxBatchSize+10 {
+		t.Fatalf("失败后待上报 = %d, want %d", got, maxBatchSize+10)
+	}
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	if err := p.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if p.Pending() != 0 || len(received) != maxBatchSize+10 {
+		t.Fatalf("pending = %d, received = %d", p.Pending(), len(received))
+	}
+	if received[0].Timestamp != 0 || received[len(received)-1].Timestamp != int64(maxBatchSize+9) {
+		t.Errorf("上报顺序错误")
+	}
+}
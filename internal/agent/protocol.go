@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+// 上报接口路径与签名请求头
+const (
+	IngestPath = "/api/ingest"
+
+	HeaderAgentID   = "X-Agent-ID"
+	HeaderTimestamp = "X-Agent-Timestamp" // Unix 秒
+	HeaderSignature = "X-Agent-Signature" // hex(HMAC-SHA256(secret, timestamp + "." + body))
+)
+
+// MaxClockSkew 签名时间戳允许的最大偏差，超出视为过期请求（防重放）
+const MaxClockSkew = 5 * time.Minute
+
+// Result 单条探测结果
+type Result struct {
+	Provider  string            `json:"provider"`
+	Service   string            `json:"service"`
+	Channel   string            `json:"channel"`
+	Status    int               `json:"status"`
+	SubStatus storage.SubStatus `json:"sub_status"`
+	Latency   int               `json:"latency"`
+	Timestamp int64             `json:"timestamp"`
+	Attempts  int               `json:"attempts"` // 探测尝试次数（含失败确认重试）
+}
+
+// NewResult 由探测结果构造上报结果
+func NewResult(r *monitor.ProbeResult, attempts int) Result {
+	return Result{
+		Provider:  r.Provider,
+		Service:   r.Service,
+		Channel:   r.Channel,
+		Status:    r.Status,
+		SubStatus: r.SubStatus,
+		Latency:   r.Latency,
+		Timestamp: r.Timestamp,
+		Attempts:  attempts,
+	}
+}
+
+// Payload 上报请求体
+type Payload struct {
+	AgentID string   `json:"agent_id"`
+	Region  string   `json:"region"`
+	Results []Result `json:"results"`
+}
+
+// IngestResponse 上报接口响应
+type IngestResponse struct {
+	Accepted   int `json:"accepted"`
+	Duplicates int `json:"duplicates"` // 已入库的重复结果（重放或重试的批次），不会重复写入
+	Rejected   int `json:"rejected"`   // 中心服务未配置的监控项或无效结果（不会重试）
+}
+
+// Sign 计算请求签名
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验请求签名和时间戳
+func Verify(secret, timestampHeader, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("时间戳无效")
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return fmt.Errorf("时间戳超出允许范围（偏差 %v）", skew.Round(time.Second))
+	}
+
+	expected, _ := hex.DecodeString(Sign(secret, ts, body))
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, got) {
+		return fmt.Errorf("签名无效")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+)
+
+const (
+	// maxBatchSize 单次上报的最大结果数
+	maxBatchSize = 500
+
+	// maxPending 上报失败时最多缓存的结果数，超出后丢弃最旧的结果
+	maxPending = 20000
+)
+
+// Pusher 将探测结果批量签名上报到中心服务
+// 上报失败的结果保留在缓冲区，下次上报时重试
+type Pusher struct {
+	client    *http.Client
+	serverURL string
+	id        string
+	region    string
+	secret    string
+
+	mu      sync.Mutex
+	pending []Result
+	dropped int // 待记录日志的丢弃数
+	trimmed int // 累计从缓冲区头部丢弃的结果数（用于上报成功后定位已发送的结果）
+
+	now func() time.Time
+}
+
+// NewPusher 创建结果上报器
+func NewPusher(cfg config.AgentConfig) *Pusher {
+	return &Pusher{
+		client:    &http.Client{Timeout: 15 * time.Second},
+		serverURL: cfg.ServerURL,
+		id:        cfg.ID,
+		region:    cfg.Region,
+		secret:    cfg.Secret,
+		now:       time.Now,
+	}
+}
+
+// Add 缓存一条探测结果（可作为调度器的 resultSink）
+func (p *Pusher) Add(r *monitor.ProbeResult, attempts int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, NewResult(r, attempts))
+	if over := len(p.pending) - maxPending; over > 0 {
+		p.pending = p.pending[over:]
+		p.dropped += over
+		p.trimmed += over
+	}
+}
+
+// Pending 返回待上报的结果数
+func (p *Pusher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+// Run 按 interval 定期上报，直到 ctx 取消；退出前尽量上报剩余结果
+func (p *Pusher) Run(ctx context.Context, interval time.Duration) {
+	log.Printf("[Agent] 节点 %s（地域 %s）开始向 %s 上报结果，间隔 %v", p.id, p.region, p.serverURL, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := p.Flush(flushCtx); err != nil {
+				log.Printf("[Agent] 退出前上报失败，丢弃 %d 条结果: %v", p.Pending(), err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := p.Flush(ctx); err != nil {
+				log.Printf("[Agent] 上报失败（%d 条结果待重试）: %v", p.Pending(), err)
+			}
+		}
+	}
+}
+
+// Flush 分批上报所有缓存的结果，遇到错误时停止并保留未上报的结果
+func (p *Pusher) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	if p.dropped > 0 {
+		log.Printf("[Agent] 缓冲区已满，丢弃了 %d 条最旧的结果", p.dropped)
+		p.dropped = 0
+	}
+	p.mu.Unlock()
+
+	for {
+		p.mu.Lock()
+		n := min(len(p.pending), maxBatchSize)
+		batch := append([]Result(nil), p.pending[:n]...)
+		trimmedBefore := p.trimmed
+		p.mu.Unlock()
+
+		if n == 0 {
+			return nil
+		}
+		resp, err := p.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		// 上报期间 Add 只追加到末尾，但超限时会从头部丢弃，扣除期间已丢弃的部分
+		p.mu.Lock()
+		sent := max(n-(p.trimmed-trimmedBefore), 0)
+		p.pending = p.pending[min(sent, len(p.pending)):]
+		p.mu.Unlock()
+
+		if resp.Rejected > 0 {
+			log.Printf("[Agent] 中心服务拒绝了 %d 条结果（监控项未在中心服务配置？）", resp.Rejected)
+		}
+	}
+}
+
+// send 签名并发送一批结果
+func (p *Pusher) send(ctx context.Context, batch []Result) (*IngestResponse, error) {
+	body, err := json.Marshal(Payload{AgentID: p.id, Region: p.region, Results: batch})
+	if err != nil {
+		return nil, fmt.Errorf("序列化结果失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.serverURL+IngestPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	ts := p.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderAgentID, p.id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(p.secret, ts, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("中心服务返回 %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var result IngestResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &result, nil
+}
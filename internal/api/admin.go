@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+)
+
+// adminMonitorPayload 管理 API 的监控项请求体
+// ServiceConfig 的 api_key 不参与 JSON 序列化，这里单独接收
+type adminMonitorPayload struct {
+	config.ServiceConfig
+	APIKey string `json:"api_key"`
+}
+
+// EnableAdmin 启用管理 API
+// editor 负责修改配置文件，onApply 在配置写入成功后调用（更新调度器、通知器并立即巡检）
+func (s *Server) EnableAdmin(editor *config.Editor, onApply func(*config.AppConfig)) {
+	s.handler.editor = editor
+	s.handler.onConfigApplied = onApply
+}
+
+// adminAuth 校验管理 API 令牌（Authorization: Bearer <token>）
+// 未启用管理 API 或未配置 admin.token 时返回 404，避免暴露接口存在
+func (h *Handler) adminAuth(c *gin.Context) {
+	h.cfgMu.RLock()
+	token := h.config.Admin.Token
+	h.cfgMu.RUnlock()
+
+	if h.editor == nil || token == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
+		return
+	}
+
+	provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Next()
+}
+
+// AdminListMonitors 列出配置文件中的全部监控项（包含已停用的监控项，不返回 api_key）
+func (h *Handler) AdminListMonitors(c *gin.Context) {
+	monitors, err := h.editor.ListMonitors()
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+	if monitors == nil {
+		monitors = []config.ServiceConfig{}
+	}
+	c.JSON(http.StatusOK, gin.H{"monitors": monitors})
+}
+
+// AdminCreateMonitor 新增监控项
+func (h *Handler) AdminCreateMonitor(c *gin.Context) {
+	m, ok := bindAdminMonitor(c)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.editor.CreateMonitor(m)
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+
+	log.Printf("[Admin] 新增监控项: %s", config.KeyOf(m))
+	h.applyAdminConfig(cfg)
+	c.JSON(http.StatusCreated, gin.H{"monitor": m})
+}
+
+// AdminUpdateMonitor 整体替换监控项（未提供 api_key 时保留原值）
+// 查询参数 provider/service/channel 指定要修改的监控项
+func (h *Handler) AdminUpdateMonitor(c *gin.Context) {
+	m, ok := bindAdminMonitor(c)
+	if !ok {
+		return
+	}
+
+	key := adminMonitorKey(c)
+	cfg, err := h.editor.UpdateMonitor(key, m)
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+
+	log.Printf("[Admin] 更新监控项: %s", key)
+	h.applyAdminConfig(cfg)
+	c.JSON(http.StatusOK, gin.H{"monitor": m})
+}
+
+// AdminDisableMonitor 停用监控项（保留在配置文件中）
+func (h *Handler) AdminDisableMonitor(c *gin.Context) {
+	h.setMonitorDisabled(c, true)
+}
+
+// AdminEnableMonitor 重新启用监控项
+func (h *Handler) AdminEnableMonitor(c *gin.Context) {
+	h.setMonitorDisabled(c, false)
+}
+
+func (h *Handler) setMonitorDisabled(c *gin.Context, disabled bool) {
+	key := adminMonitorKey(c)
+	cfg, err := h.editor.SetMonitorDisabled(key, disabled)
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+
+	log.Printf("[Admin] 监控项 %s disabled=%v", key, disabled)
+	h.applyAdminConfig(cfg)
+	c.JSON(http.StatusOK, gin.H{"monitor": key, "disabled": disabled})
+}
+
+// AdminDeleteMonitor 删除监控项
+func (h *Handler) AdminDeleteMonitor(c *gin.Context) {
+	key := adminMonitorKey(c)
+	cfg, err := h.editor.DeleteMonitor(key)
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+
+	log.Printf("[Admin] 删除监控项: %s", key)
+	h.applyAdminConfig(cfg)
+	c.JSON(http.StatusOK, gin.H{"deleted": key})
+}
+
+// applyAdminConfig 让写入成功的配置立即生效（不等待文件监听）
+func (h *Handler) applyAdminConfig(cfg *config.AppConfig) {
+	if h.onConfigApplied != nil {
+		h.onConfigApplied(cfg)
+	} else {
+		h.UpdateConfig(cfg)
+	}
+}
+
+// adminError 将配置编辑错误映射为 HTTP 状态码
+func (h *Handler) adminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, config.ErrMonitorNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, config.ErrMonitorExists), errors.Is(err, config.ErrMonitorReadOnly):
+		status = http.StatusConflict
+	case errors.Is(err, config.ErrInvalidConfig):
+		status = http.StatusUnprocessableEntity
+	default:
+		log.Printf("[Admin] 操作失败: %v", err)
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// adminMonitorKey 从查询参数中读取监控项标识
+func adminMonitorKey(c *gin.Context) config.MonitorKey {
+	return config.MonitorKey{
+		Provider: c.Query("provider"),
+		Service:  c.Query("service"),
+		Channel:  c.Query("channel"),
+	}
+}
+
+// bindAdminMonitor 解析请求体（拒绝未知字段，避免拼写错误被静默忽略）
+func bindAdminMonitor(c *gin.Context) (config.ServiceConfig, bool) {
+	var payload adminMonitorPayload
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return config.ServiceConfig{}, false
+	}
+
+	m := payload.ServiceConfig
+	m.APIKey = payload.APIKey
+	return m, true
+}
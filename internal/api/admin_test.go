@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/storage"
+)
+
+const adminTestConfig = `admin:
+  token: "secret"
+monitors:
+  - provider: "Demo"
+    service: "cc"
+    category: "public"
+    sponsor: "Alice"
+    url: "https://demo.example.com"
+    method: "GET"
+`
+
+// TestAdminAPI 验证管理 API 的鉴权与配置生效流程
+func TestAdminAPI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(adminTestConfig), 0o600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+
+	loader := config.NewLoader()
+	cfg, err := loader.Load(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	server := NewServer(&badgeTestStorage{}, cfg)
+	var applied *config.AppConfig
+	server.EnableAdmin(config.NewEditor(loader, path), func(newCfg *config.AppConfig) {
+		applied = newCfg
+		server.UpdateConfig(newCfg)
+	})
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/admin/monitors", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("缺少令牌应返回 401，got=%d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/admin/monitors", "wrong", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("错误令牌应返回 401，got=%d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/export?since=2025-01-01", "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("导出接口缺少令牌应返回 401，got=%d", w.Code)
+	}
+
+	w := do(http.MethodGet, "/api/admin/monitors", "secret", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"provider":"Demo"`) {
+		t.Fatalf("列表失败: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "api_key") {
+		t.Errorf("列表不应返回 api_key: %s", w.Body.String())
+	}
+
+	body := `{"provider":"New","service":"cx","category":"commercial","sponsor":"Bob","url":"https://new.example.com","method":"GET","api_key":"sk-new"}`
+	if w := do(http.MethodPost, "/api/admin/monitors", "secret", body); w.Code != http.StatusCreated {
+		t.Fatalf("新增失败: %d %s", w.Code, w.Body.String())
+	}
+	if applied == nil || len(applied.Monitors) != 2 || applied.Monitors[1].APIKey != "sk-new" {
+		t.Fatalf("新增后应触发配置生效回调，got=%+v", applied)
+	}
+
+	if w := do(http.MethodPost, "/api/admin/monitors", "secret", `{"provider":"X","unknown":1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("未知字段应返回 400，got=%d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/admin/monitors", "secret", `{"provider":"X","service":"y"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("校验失败应返回 422，got=%d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/admin/monitors/disable?provider=New&service=cx", "secret", ""); w.Code != http.StatusOK {
+		t.Fatalf("停用失败: %d %s", w.Code, w.Body.String())
+	}
+	if len(applied.Monitors) != 1 {
+		t.Errorf("停用后运行时监控项数量 = %d, want 1", len(applied.Monitors))
+	}
+	if w := do(http.MethodDelete, "/api/admin/monitors?provider=Missing&service=cc", "secret", ""); w.Code != http.StatusNotFound {
+		t.Errorf("删除不存在的监控项应返回 404，got=%d", w.Code)
+	}
+}
+
+// TestAdminAPIDisabledWithoutToken 未配置令牌时管理 API 不可用
+func TestAdminAPIDisabledWithoutToken(t *testing.T) {
+	cfg := &config.AppConfig{}
+	server := NewServer(&badgeTestStorage{}, cfg)
+	server.EnableAdmin(config.NewEditor(config.NewLoader(), "unused.yaml"), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/monitors", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("未配置令牌应返回 404，got=%d", w.Code)
+	}
+}
+
+// fakeProber 记录即时探测调用
+type fakeProber struct {
+	task    config.ServiceConfig
+	persist bool
+}
+
+func (p *fakeProber) ProbeOnce(_ context.Context, task config.ServiceConfig, persist bool) (*monitor.ProbeResult, error) {
+	p.task, p.persist = task, persist
+	return &monitor.ProbeResult{
+		Provider: task.Provider, Service: task.Service, Channel: task.Channel,
+		Status: 0, SubStatus: storage.SubStatusServerError, Latency: 123, Timestamp: 1735689600,
+	}, nil
+}
+
+// TestAdminProbeMonitor 验证单个监控项即时探测
+func TestAdminProbeMonitor(t *testing.T) {
+	cfg := &config.AppConfig{
+		Admin: config.AdminConfig{Token: "secret"},
+		Monitors: []config.ServiceConfig{
+			{Provider: "Demo", Service: "cc", Channel: "vip", APIKey: "sk-demo"},
+		},
+	}
+	server := NewServer(&badgeTestStorage{}, cfg)
+	server.EnableAdmin(config.NewEditor(config.NewLoader(), "unused.yaml"), nil)
+	prober := &fakeProber{}
+	server.SetProber(prober)
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"存在的监控项", "/api/admin/probe/Demo/cc/vip?persist=true", http.StatusOK},
+		{"channel 不匹配", "/api/admin/probe/Demo/cc", http.StatusNotFound},
+		{"监控项不存在", "/api/admin/probe/Other/cc/vip", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("状态码 = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+		})
+	}
+
+	if !prober.persist || prober.task.APIKey != "sk-demo" {
+		t.Errorf("应使用运行时配置并传递 persist，got=%+v persist=%v", prober.task, prober.persist)
+	}
+}
+
+// revisionTestStorage 带配置修订历史的内存存储
+type revisionTestStorage struct {
+	badgeTestStorage
+	revisions []storage.ConfigRevision
+}
+
+func (s *revisionTestStorage) WithContext(ctx context.Context) storage.Storage { return s }
+
+func (s *revisionTestStorage) SaveRevision(rev *storage.ConfigRevision) error {
+	rev.ID = int64(len(s.revisions) + 1)
+	s.revisions = append(s.revisions, *rev)
+	return nil
+}
+
+func (s *revisionTestStorage) ListRevisions(limit int) ([]storage.ConfigRevision, error) {
+	var result []storage.ConfigRevision
+	for i := len(s.revisions) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, s.revisions[i])
+	}
+	return result, nil
+}
+
+func (s *revisionTestStorage) GetRevision(id int64) (*storage.ConfigRevision, error) {
+	if id < 1 || id > int64(len(s.revisions)) {
+		return nil, nil
+	}
+	rev := s.revisions[id-1]
+	return &rev, nil
+}
+
+// TestAdminRevisions 验证配置修订历史查询
+func TestAdminRevisions(t *testing.T) {
+	cfg := &config.AppConfig{Admin: config.AdminConfig{Token: "secret"}}
+	store := &revisionTestStorage{}
+	store.SaveRevision(&storage.ConfigRevision{Version: "v1", Source: storage.RevisionSourceStartup})
+	store.SaveRevision(&storage.ConfigRevision{Version: "v2", Source: storage.RevisionSourceReload,
+		Summary: "修改 1 个监控项", Diff: `{"modified":[]}`})
+
+	server := NewServer(store, cfg)
+	server.EnableAdmin(config.NewEditor(config.NewLoader(), "unused.yaml"), nil)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/admin/config/revisions?limit=1")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"version":"v2"`) || strings.Contains(w.Body.String(), `"v1"`) {
+		t.Fatalf("列表应按时间倒序并遵守 limit: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), `"diff"`) {
+		t.Errorf("列表不应返回 diff: %s", w.Body.String())
+	}
+
+	w = get("/api/admin/config/revisions/2")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"diff":{"modified":[]}`) {
+		t.Fatalf("详情应返回 diff: %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/api/admin/config/revisions/9"); w.Code != http.StatusNotFound {
+		t.Errorf("不存在的修订应返回 404，got=%d", w.Code)
+	}
+	if w := get("/api/admin/config/revisions?limit=abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("无效的 limit 应返回 400，got=%d", w.Code)
+	}
+
+	// 存储不支持修订历史
+	plain := NewServer(&badgeTestStorage{}, cfg)
+	plain.EnableAdmin(config.NewEditor(config.NewLoader(), "unused.yaml"), nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/config/revisions", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	plain.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("存储不支持时应返回 501，got=%d", rec.Code)
+	}
+}
@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// 徽章颜色（与前端 utils/color.ts 保持一致）
+var (
+	badgeGray       = rgb{107, 114, 128} // #6b7280（不可用）
+	badgeLightGreen = rgb{74, 222, 128}  // #4ade80（降级）
+	badgeDeepGreen  = rgb{34, 197, 94}   // #22c55e（可用）
+	badgeNoDataGray = rgb{148, 163, 184} // #94a3b8（无数据）
+	badgeLabelColor = rgb{85, 85, 85}    // #555（左侧标签背景，shields 默认）
+)
+
+// 徽章类型
+const (
+	badgeTypeStatus  = "status"  // 当前状态
+	badgeTypeUptime  = "uptime"  // 时间范围内可用率
+	badgeTypeLatency = "latency" // 时间范围内平均延迟
+)
+
+type rgb struct {
+	r, g, b int
+}
+
+func (c rgb) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+// lerpColor 线性插值两个颜色
+func lerpColor(c1, c2 rgb, t float64) rgb {
+	return rgb{
+		r: int(float64(c1.r) + float64(c2.r-c1.r)*t + 0.5),
+		g: int(float64(c1.g) + float64(c2.g-c1.g)*t + 0.5),
+		b: int(float64(c1.b) + float64(c2.b-c1.b)*t + 0.5),
+	}
+}
+
+// availabilityColor 根据可用率返回颜色（阈值与前端 availabilityToColor 一致）
+func availabilityColor(availability float64) rgb {
+	switch {
+	case availability < 0:
+		return badgeNoDataGray
+	case availability < 60:
+		return badgeGray
+	case availability < 80:
+		return lerpColor(badgeGray, badgeLightGreen, (availability-60)/20)
+	default:
+		return lerpColor(badgeLightGreen, badgeDeepGreen, (availability-80)/20)
+	}
+}
+
+// latencyColor 根据延迟返回颜色（阈值与前端 latencyToColor 一致）
+func latencyColor(latency, slowLatencyMs int) rgb {
+	if latency <= 0 || slowLatencyMs <= 0 {
+		return badgeNoDataGray
+	}
+
+	ratio := float64(latency) / float64(slowLatencyMs)
+	switch {
+	case ratio < 0.3:
+		return badgeDeepGreen
+	case ratio < 1:
+		return lerpColor(badgeDeepGreen, badgeLightGreen, (ratio-0.3)/0.7)
+	case ratio < 2:
+		return lerpColor(badgeLightGreen, badgeGray, ratio-1)
+	default:
+		return badgeGray
+	}
+}
+
+// statusBadge 根据状态码返回徽章文案和颜色
+func statusBadge(status int) (string, rgb) {
+	switch status {
+	case 1:
+		return "up", badgeDeepGreen
+	case 2:
+		return "degraded", badgeLightGreen
+	case 0:
+		return "down", badgeGray
+	default:
+		return "no data", badgeNoDataGray
+	}
+}
+
+// badgeTextWidth 估算文本在 11px Verdana 下的像素宽度
+// 非 ASCII 字符（中日文等）按全角宽度估算
+func badgeTextWidth(text string) int {
+	width := 0.0
+	for _, r := range text {
+		switch {
+		case r >= utf8.RuneSelf:
+			width += 11
+		case strings.ContainsRune("iljt.,:;!|'", r):
+			width += 3.5
+		case r >= 'A' && r <= 'Z', r == 'm', r == 'w', r == '%':
+			width += 8.5
+		default:
+			width += 6.5
+		}
+	}
+	return int(width + 0.5)
+}
+
+// renderBadgeSVG 渲染 shields 风格（flat）的 SVG 徽章
+func renderBadgeSVG(label, message string, color rgb) []byte {
+	const padding = 10
+
+	labelWidth := badgeTextWidth(label) + padding
+	messageWidth := badgeTextWidth(message) + padding
+	totalWidth := labelWidth + messageWidth
+
+	escapedLabel := html.EscapeString(label)
+	escapedMessage := html.EscapeString(message)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`,
+		totalWidth, escapedLabel, escapedMessage)
+	fmt.Fprintf(&sb, `<title>%s: %s</title>`, escapedLabel, escapedMessage)
+	sb.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, totalWidth)
+	sb.WriteString(`<g clip-path="url(#r)">`)
+	fmt.Fprintf(&sb, `<rect width="%d" height="20" fill="%s"/>`, labelWidth, badgeLabelColor.hex())
+	fmt.Fprintf(&sb, `<rect x="%d" width="%d" height="20" fill="%s"/>`, labelWidth, messageWidth, color.hex())
+	fmt.Fprintf(&sb, `<rect width="%d" height="20" fill="url(#s)"/>`, totalWidth)
+	sb.WriteString(`</g>`)
+	sb.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="11">`)
+	fmt.Fprintf(&sb, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`, labelWidth/2, escapedLabel)
+	fmt.Fprintf(&sb, `<text x="%d" y="14">%s</text>`, labelWidth/2, escapedLabel)
+	fmt.Fprintf(&sb, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`, labelWidth+messageWidth/2, escapedMessage)
+	fmt.Fprintf(&sb, `<text x="%d" y="14">%s</text>`, labelWidth+messageWidth/2, escapedMessage)
+	sb.WriteString(`</g></svg>`)
+
+	return []byte(sb.String())
+}
+
+// findBadgeMonitor 根据 slug/service/channel 查找监控项
+// channel 为空时优先匹配未配置 channel 的监控项，否则返回第一个匹配项
+func findBadgeMonitor(monitors []config.ServiceConfig, slug, service, channel string, channelGiven bool) (config.ServiceConfig, bool) {
+	var fallback *config.ServiceConfig
+	for i := range monitors {
+		m := &monitors[i]
+		monitorSlug := m.ProviderSlug
+		if monitorSlug == "" {
+			monitorSlug = strings.ToLower(strings.TrimSpace(m.Provider))
+		}
+		if monitorSlug != slug || m.Service != service {
+			continue
+		}
+
+		if channelGiven {
+			if m.Channel == channel {
+				return *m, true
+			}
+			continue
+		}
+
+		if m.Channel == "" {
+			return *m, true
+		}
+		if fallback == nil {
+			fallback = m
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+	return config.ServiceConfig{}, false
+}
+
+// GetBadge 生成 SVG 状态徽章（用于 README 嵌入）
+// 路由：/api/badge/:provider_slug/:service.svg 或 /api/badge/:provider_slug/:service/:channel.svg
+// 参数：type=status|uptime|latency（默认 status），period=24h|7d|30d（默认 24h），label 自定义左侧文案
+func (h *Handler) GetBadge(c *gin.Context) {
+	slug := strings.ToLower(strings.TrimSpace(c.Param("provider_slug")))
+	service := c.Param("service")
+	channel := c.Param("channel")
+	channelGiven := channel != ""
+
+	// 最后一段必须带 .svg 后缀
+	if channelGiven {
+		if !strings.HasSuffix(channel, ".svg") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
+			return
+		}
+		channel = strings.TrimSuffix(channel, ".svg")
+	} else {
+		if !strings.HasSuffix(service, ".svg") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
+			return
+		}
+		service = strings.TrimSuffix(service, ".svg")
+	}
+
+	badgeType := c.DefaultQuery("type", badgeTypeStatus)
+	period := c.DefaultQuery("period", "24h")
+	customLabel := strings.TrimSpace(c.Query("label"))
+
+	if badgeType != badgeTypeStatus && badgeType != badgeTypeUptime && badgeType != badgeTypeLatency {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("无效的徽章类型: %s（支持 status、uptime、latency）", badgeType),
+		})
+		return
+	}
+	if _, err := h.parsePeriod(period); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("无效的时间范围: %s", period),
+		})
+		return
+	}
+	if utf8.RuneCountInString(customLabel) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label 长度不能超过 64 个字符"})
+		return
+	}
+
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	degradedWeight := h.config.DegradedWeight
+	slowLatencyMs := int(h.config.SlowLatencyDuration / time.Millisecond)
+	h.cfgMu.RUnlock()
+
+	task, found := findBadgeMonitor(monitors, slug, service, channel, channelGiven)
+	if !found {
+		label := customLabel
+		if label == "" {
+			label = "relay pulse"
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusNotFound, "image/svg+xml; charset=utf-8", renderBadgeSVG(label, "not found", badgeNoDataGray))
+		return
+	}
+
+	// 与 /api/status 共用缓存（singleflight 防止缓存击穿）
+	// 缓存的是与左侧文案无关的取值，自定义 label 在读取缓存后渲染，任意 label 不会占满共享缓存
+	cacheKey := fmt.Sprintf("badge|t=%s|p=%s|prov=%s|svc=%s|ch=%s",
+		badgeType, period, task.Provider, task.Service, task.Channel)
+	cached, err := h.cache.load(cacheKey, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		value, err := h.buildBadge(ctx, task, badgeType, period, degradedWeight, slowLatencyMs)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	var value badgeValue
+	if err == nil {
+		err = json.Unmarshal(cached, &value)
+	}
+	if err != nil {
+		log.Printf("[API] GetBadge 失败 key=%s error=%v", cacheKey, err)
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusInternalServerError, "image/svg+xml; charset=utf-8", renderBadgeSVG("relay pulse", "error", badgeNoDataGray))
+		return
+	}
+
+	label := customLabel
+	if label == "" {
+		label = defaultBadgeLabel(task, badgeType, period)
+	}
+	data := renderBadgeSVG(label, value.Message, rgb{value.Color[0], value.Color[1], value.Color[2]})
+
+	sum := sha1.Sum(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=60, s-maxage=60")
+	if match := c.GetHeader("If-None-Match"); match == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", data)
+}
+
+// badgeValue 徽章右侧的文案和颜色（缓存内容，与左侧文案无关）
+type badgeValue struct {
+	Message string `json:"m"`
+	Color   [3]int `json:"c"`
+}
+
+func newBadgeValue(message string, color rgb) badgeValue {
+	return badgeValue{Message: message, Color: [3]int{color.r, color.g, color.b}}
+}
+
+// defaultBadgeLabel 未指定 label 时的左侧文案：provider service [channel] [period]
+func defaultBadgeLabel(task config.ServiceConfig, badgeType, period string) string {
+	label := task.Provider + " " + task.Service
+	if task.Channel != "" {
+		label += " " + task.Channel
+	}
+	if badgeType != badgeTypeStatus {
+		label += " " + period
+	}
+	return label
+}
+
+// buildBadge 查询数据库并计算徽章取值（缓存 miss 时调用）
+func (h *Handler) buildBadge(ctx context.Context, task config.ServiceConfig, badgeType, period string, degradedWeight float64, slowLatencyMs int) (badgeValue, error) {
+	store := h.storage.WithContext(ctx)
+
+	if badgeType == badgeTypeStatus {
+		latest, err := store.GetLatest(task.Provider, task.Service, task.Channel)
+		if err != nil {
+			return badgeValue{}, fmt.Errorf("查询失败 %s/%s/%s: %w", task.Provider, task.Service, task.Channel, err)
+		}
+		if latest != nil && latest.Region != "" {
+			// 最新记录来自远程探测节点，改用最近 24 小时内本机探测的最新记录
+			history, err := store.GetHistory(task.Provider, task.Service, task.Channel, time.Now().Add(-24*time.Hour))
+			if err != nil {
+				return badgeValue{}, fmt.Errorf("查询历史失败 %s/%s/%s: %w", task.Provider, task.Service, task.Channel, err)
+			}
+			latest = nil
+			if local := localRecords(history); len(local) > 0 {
+				latest = local[len(local)-1]
+			}
+		}
+		status := -1
+		if latest != nil {
+			status = latest.Status
+		}
+		return newBadgeValue(statusBadge(status)), nil
+	}
+
+	since, _ := h.parsePeriod(period) // 已在调用前验证
+	history, err := store.GetHistory(task.Provider, task.Service, task.Channel, since)
+	if err != nil {
+		return badgeValue{}, fmt.Errorf("查询历史失败 %s/%s/%s: %w", task.Provider, task.Service, task.Channel, err)
+	}
+	history = localRecords(history)
+
+	if badgeType == badgeTypeUptime {
+		availability := computeAvailability(history, degradedWeight)
+		if availability < 0 {
+			return newBadgeValue("no data", badgeNoDataGray), nil
+		}
+		return newBadgeValue(fmt.Sprintf("%.2f%%", availability), availabilityColor(availability)), nil
+	}
+
+	latency := computeAverageLatency(history)
+	if latency <= 0 {
+		return newBadgeValue("no data", badgeNoDataGray), nil
+	}
+	return newBadgeValue(fmt.Sprintf("%dms", latency), latencyColor(latency, slowLatencyMs)), nil
+}
+
+// computeAvailability 计算记录集合的加权可用率（0-100），无记录时返回 -1
+func computeAvailability(records []*storage.ProbeRecord, degradedWeight float64) float64 {
+	if len(records) == 0 {
+		return -1
+	}
+
+	var weighted float64
+	for _, record := range records {
+		weighted += availabilityWeight(record.Status, degradedWeight)
+	}
+	return weighted / float64(len(records)) * 100
+}
+
+// computeAverageLatency 计算可用状态（status > 0）记录的平均延迟（四舍五入）
+func computeAverageLatency(records []*storage.ProbeRecord) int {
+	var sum int64
+	var count int
+	for _, record := range records {
+		if record.Status > 0 {
+			sum += int64(record.Latency)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return int(float64(sum)/float64(count) + 0.5)
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// badgeTestStorage 仅用于徽章测试的内存存储
+type badgeTestStorage struct {
+	storage.Storage
+	latest  *storage.ProbeRecord
+	history []*storage.ProbeRecord
+}
+
+func (s *badgeTestStorage) WithContext(ctx context.Context) storage.Storage { return s }
+
+func (s *badgeTestStorage) GetLatest(provider, service, channel string) (*storage.ProbeRecord, error) {
+	return s.latest, nil
+}
+
+func (s *badgeTestStorage) GetHistory(provider, service, channel string, since time.Time) ([]*storage.ProbeRecord, error) {
+	return s.history, nil
+}
+
+// TestAvailabilityColor 验证可用率颜色阈值与前端保持一致
+func TestAvailabilityColor(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability float64
+		want         string
+	}{
+		{"无数据", -1, "#94a3b8"},
+		{"低于 60%", 59.9, "#6b7280"},
+		{"刚好 60%", 60, "#6b7280"},
+		{"刚好 80%", 80, "#4ade80"},
+		{"100%", 100, "#22c55e"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := availabilityColor(tt.availability).hex(); got != tt.want {
+				t.Errorf("availabilityColor(%v) = %s, want %s", tt.availability, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLatencyColor 验证延迟颜色阈值与前端保持一致
+func TestLatencyColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		latency int
+		want    string
+	}{
+		{"无数据", 0, "#94a3b8"},
+		{"低于 30% 阈值", 1000, "#22c55e"},
+		{"刚好阈值", 5000, "#4ade80"},
+		{"超过 2 倍阈值", 12000, "#6b7280"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latencyColor(tt.latency, 5000).hex(); got != tt.want {
+				t.Errorf("latencyColor(%d) = %s, want %s", tt.latency, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderBadgeSVGEscapes 验证徽章文本经过转义
+func TestRenderBadgeSVGEscapes(t *testing.T) {
+	svg := string(renderBadgeSVG(`<script>`, "up", badgeDeepGreen))
+	if strings.Contains(svg, "<script>") {
+		t.Fatalf("徽章 label 未转义: %s", svg)
+	}
+	if !strings.Contains(svg, "&lt;script&gt;") {
+		t.Fatalf("徽章 label 转义结果不符合预期: %s", svg)
+	}
+}
+
+// TestFindBadgeMonitor 验证 channel 可选时的匹配逻辑
+func TestFindBadgeMonitor(t *testing.T) {
+	monitors := []config.ServiceConfig{
+		{Provider: "Demo", ProviderSlug: "demo", Service: "cc", Channel: "vip"},
+		{Provider: "Demo", ProviderSlug: "demo", Service: "cc", Channel: ""},
+		{Provider: "Other", Service: "cx", Channel: "standard"},
+	}
+
+	if m, ok := findBadgeMonitor(monitors, "demo", "cc", "vip", true); !ok || m.Channel != "vip" {
+		t.Errorf("指定 channel 时应匹配 vip，got=%+v ok=%v", m, ok)
+	}
+	if m, ok := findBadgeMonitor(monitors, "demo", "cc", "", false); !ok || m.Channel != "" {
+		t.Errorf("未指定 channel 时应优先匹配空 channel，got=%+v ok=%v", m, ok)
+	}
+	if m, ok := findBadgeMonitor(monitors, "other", "cx", "", false); !ok || m.Channel != "standard" {
+		t.Errorf("未指定 channel 且无空 channel 时应回退到第一个匹配项，got=%+v ok=%v", m, ok)
+	}
+	if _, ok := findBadgeMonitor(monitors, "demo", "cc", "missing", true); ok {
+		t.Errorf("不存在的 channel 不应匹配")
+	}
+}
+
+// TestGetBadgeWithoutGzip 验证徽章不受强制 gzip 限制，且返回 SVG 与缓存头
+func TestGetBadgeWithoutGzip(t *testing.T) {
+	now := time.Now().Unix()
+	store := &badgeTestStorage{
+		// 最新记录来自远程探测节点：徽章只统计本机探测的记录
+		latest: &storage.ProbeRecord{Region: "sg", Status: 0, Timestamp: now},
+		history: []*storage.ProbeRecord{
+			{Status: 0, Latency: 9000, Timestamp: now},
+			{Status: 1, Latency: 100, Timestamp: now},
+			{Region: "sg", Status: 1, Latency: 50000, Timestamp: now},
+			{Region: "sg", Status: 0, Timestamp: now},
+		},
+	}
+	cfg := &config.AppConfig{
+		DegradedWeight:      0.7,
+		SlowLatencyDuration: 5 * time.Second,
+		PublicBaseURL:       "https://relaypulse.top",
+		Monitors: []config.ServiceConfig{
+			{Provider: "Demo", ProviderSlug: "demo", Service: "cc", Channel: "vip"},
+		},
+	}
+	server := NewServer(store, cfg)
+
+	tests := []struct {
+		name     string
+		path     string
+		code     int
+		contains string
+	}{
+		{"当前状态", "/api/badge/demo/cc/vip.svg", http.StatusOK, ">up<"},
+		{"可用率", "/api/badge/demo/cc/vip.svg?type=uptime&period=7d", http.StatusOK, "50.00%"},
+		{"平均延迟", "/api/badge/demo/cc/vip.svg?type=latency", http.StatusOK, "100ms"},
+		{"监控项不存在", "/api/badge/demo/cx.svg", http.StatusNotFound, "not found"},
+		{"自定义 label", "/api/badge/demo/cc/vip.svg?label=my%20relay", http.StatusOK, ">my relay<"},
+		{"另一个自定义 label", "/api/badge/demo/cc/vip.svg?label=other", http.StatusOK, ">other<"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			server.router.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("状态码 = %d, want %d, body=%s", w.Code, tt.code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "image/svg+xml") {
+				t.Errorf("Content-Type = %s, want image/svg+xml", ct)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("响应中缺少 %q: %s", tt.contains, w.Body.String())
+			}
+			if tt.code == http.StatusOK && w.Header().Get("Cache-Control") == "" {
+				t.Errorf("缺少 Cache-Control 头")
+			}
+		})
+	}
+
+	// 自定义 label 不进入缓存 key：3 种徽章只占 3 个缓存条目
+	if n := len(server.handler.cache.entries); n != 3 {
+		t.Errorf("缓存条目数 = %d, want 3", n)
+	}
+}
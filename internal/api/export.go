@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/export"
+	"monitor/internal/storage"
+)
+
+const (
+	exportDefaultRange = 24 * time.Hour      // 未指定 since 时默认导出最近 24 小时
+	exportMaxRange     = 31 * 24 * time.Hour // 单次导出的最大时间跨度（按月结算足够）
+	exportTimeout      = 5 * time.Minute     // 单次导出的最长耗时
+)
+
+// GetExport 流式导出原始探测记录
+// 路由：/api/export?format=csv|ndjson|json&since=&until=&provider=&service=&channel=&region=
+// since/until 支持 RFC3339、2006-01-02 和 Unix 秒
+func (h *Handler) GetExport(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseExportFilter(c, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	h.cfgMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), exportTimeout)
+	defer cancel()
+
+	filename := fmt.Sprintf("probe_history_%s_%s.%s",
+		filter.Since.UTC().Format("20060102T150405Z"),
+		filter.Until.UTC().Format("20060102T150405Z"),
+		format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	count, err := export.Export(h.storage.WithContext(ctx), monitors, filter, format, c.Writer)
+	if err != nil {
+		// 响应头已发送，只能记录日志并中断连接
+		log.Printf("[API] GetExport 失败 (已导出 %d 行) filter=%+v error=%v", count, filter, err)
+		c.Abort()
+		return
+	}
+}
+
+// parseExportFilter 解析并校验导出的时间范围与监控项过滤条件
+func parseExportFilter(c *gin.Context, now time.Time) (storage.ExportFilter, error) {
+	filter := storage.ExportFilter{
+		Provider: c.Query("provider"),
+		Service:  c.Query("service"),
+		Channel:  c.Query("channel"),
+		Region:   c.Query("region"),
+	}
+
+	until, err := export.ParseTime(c.Query("until"))
+	if err != nil {
+		return filter, fmt.Errorf("until 参数无效: %w", err)
+	}
+	if until.IsZero() {
+		until = now
+	}
+
+	since, err := export.ParseTime(c.Query("since"))
+	if err != nil {
+		return filter, fmt.Errorf("since 参数无效: %w", err)
+	}
+	if since.IsZero() {
+		since = until.Add(-exportDefaultRange)
+	}
+
+	if !since.Before(until) {
+		return filter, fmt.Errorf("since 必须早于 until")
+	}
+	if until.Sub(since) > exportMaxRange {
+		return filter, fmt.Errorf("时间范围不能超过 %d 天，请分批导出", int(exportMaxRange.Hours()/24))
+	}
+
+	filter.Since = since
+	filter.Until = until
+	return filter, nil
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+const (
+	feedWindow     = 7 * 24 * time.Hour // Feed 回溯的时间范围
+	feedMaxEntries = 50                 // Feed 最多返回的条目数
+	feedCacheTTL   = 5 * time.Minute    // Feed 缓存时间（RSS 阅读器轮询频率较低）
+)
+
+// 状态变更事件类型（与告警类型保持一致）
+const (
+	feedEventDown = "down" // 可用 → 不可用
+	feedEventUp   = "up"   // 不可用 → 恢复
+)
+
+// statusEvent 状态变更事件（由相邻探测记录推导）
+type statusEvent struct {
+	Monitor   config.ServiceConfig
+	Type      string
+	Record    *storage.ProbeRecord
+	Timestamp int64
+}
+
+// Atom 1.0 文档结构
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Updated  string       `xml:"updated"`
+	Link     atomLink     `xml:"link"`
+	Category atomCategory `xml:"category"`
+	Summary  string       `xml:"summary"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// feedURL 构造 Feed 地址（slug 为空时为全站 Feed）
+func feedURL(baseURL string, lang Language, slug string) string {
+	prefix := ""
+	if lang.PathPrefix != "" {
+		prefix = "/" + lang.PathPrefix
+	}
+	if slug != "" {
+		return fmt.Sprintf("%s%s/p/%s/feed.xml", baseURL, prefix, slug)
+	}
+	return fmt.Sprintf("%s%s/feed.xml", baseURL, prefix)
+}
+
+// pageURL 构造页面地址（slug 为空时为首页）
+func pageURL(baseURL string, lang Language, slug string) string {
+	prefix := ""
+	if lang.PathPrefix != "" {
+		prefix = "/" + lang.PathPrefix
+	}
+	if slug != "" {
+		return fmt.Sprintf("%s%s/p/%s", baseURL, prefix, slug)
+	}
+	return fmt.Sprintf("%s%s/", baseURL, prefix)
+}
+
+// getFeedTitle 根据语言返回 Feed 标题
+func getFeedTitle(langCode, providerName string) string {
+	if providerName != "" {
+		switch langCode {
+		case "en-US":
+			return fmt.Sprintf("%s status changes - code-cli", providerName)
+		case "ru-RU":
+			return fmt.Sprintf("Изменения статуса %s - code-cli", providerName)
+		case "ja-JP":
+			return fmt.Sprintf("%s ステータス変更 - code-cli", providerName)
+		default:
+			return fmt.Sprintf("%s 状态变更 - code-cli", providerName)
+		}
+	}
+
+	switch langCode {
+	case "en-US":
+		return "code-cli - Relay status changes"
+	case "ru-RU":
+		return "code-cli - Изменения статуса ретрансляторов"
+	case "ja-JP":
+		return "code-cli - リレーのステータス変更"
+	default:
+		return "code-cli - 中转服务状态变更"
+	}
+}
+
+// getFeedEntryTitle 根据语言返回单条事件标题
+func getFeedEntryTitle(langCode, eventType, name string) string {
+	if eventType == feedEventDown {
+		switch langCode {
+		case "en-US":
+			return fmt.Sprintf("🔴 %s is down", name)
+		case "ru-RU":
+			return fmt.Sprintf("🔴 %s недоступен", name)
+		case "ja-JP":
+			return fmt.Sprintf("🔴 %s が利用不可", name)
+		default:
+			return fmt.Sprintf("🔴 %s 不可用", name)
+		}
+	}
+
+	switch langCode {
+	case "en-US":
+		return fmt.Sprintf("🟢 %s recovered", name)
+	case "ru-RU":
+		return fmt.Sprintf("🟢 %s восстановлен", name)
+	case "ja-JP":
+		return fmt.Sprintf("🟢 %s が復旧", name)
+	default:
+		return fmt.Sprintf("🟢 %s 已恢复", name)
+	}
+}
+
+// getFeedEntrySummary 根据语言返回单条事件摘要
+func getFeedEntrySummary(langCode string, event statusEvent) string {
+	ts := time.Unix(event.Timestamp, 0).UTC().Format(time.RFC3339)
+	reason := string(event.Record.SubStatus)
+
+	if event.Type == feedEventDown {
+		if reason == "" {
+			reason = "unknown"
+		}
+		switch langCode {
+		case "en-US":
+			return fmt.Sprintf("Became unavailable at %s (reason: %s)", ts, reason)
+		case "ru-RU":
+			return fmt.Sprintf("Стал недоступен в %s (причина: %s)", ts, reason)
+		case "ja-JP":
+			return fmt.Sprintf("%s に利用不可になりました（原因: %s）", ts, reason)
+		default:
+			return fmt.Sprintf("%s 变为不可用（原因: %s）", ts, reason)
+		}
+	}
+
+	switch langCode {
+	case "en-US":
+		return fmt.Sprintf("Recovered at %s (latency: %d ms)", ts, event.Record.Latency)
+	case "ru-RU":
+		return fmt.Sprintf("Восстановлен в %s (задержка: %d мс)", ts, event.Record.Latency)
+	case "ja-JP":
+		return fmt.Sprintf("%s に復旧しました（レイテンシ: %d ms）", ts, event.Record.Latency)
+	default:
+		return fmt.Sprintf("%s 恢复正常（延迟: %d ms）", ts, event.Record.Latency)
+	}
+}
+
+// detectStatusEvents 从按时间升序排列的探测记录中提取 down/up 状态变更
+// 与告警逻辑一致：绿/黄 ↔ 红 视为状态变更，绿 ↔ 黄 不视为变更
+func detectStatusEvents(task config.ServiceConfig, records []*storage.ProbeRecord) []statusEvent {
+	var events []statusEvent
+	var prev *storage.ProbeRecord
+
+	for _, record := range records {
+		if prev != nil {
+			wasDown := prev.Status == 0
+			isDown := record.Status == 0
+			if !wasDown && isDown {
+				events = append(events, statusEvent{Monitor: task, Type: feedEventDown, Record: record, Timestamp: record.Timestamp})
+			} else if wasDown && !isDown {
+				events = append(events, statusEvent{Monitor: task, Type: feedEventUp, Record: record, Timestamp: record.Timestamp})
+			}
+		}
+		prev = record
+	}
+
+	return events
+}
+
+// GetFeed 生成状态变更 Atom Feed
+// 路由：/feed.xml、/p/:slug/feed.xml（以及带语言前缀的版本，如 /en/feed.xml）
+func (h *Handler) GetFeed(c *gin.Context) {
+	langCode, slug, isProviderFeed := parseRequestPath(c.Request.URL.Path)
+
+	if isProviderFeed && !isValidProviderSlug(slug) {
+		c.String(http.StatusNotFound, "feed not found")
+		return
+	}
+
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	baseURL := h.config.PublicBaseURL
+	h.cfgMu.RUnlock()
+
+	providerName := ""
+	if isProviderFeed {
+		for _, m := range monitors {
+			if m.ProviderSlug == slug {
+				providerName = m.Provider
+				break
+			}
+		}
+		if providerName == "" {
+			c.String(http.StatusNotFound, "feed not found")
+			return
+		}
+	}
+
+	cacheKey := fmt.Sprintf("feed|lang=%s|slug=%s", langCode, slug)
+	data, err := h.feedCache.load(cacheKey, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return h.buildFeed(ctx, monitors, baseURL, langCode, slug, providerName)
+	})
+	if err != nil {
+		log.Printf("[API] GetFeed 失败 key=%s error=%v", cacheKey, err)
+		c.String(http.StatusInternalServerError, "failed to build feed")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300, s-maxage=300")
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", data)
+}
+
+// buildFeed 查询状态变更并序列化为 Atom XML（缓存 miss 时调用）
+func (h *Handler) buildFeed(ctx context.Context, monitors []config.ServiceConfig, baseURL, langCode, slug, providerName string) ([]byte, error) {
+	store := h.storage.WithContext(ctx)
+	since := time.Now().Add(-feedWindow)
+	lang := getLanguageByCode(langCode)
+
+	var events []statusEvent
+	seen := make(map[string]bool)
+	for _, task := range monitors {
+		if slug != "" && task.ProviderSlug != slug {
+			continue
+		}
+
+		key := task.Provider + "/" + task.Service + "/" + task.Channel
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		history, err := store.GetHistory(task.Provider, task.Service, task.Channel, since)
+		if err != nil {
+			return nil, fmt.Errorf("查询历史失败 %s/%s/%s: %w", task.Provider, task.Service, task.Channel, err)
+		}
+		events = append(events, detectStatusEvents(task, localRecords(history))...)
+	}
+
+	// 最新事件在前
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp > events[j].Timestamp
+	})
+	if len(events) > feedMaxEntries {
+		events = events[:feedMaxEntries]
+	}
+
+	selfURL := feedURL(baseURL, lang, slug)
+	updated := time.Now().UTC()
+	if len(events) > 0 {
+		updated = time.Unix(events[0].Timestamp, 0).UTC()
+	}
+
+	feed := atomFeed{
+		Lang:    lang.HreflangTag,
+		ID:      selfURL,
+		Title:   getFeedTitle(langCode, providerName),
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+			{Rel: "alternate", Type: "text/html", Href: pageURL(baseURL, lang, slug)},
+		},
+		Author:  atomAuthor{Name: "code-cli"},
+		Entries: make([]atomEntry, 0, len(events)),
+	}
+
+	for _, event := range events {
+		m := event.Monitor
+		nameParts := []string{m.Provider, m.Service}
+		if m.Channel != "" {
+			nameParts = append(nameParts, m.Channel)
+		}
+		name := strings.Join(nameParts, " / ")
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:       fmt.Sprintf("%s#%s-%s-%s-%d", pageURL(baseURL, supportedLanguages[0], m.ProviderSlug), m.Service, m.Channel, event.Type, event.Timestamp),
+			Title:    getFeedEntryTitle(langCode, event.Type, name),
+			Updated:  time.Unix(event.Timestamp, 0).UTC().Format(time.RFC3339),
+			Link:     atomLink{Rel: "alternate", Type: "text/html", Href: pageURL(baseURL, lang, m.ProviderSlug)},
+			Category: atomCategory{Term: event.Type},
+			Summary:  getFeedEntrySummary(langCode, event),
+		})
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化 Atom Feed 失败: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// TestDetectStatusEvents 验证 down/up 事件的推导逻辑
+func TestDetectStatusEvents(t *testing.T) {
+	task := config.ServiceConfig{Provider: "Demo", Service: "cc"}
+	records := []*storage.ProbeRecord{
+		{Status: 1, Timestamp: 100},
+		{Status: 2, Timestamp: 200}, // 绿 → 黄：不算变更
+		{Status: 0, Timestamp: 300, SubStatus: storage.SubStatusServerError},
+		{Status: 0, Timestamp: 400}, // 红 → 红：不算变更
+		{Status: 1, Timestamp: 500},
+	}
+
+	events := detectStatusEvents(task, records)
+	if len(events) != 2 {
+		t.Fatalf("期望 2 个事件，实际 %d", len(events))
+	}
+	if events[0].Type != feedEventDown || events[0].Timestamp != 300 {
+		t.Errorf("第一个事件应为 down@300，got=%s@%d", events[0].Type, events[0].Timestamp)
+	}
+	if events[1].Type != feedEventUp || events[1].Timestamp != 500 {
+		t.Errorf("第二个事件应为 up@500，got=%s@%d", events[1].Type, events[1].Timestamp)
+	}
+}
+
+// TestGetFeed 验证全站和服务商 Feed 的语言与内容
+func TestGetFeed(t *testing.T) {
+	now := time.Now().Unix()
+	store := &badgeTestStorage{
+		history: []*storage.ProbeRecord{
+			{Status: 1, Timestamp: now - 120},
+			{Status: 0, Timestamp: now - 60, SubStatus: storage.SubStatusNetworkError},
+			{Region: "sg", Status: 1, Timestamp: now - 30}, // 远程地域的结果不产生事件
+		},
+	}
+	cfg := &config.AppConfig{
+		PublicBaseURL: "https://relaypulse.top",
+		Monitors: []config.ServiceConfig{
+			{Provider: "Demo", ProviderSlug: "demo", Service: "cc"},
+		},
+	}
+	server := NewServer(store, cfg)
+
+	tests := []struct {
+		name     string
+		path     string
+		code     int
+		contains []string
+	}{
+		{"中文全站 Feed", "/feed.xml", http.StatusOK, []string{`xml:lang="zh-Hans"`, "Demo / cc 不可用", "network_error"}},
+		{"英文服务商 Feed", "/en/p/demo/feed.xml", http.StatusOK, []string{`xml:lang="en"`, "Demo / cc is down", "https://relaypulse.top/en/p/demo"}},
+		{"服务商不存在", "/p/unknown/feed.xml", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.code {
+				t.Fatalf("状态码 = %d, want %d", w.Code, tt.code)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("响应中缺少 %q:\n%s", want, w.Body.String())
+				}
+			}
+			if strings.Contains(w.Body.String(), "已恢复") || strings.Contains(w.Body.String(), "recovered") {
+				t.Errorf("不应包含远程地域结果产生的恢复事件:\n%s", w.Body.String())
+			}
+		})
+	}
+}
+
+// TestInjectMetaTagsFeedDiscovery 验证页面 head 中注入 Feed 自动发现链接
+func TestInjectMetaTagsFeedDiscovery(t *testing.T) {
+	cfg := &config.AppConfig{
+		PublicBaseURL: "https://relaypulse.top",
+		Monitors: []config.ServiceConfig{
+			{Provider: "FoxCode", ProviderSlug: "foxcode"},
+		},
+	}
+	indexHTML := `<html lang="en"><head><title>x</title><meta name="description" content="x"></head></html>`
+
+	html, _ := injectMetaTags(indexHTML, "/ja/p/foxcode", cfg)
+
+	for _, want := range []string{
+		`type="application/atom+xml"`,
+		`href="https://relaypulse.top/ja/feed.xml"`,
+		`href="https://relaypulse.top/ja/p/foxcode/feed.xml"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("缺少 Feed 自动发现链接 %q", want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"golang.org/x/sync/singleflight"
 
 	"monitor/internal/config"
+	"monitor/internal/schedule"
 	"monitor/internal/storage"
 )
 
@@ -128,6 +130,17 @@ type Handler struct {
 	config  *config.AppConfig
 	cfgMu   sync.RWMutex   // 保护config的并发访问
 	cache   *statusCache   // API 响应缓存
+
+	feedCache *statusCache // Feed 响应缓存（TTL 较长）
+
+	// 管理 API（未启用时为 nil）
+	editor          *config.Editor
+	onConfigApplied func(*config.AppConfig)
+	prober          MonitorProber
+
+	// 就绪检查（未设置时调度器检查视为 disabled）
+	scheduler SchedulerReporter
+	cluster   ClusterReporter // 仅多副本模式
 }
 
 // NewHandler 创建处理器
@@ -136,6 +149,8 @@ func NewHandler(store storage.Storage, cfg *config.AppConfig) *Handler {
 		storage: store,
 		config:  cfg,
 		cache:   newStatusCache(30*time.Second, 100), // 30 秒缓存，最多 100 条
+
+		feedCache: newStatusCache(feedCacheTTL, 100),
 	}
 }
 
@@ -158,6 +173,12 @@ type MonitorResult struct {
 	Channel     string              `json:"channel"`  // 业务通道标识
 	Current     *CurrentStatus      `json:"current_status"`
 	Timeline    []storage.TimePoint `json:"timeline"`
+
+	Labels map[string]string `json:"labels,omitempty"` // 自定义标签
+
+	// 多地域探测（汇总视图且有远程探测节点上报时返回）
+	Regions     []RegionStatus `json:"regions,omitempty"`      // 各地域最新状态
+	RegionState string         `json:"region_state,omitempty"` // ok / partial_down / all_down
 }
 
 // GetStatus 获取监控状态
@@ -166,6 +187,14 @@ func (h *Handler) GetStatus(c *gin.Context) {
 	period := c.DefaultQuery("period", "24h")
 	qProvider := strings.ToLower(strings.TrimSpace(c.DefaultQuery("provider", "all")))
 	qService := c.DefaultQuery("service", "all")
+	qRegion := strings.TrimSpace(c.Query("region"))
+
+	// 标签过滤：?label=tier:vip，可重复（需全部匹配）
+	selector, err := parseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// 验证 period 参数
 	if _, err := h.parsePeriod(period); err != nil {
@@ -175,15 +204,27 @@ func (h *Handler) GetStatus(c *gin.Context) {
 		return
 	}
 
+	// 验证 region 和 label 参数（只接受已知地域和配置中存在的标签，避免缓存 key 无限增长）
+	h.cfgMu.RLock()
+	view, err := resolveRegionView(h.config, qRegion)
+	if err == nil {
+		err = checkLabelSelector(h.config.Monitors, selector)
+	}
+	h.cfgMu.RUnlock()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 构建缓存 key（使用明确的分隔符避免碰撞）
-	cacheKey := fmt.Sprintf("p=%s|prov=%s|svc=%s", period, qProvider, qService)
+	cacheKey := fmt.Sprintf("p=%s|prov=%s|svc=%s|region=%s|labels=%q", period, qProvider, qService, view.region, config.FormatLabels(selector))
 
 	// 使用缓存（singleflight 防止缓存击穿）
 	// 注意：使用独立 context，避免单个请求取消影响其他等待的请求
 	data, err := h.cache.load(cacheKey, func() ([]byte, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		return h.queryAndSerialize(ctx, period, qProvider, qService)
+		return h.queryAndSerialize(ctx, period, qProvider, qService, selector, view)
 	})
 
 	if err != nil {
@@ -201,7 +242,7 @@ func (h *Handler) GetStatus(c *gin.Context) {
 }
 
 // queryAndSerialize 查询数据库并序列化为 JSON（缓存 miss 时调用）
-func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qService string) ([]byte, error) {
+func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qService string, selector map[string]string, view regionView) ([]byte, error) {
 	since, _ := h.parsePeriod(period) // 已在调用前验证
 
 	// 获取配置副本（线程安全）
@@ -211,6 +252,7 @@ func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qSer
 	enableConcurrent := h.config.EnableConcurrentQuery
 	concurrentLimit := h.config.ConcurrentQueryLimit
 	slowLatencyMs := int(h.config.SlowLatencyDuration / time.Millisecond)
+	regions := knownRegions(h.config)
 	h.cfgMu.RUnlock()
 
 	// 构建 slug -> provider 映射（slug作为provider的路由别名）
@@ -227,7 +269,7 @@ func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qSer
 	}
 
 	// 过滤并去重监控项
-	filtered := h.filterMonitors(monitors, realProvider, qService)
+	filtered := h.filterMonitors(monitors, realProvider, qService, selector)
 
 	// 根据配置选择串行或并发查询
 	var response []MonitorResult
@@ -236,10 +278,10 @@ func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qSer
 
 	if enableConcurrent {
 		mode = "concurrent"
-		response, err = h.getStatusConcurrent(ctx, filtered, since, period, degradedWeight, concurrentLimit)
+		response, err = h.getStatusConcurrent(ctx, filtered, since, period, degradedWeight, concurrentLimit, view)
 	} else {
 		mode = "serial"
-		response, err = h.getStatusSerial(ctx, filtered, since, period, degradedWeight)
+		response, err = h.getStatusSerial(ctx, filtered, since, period, degradedWeight, view)
 	}
 
 	if err != nil {
@@ -249,12 +291,22 @@ func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qSer
 	log.Printf("[API] GetStatus 查询 mode=%s monitors=%d period=%s count=%d", mode, len(filtered), period, len(response))
 
 	// 序列化为 JSON
+	meta := gin.H{
+		"period":          period,
+		"count":           len(response),
+		"slow_latency_ms": slowLatencyMs,
+	}
+	if len(regions) > 1 {
+		meta["regions"] = regions
+	}
+	if view.region != "" {
+		meta["region"] = view.region
+	}
+	if len(selector) > 0 {
+		meta["labels"] = selector
+	}
 	result := gin.H{
-		"meta": gin.H{
-			"period":          period,
-			"count":           len(response),
-			"slow_latency_ms": slowLatencyMs,
-		},
+		"meta": meta,
 		"data": response,
 	}
 
@@ -262,7 +314,7 @@ func (h *Handler) queryAndSerialize(ctx context.Context, period, qProvider, qSer
 }
 
 // filterMonitors 过滤并去重监控项
-func (h *Handler) filterMonitors(monitors []config.ServiceConfig, provider, service string) []config.ServiceConfig {
+func (h *Handler) filterMonitors(monitors []config.ServiceConfig, provider, service string, selector map[string]string) []config.ServiceConfig {
 	var filtered []config.ServiceConfig
 	seen := make(map[string]bool)
 
@@ -276,6 +328,9 @@ func (h *Handler) filterMonitors(monitors []config.ServiceConfig, provider, serv
 		if service != "all" && service != task.Service {
 			continue
 		}
+		if !config.MatchLabels(task.Labels, selector) {
+			continue
+		}
 
 		// 去重（使用 provider + service + channel 组合）
 		key := task.Provider + "/" + task.Service + "/" + task.Channel
@@ -290,8 +345,38 @@ func (h *Handler) filterMonitors(monitors []config.ServiceConfig, provider, serv
 	return filtered
 }
 
+// parseLabelSelector 解析 label 查询参数（key:value），同一标签名出现多次时报错
+func parseLabelSelector(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	selector := make(map[string]string, len(values))
+	for _, raw := range values {
+		key, value, ok := strings.Cut(raw, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("无效的标签过滤: %s（格式为 label=key:value）", raw)
+		}
+		if prev, exists := selector[key]; exists && prev != value {
+			return nil, fmt.Errorf("标签 %s 不能同时匹配 %s 和 %s", key, prev, value)
+		}
+		selector[key] = value
+	}
+	return selector, nil
+}
+
+// checkLabelSelector 检查每个标签过滤条件都至少匹配一个监控项
+func checkLabelSelector(monitors []config.ServiceConfig, selector map[string]string) error {
+	for k, v := range selector {
+		if !slices.ContainsFunc(monitors, func(m config.ServiceConfig) bool { return m.Labels[k] == v }) {
+			return fmt.Errorf("未知的标签: %s:%s", k, v)
+		}
+	}
+	return nil
+}
+
 // getStatusSerial 串行查询（原有逻辑）
-func (h *Handler) getStatusSerial(ctx context.Context, monitors []config.ServiceConfig, since time.Time, period string, degradedWeight float64) ([]MonitorResult, error) {
+func (h *Handler) getStatusSerial(ctx context.Context, monitors []config.ServiceConfig, since time.Time, period string, degradedWeight float64, view regionView) ([]MonitorResult, error) {
 	var response []MonitorResult
 	store := h.storage.WithContext(ctx)
 
@@ -309,7 +394,7 @@ func (h *Handler) getStatusSerial(ctx context.Context, monitors []config.Service
 		}
 
 		// 构建响应
-		result := h.buildMonitorResult(task, latest, history, period, degradedWeight)
+		result := h.buildMonitorResult(task, latest, history, period, degradedWeight, view)
 		response = append(response, result)
 	}
 
@@ -317,7 +402,7 @@ func (h *Handler) getStatusSerial(ctx context.Context, monitors []config.Service
 }
 
 // getStatusConcurrent 并发查询（使用 errgroup + 并发限制）
-func (h *Handler) getStatusConcurrent(ctx context.Context, monitors []config.ServiceConfig, since time.Time, period string, degradedWeight float64, limit int) ([]MonitorResult, error) {
+func (h *Handler) getStatusConcurrent(ctx context.Context, monitors []config.ServiceConfig, since time.Time, period string, degradedWeight float64, limit int, view regionView) ([]MonitorResult, error) {
 	// 使用请求的 context（支持取消）
 	g, gctx := errgroup.WithContext(ctx)
 	g.SetLimit(limit) // 限制最大并发度
@@ -342,7 +427,7 @@ func (h *Handler) getStatusConcurrent(ctx context.Context, monitors []config.Ser
 			}
 
 			// 构建响应（固定位置写入，保持顺序）
-			results[i] = h.buildMonitorResult(task, latest, history, period, degradedWeight)
+			results[i] = h.buildMonitorResult(task, latest, history, period, degradedWeight, view)
 			return nil
 		})
 	}
@@ -356,9 +441,13 @@ func (h *Handler) getStatusConcurrent(ctx context.Context, monitors []config.Ser
 }
 
 // buildMonitorResult 构建单个监控项的响应结构
-func (h *Handler) buildMonitorResult(task config.ServiceConfig, latest *storage.ProbeRecord, history []*storage.ProbeRecord, period string, degradedWeight float64) MonitorResult {
+func (h *Handler) buildMonitorResult(task config.ServiceConfig, latest *storage.ProbeRecord, history []*storage.ProbeRecord, period string, degradedWeight float64, view regionView) MonitorResult {
+	// 按地域过滤（汇总视图使用所有地域的记录）
+	regions, regionState := view.summarize(history, time.Now())
+	latest, history = view.filter(latest, history)
+
 	// 转换为时间轴数据
-	timeline := h.buildTimeline(history, period, degradedWeight)
+	timeline := h.buildTimeline(history, period, degradedWeight, task.ScheduleSpec)
 
 	// 转换为API响应格式（不暴露数据库主键）
 	var current *CurrentStatus
@@ -385,8 +474,11 @@ func (h *Handler) buildMonitorResult(task config.ServiceConfig, latest *storage.
 		Sponsor:      task.Sponsor,
 		SponsorURL:   task.SponsorURL,
 		Channel:      task.Channel,
+		Labels:       task.Labels,
 		Current:      current,
 		Timeline:     timeline,
+		Regions:      regions,
+		RegionState:  regionState,
 	}
 }
 
@@ -417,7 +509,8 @@ type bucketStats struct {
 }
 
 // buildTimeline 构建固定长度的时间轴，计算每个 bucket 的可用率和平均延迟
-func (h *Handler) buildTimeline(records []*storage.ProbeRecord, period string, degradedWeight float64) []storage.TimePoint {
+// spec 为监控项的探测日程（可为 nil），没有记录且未安排探测的 bucket 标记为"未排期"而不是缺失
+func (h *Handler) buildTimeline(records []*storage.ProbeRecord, period string, degradedWeight float64, spec *schedule.Spec) []storage.TimePoint {
 	// 根据 period 确定 bucket 策略
 	bucketCount, bucketWindow, format := h.determineBucketStrategy(period)
 
@@ -480,6 +573,10 @@ func (h *Handler) buildTimeline(records []*storage.ProbeRecord, period string, d
 		stat := &stats[i]
 		buckets[i].StatusCounts = stat.statusCounts
 		if stat.total == 0 {
+			start := now.Add(-time.Duration(bucketCount-i) * bucketWindow)
+			if !spec.ScheduledBetween(start, start.Add(bucketWindow)) {
+				buckets[i].Status = storage.StatusNotScheduled
+			}
 			continue
 		}
 
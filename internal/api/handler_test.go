@@ -1,10 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"monitor/internal/config"
+	"monitor/internal/schedule"
 	"monitor/internal/storage"
 )
 
@@ -81,7 +83,7 @@ func TestBuildTimelineLatencyCalculation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// 调用 buildTimeline
-			timeline := h.buildTimeline(tt.records, "24h", 0.7)
+			timeline := h.buildTimeline(tt.records, "24h", 0.7, nil)
 
 			// 找到有数据的 bucket（最后一个，因为所有记录时间戳都是 now）
 			var latency int
@@ -116,7 +118,7 @@ func TestBuildTimelineLatencyRounding(t *testing.T) {
 		{Status: 1, Latency: 101, Timestamp: now.Unix()},
 	}
 
-	timeline := h.buildTimeline(records, "24h", 0.7)
+	timeline := h.buildTimeline(records, "24h", 0.7, nil)
 
 	var latency int
 	for _, point := range timeline {
@@ -131,3 +133,74 @@ func TestBuildTimelineLatencyRounding(t *testing.T) {
 		t.Errorf("四舍五入测试失败: 期望 101ms，实际 %dms", latency)
 	}
 }
+
+// TestBuildTimelineNotScheduled 测试探测日程之外的时间块标记为未排期而不是缺失
+func TestBuildTimelineNotScheduled(t *testing.T) {
+	h := &Handler{config: &config.AppConfig{DegradedWeight: 0.7}}
+
+	// 每天只在 5.5 小时前的那一分钟探测一次：24 个小时块中只有 1 个已排期
+	fire := time.Now().UTC().Add(-330 * time.Minute)
+	spec, err := schedule.Parse(fmt.Sprintf("%d %d * * *", fire.Minute(), fire.Hour()), "UTC", "", nil)
+	if err != nil {
+		t.Fatalf("schedule.Parse() error = %v", err)
+	}
+
+	timeline := h.buildTimeline(nil, "24h", 0.7, spec)
+	var missing, notScheduled int
+	for _, point := range timeline {
+		switch point.Status {
+		case -1:
+			missing++
+		case storage.StatusNotScheduled:
+			notScheduled++
+		}
+	}
+	if missing != 1 || notScheduled != 23 {
+		t.Errorf("missing = %d, not scheduled = %d; want 1, 23", missing, notScheduled)
+	}
+
+	// 有记录的时间块照常计算
+	records := []*storage.ProbeRecord{{Status: 1, Latency: 100, Timestamp: time.Now().Unix()}}
+	if last := h.buildTimeline(records, "24h", 0.7, spec)[23]; last.Status != 1 {
+		t.Errorf("有记录的时间块 Status = %d, want 1", last.Status)
+	}
+}
+
+// TestLabelFilter 验证 label 查询参数解析和按标签过滤监控项
+func TestLabelFilter(t *testing.T) {
+	for _, bad := range [][]string{{"tier"}, {"tier:"}, {":vip"}, {"tier:vip", "tier:free"}} {
+		if _, err := parseLabelSelector(bad); err == nil {
+			t.Errorf("parseLabelSelector(%q) 应返回错误", bad)
+		}
+	}
+	selector, err := parseLabelSelector([]string{"tier:vip", "region: hk", "tier:vip"})
+	if err != nil || len(selector) != 2 || selector["region"] != "hk" {
+		t.Fatalf("parseLabelSelector = %v, %v", selector, err)
+	}
+
+	monitors := []config.ServiceConfig{
+		{Provider: "A", Service: "cc", Labels: map[string]string{"tier": "vip", "region": "hk"}},
+		{Provider: "B", Service: "cc", Labels: map[string]string{"tier": "vip"}},
+		{Provider: "C", Service: "cc"},
+	}
+	h := &Handler{}
+	if got := h.filterMonitors(monitors, "all", "all", selector); len(got) != 1 || got[0].Provider != "A" {
+		t.Errorf("filterMonitors(tier=vip,region=hk) = %+v", got)
+	}
+	if got := h.filterMonitors(monitors, "all", "all", map[string]string{"tier": "vip"}); len(got) != 2 {
+		t.Errorf("filterMonitors(tier=vip) 应返回 2 个监控项，got %d", len(got))
+	}
+	if got := h.filterMonitors(monitors, "all", "all", nil); len(got) != 3 {
+		t.Errorf("无标签过滤时应返回全部监控项，got %d", len(got))
+	}
+
+	// 只接受配置中存在的标签（避免任意取值占满缓存）
+	if err := checkLabelSelector(monitors, selector); err != nil {
+		t.Errorf("checkLabelSelector(tier=vip,region=hk) = %v", err)
+	}
+	for _, bad := range []map[string]string{{"tier": "free"}, {"zone": "hk"}} {
+		if err := checkLabelSelector(monitors, bad); err == nil {
+			t.Errorf("checkLabelSelector(%v) 应返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/cluster"
+	"monitor/internal/scheduler"
+)
+
+const (
+	// healthPingTimeout 存储连通性检查超时
+	healthPingTimeout = 2 * time.Second
+
+	// schedulerStaleIntervals 超过多少个巡检间隔调度循环没有运行视为调度器停滞
+	// 调度循环至少每个间隔运行一次，留出 2 个间隔的余量
+	schedulerStaleIntervals = 3
+)
+
+// 健康检查结果
+const (
+	healthStatusOK       = "ok"
+	healthStatusFail     = "fail"
+	healthStatusDisabled = "disabled"
+	healthStatusStandby  = "standby"
+)
+
+// SchedulerReporter 提供调度器运行状态（由 scheduler.Scheduler 实现）
+type SchedulerReporter interface {
+	Status() scheduler.Status
+}
+
+// ClusterReporter 提供多副本集群状态（由 cluster.Elector / cluster.Sharder 实现）
+type ClusterReporter interface {
+	Status() cluster.Status
+}
+
+// HealthCheck 单项检查结果
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// storage
+	LatencyMs *int64 `json:"latency_ms,omitempty"`
+
+	// scheduler
+	Interval          string     `json:"interval,omitempty"`
+	LastDispatch      *time.Time `json:"last_dispatch,omitempty"`
+	LastProbeFinished *time.Time `json:"last_probe_finished,omitempty"`
+	InFlight          *int       `json:"in_flight,omitempty"`
+
+	// notifier
+	Enabled *bool `json:"enabled,omitempty"`
+	Active  *bool `json:"active,omitempty"`
+}
+
+// HealthConfigInfo 当前生效配置的版本信息
+type HealthConfigInfo struct {
+	Version  string    `json:"version"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Monitors int       `json:"monitors"`
+}
+
+// HealthReport 详细健康检查结果（/ready 与 /health?verbose=1）
+type HealthReport struct {
+	Status  string                 `json:"status"`
+	Checks  map[string]HealthCheck `json:"checks"`
+	Config  HealthConfigInfo       `json:"config"`
+	Cluster *cluster.Status        `json:"cluster,omitempty"` // 仅多副本模式
+}
+
+// SetScheduler 设置调度器状态来源（用于就绪检查）
+func (s *Server) SetScheduler(r SchedulerReporter) {
+	s.handler.scheduler = r
+}
+
+// SetCluster 设置集群状态来源（在 /health 中展示当前主节点或分片成员）
+func (s *Server) SetCluster(r ClusterReporter) {
+	s.handler.cluster = r
+}
+
+// GetHealth 存活检查；verbose=1 时返回与 /ready 相同的详细检查结果
+func (h *Handler) GetHealth(c *gin.Context) {
+	if v := c.Query("verbose"); v == "" || v == "0" || v == "false" {
+		body := gin.H{"status": "ok"}
+		if h.cluster != nil {
+			st := h.cluster.Status()
+			c.Header("Cache-Control", "no-store")
+			body["node_id"] = st.NodeID
+			if st.Mode == "shard" {
+				body["members"] = st.Members
+			} else {
+				body["leader"] = st.Leader
+				body["is_leader"] = st.IsLeader
+			}
+		}
+		c.JSON(http.StatusOK, body)
+		return
+	}
+	h.GetReady(c)
+}
+
+// GetReady 就绪检查：存储连通性、调度循环是否按时运行、通知器状态，任一失败返回 503
+func (h *Handler) GetReady(c *gin.Context) {
+	report := h.checkHealth(c.Request.Context())
+
+	c.Header("Cache-Control", "no-store")
+	code := http.StatusOK
+	if report.Status != healthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
+
+// checkHealth 执行全部检查
+func (h *Handler) checkHealth(ctx context.Context) *HealthReport {
+	h.cfgMu.RLock()
+	cfg := h.config
+	h.cfgMu.RUnlock()
+
+	report := &HealthReport{
+		Status: healthStatusOK,
+		Checks: map[string]HealthCheck{
+			"storage": h.checkStorage(ctx),
+		},
+		Config: HealthConfigInfo{
+			Version:  cfg.Version,
+			LoadedAt: cfg.LoadedAt,
+			Monitors: len(cfg.Monitors),
+		},
+	}
+
+	var st *scheduler.Status
+	if h.scheduler != nil {
+		status := h.scheduler.Status()
+		st = &status
+	}
+	report.Checks["scheduler"] = checkScheduler(st, time.Now())
+	report.Checks["notifier"] = checkNotifier(cfg.Notifier.Enabled, st)
+
+	if h.cluster != nil {
+		status := h.cluster.Status()
+		report.Cluster = &status
+	}
+
+	for _, check := range report.Checks {
+		if check.Status == healthStatusFail {
+			report.Status = healthStatusFail
+			break
+		}
+	}
+	return report
+}
+
+// checkStorage 检查存储连通性
+func (h *Handler) checkStorage(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.storage.WithContext(ctx).Ping()
+	latency := time.Since(start).Milliseconds()
+
+	check := HealthCheck{Status: healthStatusOK, LatencyMs: &latency}
+	if err != nil {
+		check.Status = healthStatusFail
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// checkScheduler 检查调度循环是否在预期时间内运行
+// 未启动调度器（--no-scheduler 或未设置）时视为 disabled，不影响就绪状态
+func checkScheduler(st *scheduler.Status, now time.Time) HealthCheck {
+	if st == nil || !st.Running {
+		return HealthCheck{Status: healthStatusDisabled}
+	}
+	if st.Standby {
+		// 备节点不巡检，由主节点负责
+		return HealthCheck{Status: healthStatusStandby, Interval: st.Interval.String()}
+	}
+
+	check := HealthCheck{
+		Status:   healthStatusOK,
+		Interval: st.Interval.String(),
+		InFlight: &st.InFlight,
+	}
+	if !st.LastDispatch.IsZero() {
+		check.LastDispatch = &st.LastDispatch
+	}
+	if !st.LastProbeEnd.IsZero() {
+		check.LastProbeFinished = &st.LastProbeEnd
+	}
+
+	// 从调度循环最近一次运行（或启动/转为主节点的时间，取较晚者）开始计算
+	// 单个监控项探测卡住不会阻塞调度循环，通过 /metrics 中的调度延迟观察
+	since := st.LastDispatch
+	if st.ActiveSince.After(since) {
+		since = st.ActiveSince
+	}
+	if staleAfter := schedulerStaleIntervals * st.Interval; staleAfter > 0 && now.Sub(since) > staleAfter {
+		check.Status = healthStatusFail
+		check.Error = "scheduler loop has not run within " + staleAfter.String()
+	}
+	return check
+}
+
+// checkNotifier 检查通知器：配置启用但未能初始化时视为失败
+func checkNotifier(enabled bool, st *scheduler.Status) HealthCheck {
+	check := HealthCheck{Status: healthStatusOK, Enabled: &enabled}
+	if !enabled {
+		check.Status = healthStatusDisabled
+		return check
+	}
+	if st == nil || !st.Running {
+		// 调度器未运行时通知器不会被使用
+		check.Status = healthStatusDisabled
+		return check
+	}
+
+	active := st.NotifierActive
+	check.Active = &active
+	if !active {
+		check.Status = healthStatusFail
+		check.Error = "notifier enabled but not initialized"
+	}
+	return check
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/scheduler"
+	"monitor/internal/storage"
+)
+
+// healthTestStorage 可控制 Ping 结果的存储
+type healthTestStorage struct {
+	badgeTestStorage
+	pingErr error
+}
+
+func (s *healthTestStorage) WithContext(ctx context.Context) storage.Storage { return s }
+
+func (s *healthTestStorage) Ping() error { return s.pingErr }
+
+// fakeSchedulerReporter 固定返回的调度器状态
+type fakeSchedulerReporter struct{ status scheduler.Status }
+
+func (f fakeSchedulerReporter) Status() scheduler.Status { return f.status }
+
+// TestReadiness 验证 /ready 与 /health?verbose=1 的检查项和状态码
+func TestReadiness(t *testing.T) {
+	now := time.Now()
+	healthy := scheduler.Status{
+		Running:        true,
+		Interval:       time.Minute,
+		ActiveSince:    now.Add(-time.Hour),
+		LastDispatch:   now.Add(-40 * time.Second),
+		LastProbeEnd:   now.Add(-30 * time.Second),
+		NotifierActive: true,
+	}
+	stale := healthy
+	stale.LastDispatch = now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name      string
+		pingErr   error
+		scheduler SchedulerReporter
+		notifier  bool
+		wantCode  int
+		wantFail  string
+	}{
+		{"全部正常", nil, fakeSchedulerReporter{healthy}, true, http.StatusOK, ""},
+		{"未启动调度器", nil, nil, false, http.StatusOK, ""},
+		{"数据库不可用", errors.New("connection refused"), fakeSchedulerReporter{healthy}, false, http.StatusServiceUnavailable, "storage"},
+		{"调度器停滞", nil, fakeSchedulerReporter{stale}, false, http.StatusServiceUnavailable, "scheduler"},
+		{"通知器未初始化", nil, fakeSchedulerReporter{scheduler.Status{Running: true, Interval: time.Minute, ActiveSince: now}}, true, http.StatusServiceUnavailable, "notifier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AppConfig{Version: "abc123def456", LoadedAt: now}
+			cfg.Notifier.Enabled = tt.notifier
+			server := NewServer(&healthTestStorage{pingErr: tt.pingErr}, cfg)
+			if tt.scheduler != nil {
+				server.SetScheduler(tt.scheduler)
+			}
+
+			for _, path := range []string{"/ready", "/health?verbose=1"} {
+				w := policyTestRequest(server, http.MethodGet, path, nil)
+				if w.Code != tt.wantCode {
+					t.Fatalf("%s 状态码 = %d, want %d, body=%s", path, w.Code, tt.wantCode, w.Body.String())
+				}
+
+				var report HealthReport
+				if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+					t.Fatalf("%s 响应解析失败: %v", path, err)
+				}
+				if report.Config.Version != "abc123def456" {
+					t.Errorf("config.version = %q", report.Config.Version)
+				}
+				if tt.wantFail != "" && report.Checks[tt.wantFail].Status != healthStatusFail {
+					t.Errorf("%s: checks.%s = %+v, want fail", path, tt.wantFail, report.Checks[tt.wantFail])
+				}
+			}
+
+			// 存活检查不受依赖状态影响
+			if w := policyTestRequest(server, http.MethodGet, "/health", nil); w.Code != http.StatusOK {
+				t.Errorf("/health 状态码 = %d, want 200", w.Code)
+			}
+		})
+	}
+}
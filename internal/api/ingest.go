@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/agent"
+	"monitor/internal/config"
+	"monitor/internal/metrics"
+	"monitor/internal/storage"
+)
+
+const (
+	// ingestMaxBodyBytes 单次上报请求体上限（一批最多 500 条结果，远小于该值）
+	ingestMaxBodyBytes = 2 << 20
+
+	// ingestMaxAge 接受的最旧结果（节点离线期间缓存的结果仍可补报）
+	ingestMaxAge = 7 * 24 * time.Hour
+)
+
+// ingestResultsTotal 探测节点上报的结果数（按节点和处理结果统计）
+var ingestResultsTotal = metrics.NewCounterVec(
+	"monitor_ingest_results_total",
+	"Probe results reported by remote agents.",
+	"agent", "result",
+)
+
+// PostIngest 接收远程探测节点上报的结果
+// 路由：POST /api/ingest，请求头携带 X-Agent-ID / X-Agent-Timestamp / X-Agent-Signature
+// 结果按节点配置的地域入库；未在本服务配置的监控项计入 rejected，不会触发告警
+func (h *Handler) PostIngest(c *gin.Context) {
+	h.cfgMu.RLock()
+	agents := h.config.Ingest.Agents
+	monitors := h.config.Monitors
+	h.cfgMu.RUnlock()
+
+	if len(agents) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
+		return
+	}
+
+	agentID := c.GetHeader(agent.HeaderAgentID)
+	var cred *config.IngestAgent
+	for i := range agents {
+		if agents[i].ID == agentID {
+			cred = &agents[i]
+			break
+		}
+	}
+	if cred == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unknown agent"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, ingestMaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取请求体失败"})
+		return
+	}
+
+	now := time.Now()
+	if err := agent.Verify(cred.Secret, c.GetHeader(agent.HeaderTimestamp), c.GetHeader(agent.HeaderSignature), body, now); err != nil {
+		log.Printf("[API] 探测节点 %s 上报校验失败: %v", agentID, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payload agent.Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求体不是有效的 JSON"})
+		return
+	}
+	if payload.AgentID != cred.ID || (payload.Region != "" && payload.Region != cred.Region) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "agent_id 或 region 与中心服务配置不一致"})
+		return
+	}
+
+	configured := make(map[config.MonitorKey]bool, len(monitors))
+	for _, m := range monitors {
+		configured[config.KeyOf(m)] = true
+	}
+
+	var resp agent.IngestResponse
+	records := make([]*storage.ProbeRecord, 0, len(payload.Results))
+	for _, r := range payload.Results {
+		key := config.MonitorKey{Provider: r.Provider, Service: r.Service, Channel: r.Channel}
+		if !configured[key] || !validIngestResult(r, now) {
+			resp.Rejected++
+			continue
+		}
+
+		records = append(records, &storage.ProbeRecord{
+			Provider:  r.Provider,
+			Service:   r.Service,
+			Channel:   r.Channel,
+			Status:    r.Status,
+			SubStatus: r.SubStatus,
+			Latency:   r.Latency,
+			Timestamp: r.Timestamp,
+			Region:    cred.Region,
+			Attempts:  r.Attempts,
+		})
+	}
+
+	saved, err := saveIngestRecords(h.storage.WithContext(c.Request.Context()), records)
+	if err != nil {
+		// 整批回滚，由节点重试
+		log.Printf("[API] 保存探测节点 %s 的结果失败: %v", cred.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存结果失败"})
+		return
+	}
+	resp.Accepted = saved
+	resp.Duplicates = len(records) - saved
+
+	ingestResultsTotal.Add(float64(resp.Accepted), cred.ID, "accepted")
+	ingestResultsTotal.Add(float64(resp.Duplicates), cred.ID, "duplicate")
+	ingestResultsTotal.Add(float64(resp.Rejected), cred.ID, "rejected")
+	c.JSON(http.StatusOK, resp)
+}
+
+// saveIngestRecords 保存一批上报结果，返回实际写入的条数
+// 存储实现 IngestStore 时在一个事务中写入并跳过重复记录（同一批结果被重放或重试时不会重复入库）
+func saveIngestRecords(store storage.Storage, records []*storage.ProbeRecord) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if batch, ok := store.(storage.IngestStore); ok {
+		return batch.SaveIngestBatch(records)
+	}
+	for i, record := range records {
+		if err := store.SaveRecord(record); err != nil {
+			return i, err
+		}
+	}
+	return len(records), nil
+}
+
+// validIngestResult 校验上报结果的状态码、尝试次数和时间戳
+func validIngestResult(r agent.Result, now time.Time) bool {
+	if r.Status < 0 || r.Status > 2 || r.Latency < 0 || r.Attempts < 1 || r.Attempts > config.MaxConfirmRetries+1 {
+		return false
+	}
+	ts := time.Unix(r.Timestamp, 0)
+	return ts.After(now.Add(-ingestMaxAge)) && !ts.After(now.Add(agent.MaxClockSkew))
+}
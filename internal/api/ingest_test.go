@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"monitor/internal/agent"
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// ingestTestStorage 记录写入的探测记录
+type ingestTestStorage struct {
+	badgeTestStorage
+	saved []*storage.ProbeRecord
+}
+
+func (s *ingestTestStorage) WithContext(ctx context.Context) storage.Storage { return s }
+
+func (s *ingestTestStorage) SaveRecord(record *storage.ProbeRecord) error {
+	s.saved = append(s.saved, record)
+	return nil
+}
+
+func ingestTestRequest(server *Server, agentID, secret string, ts time.Time, payload agent.Payload) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, agent.IngestPath, bytes.NewReader(body))
+	req.Header.Set(agent.HeaderAgentID, agentID)
+	req.Header.Set(agent.HeaderTimestamp, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(agent.HeaderSignature, agent.Sign(secret, ts.Unix(), body))
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+// TestPostIngest 验证签名校验、地域入库和未配置监控项的拒绝
+func TestPostIngest(t *testing.T) {
+	now := time.Now()
+	cfg := &config.AppConfig{
+		Monitors: []config.ServiceConfig{{Provider: "Demo", Service: "cc", Channel: "vip"}},
+		Ingest: config.IngestConfig{
+			Agents: []config.IngestAgent{{ID: "sg-1", Region: "sg", Secret: "s3cret"}},
+		},
+	}
+	store := &ingestTestStorage{}
+	server := NewServer(store, cfg)
+
+	payload := agent.Payload{
+		AgentID: "sg-1",
+		Region:  "sg",
+		Results: []agent.Result{
+			{Provider: "Demo", Service: "cc", Channel: "vip", Status: 0, SubStatus: storage.SubStatusNetworkError, Latency: 120, Timestamp: now.Unix(), Attempts: 1},
+			{Provider: "Other", Service: "cc", Channel: "vip", Status: 1, Timestamp: now.Unix()},
+			{Provider: "Demo", Service: "cc", Channel: "vip", Status: 7, Timestamp: now.Unix(), Attempts: 1},
+			{Provider: "Demo", Service: "cc", Channel: "vip", Status: 1, Timestamp: now.Unix() - 60},
+		},
+	}
+
+	w := ingestTestRequest(server, "sg-1", "s3cret", now, payload)
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d, body=%s", w.Code, w.Body.String())
+	}
+	var resp agent.IngestResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应解析失败: %v", err)
+	}
+	if resp.Accepted != 1 || resp.Rejected != 3 {
+		t.Errorf("响应 = %+v, want accepted=1 rejected=3", resp)
+	}
+	if len(store.saved) != 1 || store.saved[0].Region != "sg" || store.saved[0].SubStatus != storage.SubStatusNetworkError {
+		t.Fatalf("入库记录 = %+v", store.saved)
+	}
+
+	tests := []struct {
+		name     string
+		agentID  string
+		secret   string
+		ts       time.Time
+		payload  agent.Payload
+		wantCode int
+	}{
+		{"未知节点", "us-1", "s3cret", now, payload, http.StatusUnauthorized},
+		{"密钥错误", "sg-1", "wrong", now, payload, http.StatusUnauthorized},
+		{"时间戳过期", "sg-1", "s3cret", now.Add(-10 * time.Minute), payload, http.StatusUnauthorized},
+		{"地域不一致", "sg-1", "s3cret", now, agent.Payload{AgentID: "sg-1", Region: "us"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := ingestTestRequest(server, tt.agentID, tt.secret, tt.ts, tt.payload); w.Code != tt.wantCode {
+				t.Errorf("状态码 = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+
+	// 未配置任何节点时接口不可用
+	cfg2 := cfg.Clone()
+	cfg2.Ingest.Agents = nil
+	server.UpdateConfig(cfg2)
+	if w := ingestTestRequest(server, "sg-1", "s3cret", now, payload); w.Code != http.StatusNotFound {
+		t.Errorf("未配置节点时状态码 = %d, want 404", w.Code)
+	}
+}
+
+// TestPostIngestReplay 验证同一批结果重放时不会重复入库
+func TestPostIngestReplay(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "monitor.db"))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	defer store.Close()
+	if err := store.Init(); err != nil {
+		t.Fatalf("初始化存储失败: %v", err)
+	}
+
+	now := time.Now()
+	cfg := &config.AppConfig{
+		Monitors: []config.ServiceConfig{{Provider: "Demo", Service: "cc", Channel: "vip"}},
+		Ingest: config.IngestConfig{
+			Agents: []config.IngestAgent{{ID: "sg-1", Region: "sg", Secret: "s3cret"}},
+		},
+	}
+	server := NewServer(store, cfg)
+
+	payload := agent.Payload{
+		AgentID: "sg-1",
+		Region:  "sg",
+		Results: []agent.Result{
+			{Provider: "Demo", Service: "cc", Channel: "vip", Status: 1, Latency: 80, Timestamp: now.Unix() - 120, Attempts: 1},
+			{Provider: "Demo", Service: "cc", Channel: "vip", Status: 1, Latency: 90, Timestamp: now.Unix() - 60, Attempts: 1},
+		},
+	}
+
+	want := []agent.IngestResponse{{Accepted: 2}, {Duplicates: 2}}
+	for i, wantResp := range want {
+		w := ingestTestRequest(server, "sg-1", "s3cret", now, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次上报状态码 = %d, body=%s", i+1, w.Code, w.Body.String())
+		}
+		var resp agent.IngestResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("响应解析失败: %v", err)
+		}
+		if resp != wantResp {
+			t.Errorf("第 %d 次上报响应 = %+v, want %+v", i+1, resp, wantResp)
+		}
+	}
+
+	records, err := store.GetHistory("Demo", "cc", "vip", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("查询历史失败: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("入库记录数 = %d, want 2", len(records))
+	}
+}
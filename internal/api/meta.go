@@ -74,6 +74,7 @@ type PageMeta struct {
 	OpenGraph   string // Open Graph 标签组
 	TwitterCard string // Twitter Card 标签组
 	JSONLD      string // JSON-LD 结构化数据
+	Feed        string // Atom Feed 自动发现链接
 }
 
 // parseRequestPath 解析请求路径，提取语言和 provider slug
@@ -246,7 +247,18 @@ func generatePageMeta(meta MetaData, baseURL string) PageMeta {
 		meta.Description,
 		ogImage)
 
-	// 6. JSON-LD 结构化数据
+	// 6. Atom Feed 自动发现（全站 Feed + 服务商 Feed）
+	var feedBuilder strings.Builder
+	feedBuilder.WriteString(fmt.Sprintf(`    <link rel="alternate" type="application/atom+xml" title="%s" href="%s">`,
+		html.EscapeString(getFeedTitle(meta.Language.Code, "")),
+		feedURL(baseURL, meta.Language, "")))
+	if meta.IsProviderPage {
+		feedBuilder.WriteString(fmt.Sprintf("\n"+`    <link rel="alternate" type="application/atom+xml" title="%s" href="%s">`,
+			html.EscapeString(getFeedTitle(meta.Language.Code, meta.ProviderName)),
+			feedURL(baseURL, meta.Language, meta.Slug)))
+	}
+
+	// 7. JSON-LD 结构化数据
 	var jsonLD string
 	if meta.IsProviderPage {
 		// 服务商页面：Service 类型
@@ -297,6 +309,7 @@ func generatePageMeta(meta MetaData, baseURL string) PageMeta {
 		OpenGraph:   openGraph,
 		TwitterCard: twitterCard,
 		JSONLD:      jsonLD,
+		Feed:        feedBuilder.String(),
 	}
 }
 
@@ -366,11 +379,12 @@ func injectMetaTags(indexHTML string, path string, cfg *config.AppConfig) (strin
 	html = replaceMetaDescription(html, metaData.Description)
 
 	// 在 </head> 前插入其他 meta 标签
-	additionalMeta := fmt.Sprintf("\n%s\n%s\n%s\n%s\n%s\n",
+	additionalMeta := fmt.Sprintf("\n%s\n%s\n%s\n%s\n%s\n%s\n",
 		pageMeta.Canonical,
 		pageMeta.Hreflang,
 		pageMeta.OpenGraph,
 		pageMeta.TwitterCard,
+		pageMeta.Feed,
 		pageMeta.JSONLD)
 
 	html = strings.Replace(html, "</head>", additionalMeta+"  </head>", 1)
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+)
+
+// gzipRequiredPrefixes gzip_mode=required 时强制要求 gzip 的路径前缀
+// /api/status 响应约 300KB，未压缩会瞬间打满带宽
+// 注意：/api/badge 徽章会被 README 图片代理（如 GitHub camo）直接拉取，不能纳入此列表
+var gzipRequiredPrefixes = []string{"/api/status"}
+
+// frameRule 按路径前缀覆盖的 frame-ancestors 策略
+type frameRule struct {
+	prefix string
+	value  string
+}
+
+// httpPolicy 可热更新的中间件策略（由 cfg.Server 构建，整体替换）
+type httpPolicy struct {
+	cors          gin.HandlerFunc
+	gzipRequired  bool
+	hsts          string // 为空表示不发送 HSTS
+	frameDefault  string
+	frameByPrefix []frameRule  // 按前缀长度降序，最长前缀优先
+	limiter       *rateLimiter // 未启用限流时为 nil
+	rateLimitCfg  config.RateLimitConfig
+}
+
+// newHTTPPolicy 根据服务配置构建中间件策略
+// 配置无效时（热更新已校验，理论上不会发生）退回默认策略；
+// prev 的限流配置未变化时沿用其限流器，避免热更新清空令牌桶
+func newHTTPPolicy(serverCfg config.ServerConfig, prev *httpPolicy) *httpPolicy {
+	// Normalize 会原地改写切片，避免影响调用方持有的配置
+	serverCfg.CORS.AllowedOrigins = append([]string(nil), serverCfg.CORS.AllowedOrigins...)
+	serverCfg.CORS.AllowedMethods = append([]string(nil), serverCfg.CORS.AllowedMethods...)
+
+	if err := serverCfg.Normalize(); err != nil {
+		log.Printf("[API] 服务配置无效，使用默认中间件策略: %v", err)
+		serverCfg = config.ServerConfig{}
+		_ = serverCfg.Normalize()
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     serverCfg.CORS.AllowedMethods,
+		AllowHeaders:     []string{"Origin", "Content-Type"},
+		ExposeHeaders:    []string{"Content-Length"},
+		AllowCredentials: false,
+		MaxAge:           12 * time.Hour,
+	}
+	for _, origin := range serverCfg.CORS.AllowedOrigins {
+		if origin == "*" {
+			corsConfig.AllowAllOrigins = true
+			break
+		}
+	}
+	if !corsConfig.AllowAllOrigins {
+		corsConfig.AllowOrigins = serverCfg.CORS.AllowedOrigins
+	}
+
+	policy := &httpPolicy{
+		cors:         cors.New(corsConfig),
+		gzipRequired: serverCfg.GzipMode == config.GzipModeRequired,
+		frameDefault: serverCfg.FrameAncestors.Default,
+		rateLimitCfg: serverCfg.RateLimit,
+	}
+	if prev != nil && reflect.DeepEqual(prev.rateLimitCfg, serverCfg.RateLimit) {
+		policy.limiter = prev.limiter
+	} else {
+		policy.limiter = newRateLimiter(serverCfg.RateLimit)
+	}
+
+	if *serverCfg.HSTS.Enabled {
+		policy.hsts = fmt.Sprintf("max-age=%d", serverCfg.HSTS.MaxAge)
+		if *serverCfg.HSTS.IncludeSubdomains {
+			policy.hsts += "; includeSubDomains"
+		}
+	}
+
+	for prefix, value := range serverCfg.FrameAncestors.Paths {
+		policy.frameByPrefix = append(policy.frameByPrefix, frameRule{prefix: prefix, value: strings.TrimSpace(value)})
+	}
+	sort.Slice(policy.frameByPrefix, func(i, j int) bool {
+		return len(policy.frameByPrefix[i].prefix) > len(policy.frameByPrefix[j].prefix)
+	})
+
+	return policy
+}
+
+// frameAncestors 返回路径对应的 frame-ancestors 策略
+func (p *httpPolicy) frameAncestors(path string) string {
+	for _, rule := range p.frameByPrefix {
+		if strings.HasPrefix(path, rule.prefix) {
+			return rule.value
+		}
+	}
+	return p.frameDefault
+}
+
+// requiresGzip 判断路径是否强制要求客户端支持 gzip
+func (p *httpPolicy) requiresGzip(path string) bool {
+	if !p.gzipRequired {
+		return false
+	}
+	for _, prefix := range gzipRequiredPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setFrameHeaders 设置防点击劫持响应头
+// "*" 表示允许任意嵌入（不发送任何限制头）；'self' / 'none' 同时发送 X-Frame-Options 以兼容旧浏览器
+func setFrameHeaders(c *gin.Context, value string) {
+	if value == "" || value == "*" {
+		return
+	}
+
+	c.Header("Content-Security-Policy", "frame-ancestors "+value)
+	switch value {
+	case "'self'":
+		c.Header("X-Frame-Options", "SAMEORIGIN")
+	case "'none'":
+		c.Header("X-Frame-Options", "DENY")
+	}
+}
+
+// corsMiddleware CORS 中间件（每个请求读取当前策略，支持热更新）
+func (s *Server) corsMiddleware(c *gin.Context) {
+	s.policy.Load().cors(c)
+}
+
+// gzipRequirementMiddleware 强制 gzip 中间件（仅针对大响应 API，保护 4Mb 带宽）
+func (s *Server) gzipRequirementMiddleware(c *gin.Context) {
+	if s.policy.Load().requiresGzip(c.Request.URL.Path) {
+		acceptEncoding := c.GetHeader("Accept-Encoding")
+		if !strings.Contains(acceptEncoding, "gzip") {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{
+				"error": "This endpoint requires gzip support. Add header: Accept-Encoding: gzip",
+			})
+			return
+		}
+	}
+	c.Next()
+}
+
+// securityHeadersMiddleware 安全头中间件
+func (s *Server) securityHeadersMiddleware(c *gin.Context) {
+	policy := s.policy.Load()
+
+	if policy.hsts != "" {
+		c.Header("Strict-Transport-Security", policy.hsts)
+	}
+
+	// 防止点击劫持（默认仅 /p/* 允许任意嵌入，iframe 友好）
+	setFrameHeaders(c, policy.frameAncestors(c.Request.URL.Path))
+
+	// 防止 MIME 类型嗅探
+	c.Header("X-Content-Type-Options", "nosniff")
+	// XSS 保护
+	c.Header("X-XSS-Protection", "1; mode=block")
+	// Referrer Policy
+	c.Header("Referrer-Policy", "no-referrer-when-downgrade")
+	c.Next()
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"monitor/internal/config"
+)
+
+func policyTestRequest(server *Server, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+// TestDefaultHTTPPolicy 验证未配置 server 策略时保持原有行为
+func TestDefaultHTTPPolicy(t *testing.T) {
+	server := NewServer(&badgeTestStorage{}, &config.AppConfig{})
+
+	if w := policyTestRequest(server, http.MethodGet, "/api/status", nil); w.Code != http.StatusNotAcceptable {
+		t.Errorf("默认应强制 gzip，状态码 = %d", w.Code)
+	}
+
+	w := policyTestRequest(server, http.MethodGet, "/health", map[string]string{"Origin": "https://relaypulse.top"})
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("HSTS = %q", got)
+	}
+	if got := w.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want SAMEORIGIN", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://relaypulse.top" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+
+	w = policyTestRequest(server, http.MethodGet, "/p/demo", nil)
+	if got := w.Header().Get("X-Frame-Options"); got != "" {
+		t.Errorf("/p/ 路径应允许嵌入，X-Frame-Options = %q", got)
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("/p/ 路径应允许嵌入，Content-Security-Policy = %q", got)
+	}
+}
+
+// TestHTTPPolicyHotReload 验证 UpdateConfig 后中间件策略立即生效
+func TestHTTPPolicyHotReload(t *testing.T) {
+	server := NewServer(&badgeTestStorage{}, &config.AppConfig{})
+
+	disabled := false
+	server.UpdateConfig(&config.AppConfig{
+		Server: config.ServerConfig{
+			CORS: config.CORSConfig{AllowedOrigins: []string{"http://localhost:5173"}},
+			HSTS: config.HSTSConfig{Enabled: &disabled},
+			FrameAncestors: config.FrameAncestorsConfig{
+				Default: "'none'",
+				Paths: map[string]string{
+					"/p/":        "https://partner.example.com",
+					"/p/private": "'self'",
+				},
+			},
+			GzipMode: config.GzipModePreferred,
+		},
+	})
+
+	w := policyTestRequest(server, http.MethodGet, "/api/status", nil)
+	if w.Code == http.StatusNotAcceptable {
+		t.Errorf("gzip_mode=preferred 时不应拒绝未声明 gzip 的请求")
+	}
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("HSTS 已关闭，仍返回 %q", got)
+	}
+
+	tests := []struct {
+		path     string
+		wantCSP  string
+		wantXFO  string
+		describe string
+	}{
+		{"/health", "frame-ancestors 'none'", "DENY", "默认策略"},
+		{"/p/demo", "frame-ancestors https://partner.example.com", "", "前缀覆盖"},
+		{"/p/private/x", "frame-ancestors 'self'", "SAMEORIGIN", "最长前缀优先"},
+	}
+	for _, tt := range tests {
+		w := policyTestRequest(server, http.MethodGet, tt.path, nil)
+		if got := w.Header().Get("Content-Security-Policy"); got != tt.wantCSP {
+			t.Errorf("%s: Content-Security-Policy = %q, want %q", tt.describe, got, tt.wantCSP)
+		}
+		if got := w.Header().Get("X-Frame-Options"); got != tt.wantXFO {
+			t.Errorf("%s: X-Frame-Options = %q, want %q", tt.describe, got, tt.wantXFO)
+		}
+	}
+
+	w = policyTestRequest(server, http.MethodGet, "/health", map[string]string{"Origin": "http://localhost:5173"})
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Errorf("新来源未生效，Access-Control-Allow-Origin = %q", got)
+	}
+	w = policyTestRequest(server, http.MethodGet, "/health", map[string]string{"Origin": "https://relaypulse.top"})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("已移除的来源应被拒绝，状态码 = %d", w.Code)
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/monitor"
+	"monitor/internal/scheduler"
+)
+
+const (
+	// probeTimeout 单次即时探测的最长耗时
+	probeTimeout = 60 * time.Second
+
+	// probeSnippetBytes 即时探测报告中保留的响应体长度上限
+	probeSnippetBytes = 512
+)
+
+// MonitorProber 单个监控项即时探测（由调度器实现）
+// 未能执行探测时返回 nil 结果和原因（scheduler.ErrProbeInFlight 等），结果非 nil 时 error 仅表示保存失败
+type MonitorProber interface {
+	ProbeOnce(ctx context.Context, task config.ServiceConfig, persist bool) (*monitor.ProbeResult, error)
+}
+
+// ProbeReport 即时探测结果（API 与命令行共用）
+type ProbeReport struct {
+	Provider   string `json:"provider"`
+	Service    string `json:"service"`
+	Channel    string `json:"channel"`
+	Status     int    `json:"status"`     // 1=绿, 0=红, 2=黄
+	SubStatus  string `json:"sub_status"` // 黄/红的细分原因
+	Latency    int    `json:"latency"`    // 探测器记录的请求延迟（ms）
+	DurationMs int64  `json:"duration_ms"`
+	Timestamp  int64  `json:"timestamp"`
+	Persisted  bool   `json:"persisted"`
+	SaveError  string `json:"save_error,omitempty"`
+
+	HTTPStatus      int    `json:"http_status"`                // 上游返回的 HTTP 状态码（请求未完成时为 0）
+	ResponseSnippet string `json:"response_snippet,omitempty"` // 响应体开头（最多 512 字节）
+	Error           string `json:"error,omitempty"`            // 探测错误（连接失败、超时等）
+}
+
+// NewProbeReport 根据探测结果构造报告（duration 为包含排队与连接建立的总耗时）
+func NewProbeReport(result *monitor.ProbeResult, duration time.Duration, persisted bool, saveErr error) ProbeReport {
+	report := ProbeReport{
+		Provider:   result.Provider,
+		Service:    result.Service,
+		Channel:    result.Channel,
+		Status:     result.Status,
+		SubStatus:  string(result.SubStatus),
+		Latency:    result.Latency,
+		DurationMs: duration.Milliseconds(),
+		Timestamp:  result.Timestamp,
+		Persisted:  persisted && saveErr == nil,
+
+		HTTPStatus:      result.HttpCode,
+		ResponseSnippet: responseSnippet(result.ResponseBody),
+	}
+	if result.Error != nil {
+		report.Error = result.Error.Error()
+	}
+	if saveErr != nil {
+		report.SaveError = saveErr.Error()
+	}
+	return report
+}
+
+// responseSnippet 截取响应体开头用于排查（按字节截断后去掉不完整的 UTF-8 字符）
+func responseSnippet(body string) string {
+	if len(body) <= probeSnippetBytes {
+		return body
+	}
+	return strings.ToValidUTF8(body[:probeSnippetBytes], "") + "..."
+}
+
+// SetProber 设置即时探测实现（启用 /api/admin/probe）
+func (s *Server) SetProber(p MonitorProber) {
+	s.handler.prober = p
+}
+
+// AdminProbeMonitor 立即探测单个监控项
+// 路由：POST /api/admin/probe/:provider/:service[/:channel]?persist=true
+func (h *Handler) AdminProbeMonitor(c *gin.Context) {
+	if h.prober == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "prober not available"})
+		return
+	}
+
+	key := config.MonitorKey{
+		Provider: c.Param("provider"),
+		Service:  c.Param("service"),
+		Channel:  c.Param("channel"),
+	}
+
+	h.cfgMu.RLock()
+	monitors := h.config.Monitors
+	h.cfgMu.RUnlock()
+
+	task, ok := findMonitorByKey(monitors, key)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "monitor not found: " + key.String()})
+		return
+	}
+
+	persist := c.Query("persist") == "true" || c.Query("persist") == "1"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := h.prober.ProbeOnce(ctx, task, persist)
+	duration := time.Since(start)
+	if result == nil {
+		body := gin.H{"error": fmt.Sprintf("探测未执行: %v", err)}
+		if errors.Is(err, scheduler.ErrStandby) && h.cluster != nil {
+			body["leader"] = h.cluster.Status().Leader // 持久化探测需发往主节点
+		}
+		c.JSON(probeErrorStatus(err), body)
+		return
+	}
+	if err != nil {
+		log.Printf("[Admin] 即时探测结果保存失败 %s: %v", key, err)
+	}
+
+	log.Printf("[Admin] 即时探测 %s status=%d http=%d latency=%dms persist=%v", key, result.Status, result.HttpCode, result.Latency, persist)
+	c.JSON(http.StatusOK, NewProbeReport(result, duration, persist, err))
+}
+
+// probeErrorStatus 将未能执行探测的原因映射为 HTTP 状态码
+func probeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, scheduler.ErrProbeInFlight), errors.Is(err, scheduler.ErrStandby):
+		return http.StatusConflict
+	case errors.Is(err, scheduler.ErrProbeBudgetExhausted):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// findMonitorByKey 在运行时配置中查找监控项（已停用的监控项不在其中）
+func findMonitorByKey(monitors []config.ServiceConfig, key config.MonitorKey) (config.ServiceConfig, bool) {
+	for _, m := range monitors {
+		if config.KeyOf(m) == key {
+			return m, true
+		}
+	}
+	return config.ServiceConfig{}, false
+}
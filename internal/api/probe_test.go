@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/monitor"
+)
+
+// TestNewProbeReport 验证报告包含 HTTP 状态码、错误和截断后的响应体
+func TestNewProbeReport(t *testing.T) {
+	body := strings.Repeat("a", probeSnippetBytes-1) + "中文"
+	result := &monitor.ProbeResult{
+		Provider:     "Demo",
+		Service:      "cc",
+		Status:       0,
+		HttpCode:     502,
+		Error:        errors.New("bad gateway"),
+		ResponseBody: body,
+	}
+
+	report := NewProbeReport(result, time.Second, true, errors.New("db down"))
+	if report.HTTPStatus != 502 || report.Error != "bad gateway" {
+		t.Errorf("HTTPStatus/Error = %d/%q", report.HTTPStatus, report.Error)
+	}
+	if want := strings.Repeat("a", probeSnippetBytes-1) + "..."; report.ResponseSnippet != want {
+		t.Errorf("ResponseSnippet 长度 = %d, want %d", len(report.ResponseSnippet), len(want))
+	}
+	if report.Persisted || report.SaveError != "db down" {
+		t.Errorf("Persisted/SaveError = %v/%q", report.Persisted, report.SaveError)
+	}
+
+	if short := responseSnippet("ok"); short != "ok" {
+		t.Errorf("responseSnippet(ok) = %q", short)
+	}
+}
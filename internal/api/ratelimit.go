@@ -0,0 +1,199 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+	"monitor/internal/metrics"
+)
+
+// rateLimitedTotal 被限流拒绝的请求数（按路由规则统计，不含 IP，避免标签基数膨胀）
+var rateLimitedTotal = metrics.NewCounterVec(
+	"monitor_http_rate_limited_total",
+	"Requests rejected by the per-IP rate limiter.",
+	"route",
+)
+
+// rateLimitIdleTTL 令牌桶空闲超过该时长后回收（此时桶早已补满，回收不影响限流结果）
+const rateLimitIdleTTL = 10 * time.Minute
+
+// rateLimitRule 按路径前缀匹配的限流规则
+type rateLimitRule struct {
+	prefix string // 统计标签；默认规则为 "default"
+	rate   float64
+	burst  float64
+}
+
+// tokenBucket 单个客户端在单条规则下的令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter 按客户端 IP 的令牌桶限流器
+type rateLimiter struct {
+	rules       []rateLimitRule // 按前缀长度降序，最长前缀优先
+	defaultRule *rateLimitRule
+	trusted     []*net.IPNet
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter 根据配置创建限流器，未启用时返回 nil
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	l := &rateLimiter{
+		trusted: cfg.TrustedProxyNets,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+	for prefix, rule := range cfg.Routes {
+		l.rules = append(l.rules, rateLimitRule{prefix: prefix, rate: rule.PerMinute / 60, burst: float64(rule.Burst)})
+	}
+	sort.Slice(l.rules, func(i, j int) bool { return len(l.rules[i].prefix) > len(l.rules[j].prefix) })
+	if cfg.Default.PerMinute > 0 {
+		l.defaultRule = &rateLimitRule{prefix: "default", rate: cfg.Default.PerMinute / 60, burst: float64(cfg.Default.Burst)}
+	}
+	return l
+}
+
+// match 返回路径对应的规则；不限流时返回 nil
+func (l *rateLimiter) match(path string) *rateLimitRule {
+	for i := range l.rules {
+		if strings.HasPrefix(path, l.rules[i].prefix) {
+			if l.rules[i].rate <= 0 {
+				return nil
+			}
+			return &l.rules[i]
+		}
+	}
+	return l.defaultRule
+}
+
+// allow 消耗一个令牌；被拒绝时返回需要等待的时长
+func (l *rateLimiter) allow(rule *rateLimitRule, clientIP string) (bool, time.Duration) {
+	now := l.now()
+	key := rule.prefix + "|" + clientIP
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: rule.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(rule.burst, b.tokens+elapsed*rule.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rule.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep 定期回收空闲令牌桶，防止大量不同 IP 导致内存增长（调用方持有锁）
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > rateLimitIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientIP 解析客户端 IP
+// 仅当连接来自可信代理时才信任代理头：CF-Connecting-IP > X-Forwarded-For（从右向左跳过可信代理）> X-Real-IP
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !l.isTrusted(remote) {
+		return remote
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("CF-Connecting-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if !l.isTrusted(ip) || i == 0 {
+				return ip
+			}
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return remote
+}
+
+func (l *rateLimiter) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range l.trusted {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitMiddleware 限流中间件（每个请求读取当前策略，支持热更新）
+func (s *Server) rateLimitMiddleware(c *gin.Context) {
+	limiter := s.policy.Load().limiter
+	if limiter == nil || c.Request.Method == http.MethodOptions {
+		c.Next()
+		return
+	}
+
+	rule := limiter.match(c.Request.URL.Path)
+	if rule == nil {
+		c.Next()
+		return
+	}
+
+	if ok, wait := limiter.allow(rule, limiter.clientIP(c.Request)); !ok {
+		rateLimitedTotal.Inc(rule.prefix)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many requests, please retry later",
+		})
+		return
+	}
+	c.Next()
+}
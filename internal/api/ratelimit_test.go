@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+func newTestRateLimiter(t *testing.T, cfg config.RateLimitConfig) *rateLimiter {
+	t.Helper()
+	cfg.Enabled = true
+	server := config.ServerConfig{RateLimit: cfg}
+	if err := server.Normalize(); err != nil {
+		t.Fatalf("限流配置无效: %v", err)
+	}
+	return newRateLimiter(server.RateLimit)
+}
+
+// TestRateLimiterTokenBucket 验证突发容量、补充速率与 Retry-After 计算
+func TestRateLimiterTokenBucket(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{
+		Routes: map[string]config.RateLimitRule{
+			"/api/status": {PerMinute: 60, Burst: 2},
+			"/api/public": {PerMinute: 0},
+		},
+	})
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	rule := limiter.match("/api/status?period=24h")
+	if rule == nil {
+		t.Fatal("/api/status 应匹配限流规则")
+	}
+	if limiter.match("/api/public/x") != nil || limiter.match("/health") != nil {
+		t.Fatal("per_minute=0 或未匹配且无默认规则时不应限流")
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow(rule, "1.2.3.4"); !ok {
+			t.Fatalf("第 %d 个请求应在突发容量内", i+1)
+		}
+	}
+	ok, wait := limiter.allow(rule, "1.2.3.4")
+	if ok || wait != time.Second {
+		t.Fatalf("超出突发容量应被拒绝并等待 1s，got ok=%v wait=%v", ok, wait)
+	}
+	if ok, _ := limiter.allow(rule, "5.6.7.8"); !ok {
+		t.Fatal("不同 IP 应独立计数")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.allow(rule, "1.2.3.4"); !ok {
+		t.Fatal("补充 1 个令牌后应放行")
+	}
+}
+
+// TestRateLimiterClientIP 验证仅信任来自可信代理的转发头
+func TestRateLimiterClientIP(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1"}})
+
+	tests := []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"非可信来源忽略转发头", "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, "8.8.8.8"},
+		{"Cloudflare 头优先", "127.0.0.1:1234", map[string]string{"CF-Connecting-IP": "2.2.2.2", "X-Forwarded-For": "3.3.3.3"}, "2.2.2.2"},
+		{"XFF 跳过可信代理", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 4.4.4.4, 10.0.0.2"}, "4.4.4.4"},
+		{"XFF 全部为可信代理", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3"}, "10.0.0.3"},
+		{"X-Real-IP 兜底", "10.0.0.1:1234", map[string]string{"X-Real-IP": "5.5.5.5"}, "5.5.5.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if got := limiter.clientIP(req); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRateLimitMiddleware 验证 429 响应、Retry-After、指标以及热更新后保留计数
+func TestRateLimitMiddleware(t *testing.T) {
+	cfg := &config.AppConfig{
+		Server: config.ServerConfig{
+			GzipMode: config.GzipModePreferred,
+			RateLimit: config.RateLimitConfig{
+				Enabled: true,
+				Routes:  map[string]config.RateLimitRule{"/health": {PerMinute: 1, Burst: 1}},
+			},
+		},
+	}
+	server := NewServer(&badgeTestStorage{}, cfg)
+	before := rateLimitedTotal.Value("/health")
+
+	if w := policyTestRequest(server, http.MethodGet, "/health", nil); w.Code != http.StatusOK {
+		t.Fatalf("首个请求状态码 = %d, want 200", w.Code)
+	}
+
+	// 配置未变化的热更新不应清空令牌桶
+	server.UpdateConfig(cfg)
+
+	w := policyTestRequest(server, http.MethodGet, "/health", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超限请求状态码 = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	if got := rateLimitedTotal.Value("/health") - before; got != 1 {
+		t.Errorf("限流指标增加 %v, want 1", got)
+	}
+
+	w = policyTestRequest(server, http.MethodGet, "/metrics", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `monitor_http_rate_limited_total{route="/health"}`) {
+		t.Errorf("/metrics 未输出限流指标: %d %s", w.Code, w.Body.String())
+	}
+}
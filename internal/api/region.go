@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// 多地域汇总状态（仅统计最新结果未过期的地域）
+const (
+	RegionStateOK          = "ok"           // 没有地域不可用
+	RegionStatePartialDown = "partial_down" // 部分地域不可用
+	RegionStateAllDown     = "all_down"     // 所有地域均不可用
+)
+
+// RegionStatus 单个地域的最新状态
+type RegionStatus struct {
+	Region  string         `json:"region"`
+	Current *CurrentStatus `json:"current_status"`
+	Stale   bool           `json:"stale"` // 最新结果已过期（节点离线或停止上报），不参与汇总
+}
+
+// regionView /api/status 的地域视图
+type regionView struct {
+	region      string        // 查询的地域，空表示汇总所有地域
+	localRegion string        // 本机探测结果（region 为空的记录）的地域名
+	freshness   time.Duration // 地域最新结果的有效期，超过视为过期
+}
+
+// resolveRegionView 解析 region 查询参数，只接受本机地域和已配置的探测节点地域
+func resolveRegionView(cfg *config.AppConfig, region string) (regionView, error) {
+	view := regionView{
+		localRegion: cfg.Ingest.LocalRegion,
+		freshness:   3 * cfg.IntervalDuration,
+	}
+	if view.localRegion == "" {
+		view.localRegion = config.DefaultLocalRegion
+	}
+	if region == "" || region == "all" {
+		return view, nil
+	}
+	if !slices.Contains(knownRegions(cfg), region) {
+		return view, fmt.Errorf("未知的地域: %s", region)
+	}
+	view.region = region
+	return view, nil
+}
+
+// knownRegions 返回本机地域和已配置的探测节点地域（去重，本机地域在前）
+func knownRegions(cfg *config.AppConfig) []string {
+	local := cfg.Ingest.LocalRegion
+	if local == "" {
+		local = config.DefaultLocalRegion
+	}
+	regions := []string{local}
+	for _, a := range cfg.Ingest.Agents {
+		if !slices.Contains(regions, a.Region) {
+			regions = append(regions, a.Region)
+		}
+	}
+	return regions
+}
+
+// regionOf 返回记录所属地域名
+func (v regionView) regionOf(r *storage.ProbeRecord) string {
+	if r.Region == "" {
+		return v.localRegion
+	}
+	return r.Region
+}
+
+// filter 按查询地域过滤历史记录，返回过滤后的记录和该地域的最新记录
+// 汇总视图原样返回
+func (v regionView) filter(latest *storage.ProbeRecord, history []*storage.ProbeRecord) (*storage.ProbeRecord, []*storage.ProbeRecord) {
+	if v.region == "" {
+		return latest, history
+	}
+
+	filtered := make([]*storage.ProbeRecord, 0, len(history))
+	for _, r := range history {
+		if v.regionOf(r) == v.region {
+			filtered = append(filtered, r)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, filtered
+	}
+	return filtered[len(filtered)-1], filtered
+}
+
+// localRecords 只保留本机探测的记录（region 为空）
+// 徽章和 Feed 只展示本机地域：混合多个地域的记录会让最新状态随上报顺序跳动，并在地域结果不一致时产生虚假的故障/恢复事件
+func localRecords(records []*storage.ProbeRecord) []*storage.ProbeRecord {
+	local := records[:0:0]
+	for _, r := range records {
+		if r.Region == "" {
+			local = append(local, r)
+		}
+	}
+	return local
+}
+
+// summarize 汇总各地域的最新状态（仅汇总视图，且有远程探测节点上报时返回）
+func (v regionView) summarize(history []*storage.ProbeRecord, now time.Time) ([]RegionStatus, string) {
+	if v.region != "" {
+		return nil, ""
+	}
+
+	// history 按时间升序，后出现的记录覆盖先出现的
+	latestByRegion := make(map[string]*storage.ProbeRecord)
+	remote := false
+	for _, r := range history {
+		latestByRegion[v.regionOf(r)] = r
+		if r.Region != "" {
+			remote = true
+		}
+	}
+	if !remote {
+		return nil, ""
+	}
+
+	names := make([]string, 0, len(latestByRegion))
+	for name := range latestByRegion {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		switch {
+		case a == v.localRegion:
+			return -1
+		case b == v.localRegion:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+
+	regions := make([]RegionStatus, 0, len(names))
+	fresh, down := 0, 0
+	for _, name := range names {
+		r := latestByRegion[name]
+		stale := v.freshness > 0 && now.Sub(time.Unix(r.Timestamp, 0)) > v.freshness
+		regions = append(regions, RegionStatus{
+			Region:  name,
+			Current: &CurrentStatus{Status: r.Status, Latency: r.Latency, Timestamp: r.Timestamp},
+			Stale:   stale,
+		})
+		if stale {
+			continue
+		}
+		fresh++
+		if r.Status == 0 {
+			down++
+		}
+	}
+
+	switch {
+	case fresh == 0:
+		return regions, ""
+	case down == fresh:
+		return regions, RegionStateAllDown
+	case down > 0:
+		return regions, RegionStatePartialDown
+	default:
+		return regions, RegionStateOK
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// TestRegionView 验证地域过滤与多地域汇总状态
+func TestRegionView(t *testing.T) {
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Ingest: config.IngestConfig{
+			Agents: []config.IngestAgent{{ID: "sg-1", Region: "sg"}, {ID: "us-1", Region: "us"}},
+		},
+	}
+	now := time.Unix(1735689600, 0)
+	rec := func(region string, status int, ago time.Duration) *storage.ProbeRecord {
+		return &storage.ProbeRecord{Region: region, Status: status, Timestamp: now.Add(-ago).Unix()}
+	}
+
+	if _, err := resolveRegionView(cfg, "eu"); err == nil {
+		t.Fatal("未知地域应返回错误")
+	}
+
+	all, err := resolveRegionView(cfg, "all")
+	if err != nil || all.region != "" || all.localRegion != config.DefaultLocalRegion {
+		t.Fatalf("resolveRegionView(all) = %+v, %v", all, err)
+	}
+
+	tests := []struct {
+		name    string
+		history []*storage.ProbeRecord
+		want    string
+		regions int
+	}{
+		{"仅本机探测", []*storage.ProbeRecord{rec("", 0, time.Minute)}, "", 0},
+		{"全部正常", []*storage.ProbeRecord{rec("", 1, time.Minute), rec("sg", 1, 30*time.Second)}, RegionStateOK, 2},
+		{"部分不可用", []*storage.ProbeRecord{rec("", 1, time.Minute), rec("sg", 0, 30*time.Second), rec("us", 2, 10*time.Second)}, RegionStatePartialDown, 3},
+		{"全部不可用", []*storage.ProbeRecord{rec("sg", 1, 2*time.Minute), rec("", 0, time.Minute), rec("sg", 0, 30*time.Second)}, RegionStateAllDown, 2},
+		{"过期地域不参与汇总", []*storage.ProbeRecord{rec("us", 1, time.Hour), rec("", 0, time.Minute)}, RegionStateAllDown, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions, state := all.summarize(tt.history, now)
+			if state != tt.want || len(regions) != tt.regions {
+				t.Fatalf("summarize = %+v, %q; want %d regions, %q", regions, state, tt.regions, tt.want)
+			}
+			if len(regions) > 0 && regions[0].Region != config.DefaultLocalRegion {
+				t.Errorf("本机地域应排在最前: %+v", regions)
+			}
+		})
+	}
+
+	sg, err := resolveRegionView(cfg, "sg")
+	if err != nil {
+		t.Fatalf("resolveRegionView(sg) error = %v", err)
+	}
+	history := []*storage.ProbeRecord{rec("sg", 1, 2*time.Minute), rec("", 0, time.Minute), rec("sg", 2, 30*time.Second), rec("us", 0, 10*time.Second)}
+	latest, filtered := sg.filter(history[3], history)
+	if len(filtered) != 2 || latest != history[2] {
+		t.Fatalf("filter(sg) = %+v, %d 条", latest, len(filtered))
+	}
+	if regions, state := sg.summarize(history, now); regions != nil || state != "" {
+		t.Errorf("单地域视图不应返回汇总: %+v %q", regions, state)
+	}
+}
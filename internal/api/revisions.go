@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/storage"
+)
+
+const (
+	defaultRevisionLimit = 20  // 修订列表默认返回条数
+	maxRevisionLimit     = 200 // 修订列表单次最多返回条数
+)
+
+// AdminRevision 配置修订记录（列表不含 diff，详情返回完整的结构化差异）
+type AdminRevision struct {
+	ID        int64           `json:"id"`
+	Version   string          `json:"version"`
+	NodeID    string          `json:"node_id,omitempty"`
+	Source    string          `json:"source"`
+	Summary   string          `json:"summary"`
+	CreatedAt time.Time       `json:"created_at"`
+	Diff      json.RawMessage `json:"diff,omitempty"`
+}
+
+func newAdminRevision(rev storage.ConfigRevision, withDiff bool) AdminRevision {
+	r := AdminRevision{
+		ID:        rev.ID,
+		Version:   rev.Version,
+		NodeID:    rev.NodeID,
+		Source:    rev.Source,
+		Summary:   rev.Summary,
+		CreatedAt: rev.CreatedAt,
+	}
+	if withDiff && rev.Diff != "" {
+		r.Diff = json.RawMessage(rev.Diff)
+	}
+	return r
+}
+
+// revisionStore 返回支持配置修订历史的存储，不支持时写入 501 响应并返回 nil
+func (h *Handler) revisionStore(c *gin.Context) storage.RevisionStore {
+	store, ok := h.storage.WithContext(c.Request.Context()).(storage.RevisionStore)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "config revisions are not supported by the storage backend"})
+		return nil
+	}
+	return store
+}
+
+// AdminListRevisions 按时间倒序列出配置修订记录（?limit=，默认 20，最多 200）
+func (h *Handler) AdminListRevisions(c *gin.Context) {
+	limit := defaultRevisionLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit: " + raw})
+			return
+		}
+		limit = min(n, maxRevisionLimit)
+	}
+
+	store := h.revisionStore(c)
+	if store == nil {
+		return
+	}
+	revisions, err := store.ListRevisions(limit)
+	if err != nil {
+		log.Printf("[Admin] 查询配置修订失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]AdminRevision, 0, len(revisions))
+	for _, rev := range revisions {
+		result = append(result, newAdminRevision(rev, false))
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": result})
+}
+
+// AdminGetRevision 返回单条配置修订记录及其结构化差异
+func (h *Handler) AdminGetRevision(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision id: " + c.Param("id")})
+		return
+	}
+
+	store := h.revisionStore(c)
+	if store == nil {
+		return
+	}
+	rev, err := store.GetRevision(id)
+	if err != nil {
+		log.Printf("[Admin] 查询配置修订失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rev == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revision": newAdminRevision(*rev, true)})
+}
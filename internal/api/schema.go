@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"monitor/internal/config"
+)
+
+// GetConfigSchema 返回 config.yaml 的 JSON Schema（由配置结构体生成，随版本变化，不含任何配置值）
+// 可在编辑器中引用：# yaml-language-server: $schema=https://<host>/api/config/schema
+func (h *Handler) GetConfigSchema(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, config.JSONSchema())
+}
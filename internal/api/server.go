@@ -2,23 +2,26 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"embed"
 	"fmt"
 	"io/fs"
 	"log"
 	"mime"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 
 	"monitor/internal/buildinfo"
 	"monitor/internal/config"
+	"monitor/internal/metrics"
 	"monitor/internal/storage"
 )
 
@@ -30,48 +33,32 @@ type Server struct {
 	handler    *Handler
 	router     *gin.Engine
 	httpServer *http.Server
-	port       string
+	cancel     context.CancelFunc // 停止证书监听
+
+	policy atomic.Pointer[httpPolicy] // CORS / gzip / 安全头策略（可热更新）
 }
 
-// NewServer 创建服务器
-func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Server {
+// NewServer 创建服务器（监听地址、TLS、超时取自 cfg.Server）
+func NewServer(store storage.Storage, cfg *config.AppConfig) *Server {
 	// 设置gin模式
 	gin.SetMode(gin.ReleaseMode)
 
 	// 创建路由
 	router := gin.Default()
 
-	// CORS中间件 - 从环境变量获取允许的来源
-	allowedOrigins := []string{"https://relaypulse.top"}
-	if extraOrigins := os.Getenv("MONITOR_CORS_ORIGINS"); extraOrigins != "" {
-		// 支持逗号分隔的多个域名，例如: MONITOR_CORS_ORIGINS=http://localhost:5173,http://localhost:3000
-		allowedOrigins = append(allowedOrigins, strings.Split(extraOrigins, ",")...)
-	}
+	server := &Server{router: router}
+	server.policy.Store(newHTTPPolicy(cfg.Server, nil))
 
-	corsConfig := cors.Config{
-		AllowOrigins:     allowedOrigins,
-		AllowMethods:     []string{"GET", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: false,
-		MaxAge:           12 * time.Hour,
-	}
-	router.Use(cors.New(corsConfig))
+	// CORS / 限流 / 强制 gzip / 安全头策略来自 cfg.Server，热更新时整体替换
+	router.Use(server.corsMiddleware)
+	router.Use(server.rateLimitMiddleware)
+	router.Use(server.gzipRequirementMiddleware)
 
-	// 强制 gzip 中间件（仅针对大响应 API，保护 4Mb 带宽）
-	// /api/status 响应约 300KB，未压缩会瞬间打满带宽
+	// 导出接口是长时间的流式响应，单独放宽写超时（server.write_timeout 对其不适用）
+	// 必须在 gzip 中间件之前执行：gzip 包装后的 ResponseWriter 无法再获取底层连接
 	router.Use(func(c *gin.Context) {
-		path := c.Request.URL.Path
-
-		// 仅对 /api/status 强制要求 gzip
-		if strings.HasPrefix(path, "/api/status") {
-			acceptEncoding := c.GetHeader("Accept-Encoding")
-			if !strings.Contains(acceptEncoding, "gzip") {
-				c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{
-					"error": "This endpoint requires gzip support. Add header: Accept-Encoding: gzip",
-				})
-				return
-			}
+		if c.Request.URL.Path == "/api/export" {
+			_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(exportTimeout))
 		}
 		c.Next()
 	})
@@ -80,25 +67,7 @@ func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Serve
 	router.Use(gzip.Gzip(gzip.DefaultCompression))
 
 	// 安全头中间件
-	router.Use(func(c *gin.Context) {
-		path := c.Request.URL.Path
-
-		// HSTS（强制 HTTPS，有效期 1 年）- Cloudflare 提供 HTTPS
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
-
-		// 防止点击劫持 - 对 /p/* 路径允许任意嵌入（iframe 友好）
-		if !strings.HasPrefix(path, "/p/") {
-			c.Header("X-Frame-Options", "SAMEORIGIN")
-		}
-
-		// 防止 MIME 类型嗅探
-		c.Header("X-Content-Type-Options", "nosniff")
-		// XSS 保护
-		c.Header("X-XSS-Protection", "1; mode=block")
-		// Referrer Policy
-		c.Header("Referrer-Policy", "no-referrer-when-downgrade")
-		c.Next()
-	})
+	router.Use(server.securityHeadersMiddleware)
 
 	// 创建处理器
 	handler := NewHandler(store, cfg)
@@ -106,10 +75,46 @@ func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Serve
 	// 注册 API 路由
 	router.GET("/api/status", handler.GetStatus)
 
+	// 状态徽章（SVG，用于 README 嵌入）
+	router.GET("/api/badge/:provider_slug/:service", handler.GetBadge)
+	router.GET("/api/badge/:provider_slug/:service/:channel", handler.GetBadge)
+
+	// 配置文件 JSON Schema（编辑器补全与 CI 校验）
+	router.GET("/api/config/schema", handler.GetConfigSchema)
+
+	// 原始探测记录导出（CSV / NDJSON / JSON，流式输出；查询开销大，与管理 API 使用相同的令牌鉴权）
+	router.GET("/api/export", handler.adminAuth, handler.GetExport)
+
+	// 远程探测节点结果上报（需配置 ingest.agents，请求使用 HMAC 签名）
+	router.POST("/api/ingest", handler.PostIngest)
+
+	// 管理 API（需配置 admin.token，请求头 Authorization: Bearer <token>）
+	admin := router.Group("/api/admin", handler.adminAuth)
+	admin.GET("/monitors", handler.AdminListMonitors)
+	admin.POST("/monitors", handler.AdminCreateMonitor)
+	admin.PUT("/monitors", handler.AdminUpdateMonitor)
+	admin.DELETE("/monitors", handler.AdminDeleteMonitor)
+	admin.POST("/monitors/disable", handler.AdminDisableMonitor)
+	admin.POST("/monitors/enable", handler.AdminEnableMonitor)
+	admin.POST("/probe/:provider/:service", handler.AdminProbeMonitor)
+	admin.POST("/probe/:provider/:service/:channel", handler.AdminProbeMonitor)
+	admin.GET("/config/revisions", handler.AdminListRevisions)
+	admin.GET("/config/revisions/:id", handler.AdminGetRevision)
+
 	// SEO 路由
 	router.GET("/sitemap.xml", handler.GetSitemap)
 	router.GET("/robots.txt", handler.GetRobots)
 
+	// 状态变更 Atom Feed（每种语言一个前缀）
+	for _, lang := range supportedLanguages {
+		prefix := ""
+		if lang.PathPrefix != "" {
+			prefix = "/" + lang.PathPrefix
+		}
+		router.GET(prefix+"/feed.xml", handler.GetFeed)
+		router.GET(prefix+"/p/:slug/feed.xml", handler.GetFeed)
+	}
+
 	// 版本信息 API
 	router.GET("/api/version", func(c *gin.Context) {
 		c.Header("Cache-Control", "no-store")
@@ -122,48 +127,139 @@ func NewServer(store storage.Storage, cfg *config.AppConfig, port string) *Serve
 	})
 
 	// 健康检查（支持 GET 和 HEAD）
-	healthHandler := func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	}
-	router.GET("/health", healthHandler)
-	router.HEAD("/health", healthHandler)
+	// /health 为存活检查（verbose=1 时返回详细检查结果），/ready 为就绪检查（失败返回 503）
+	router.GET("/health", handler.GetHealth)
+	router.HEAD("/health", handler.GetHealth)
+	router.GET("/ready", handler.GetReady)
+	router.HEAD("/ready", handler.GetReady)
+
+	// Prometheus 指标
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// 静态文件服务（前端）- 传递 handler 以支持动态 Meta 注入
 	setupStaticFiles(router, handler)
 
-	return &Server{
-		handler: handler,
-		router:  router,
-		port:    port,
-	}
+	server.handler = handler
+	return server
 }
 
-// Start 启动服务器
+// Start 启动服务器（阻塞，直到任一监听器出错或服务器关闭）
+// 可同时监听 TCP（可选 TLS）和 Unix socket
 func (s *Server) Start() error {
+	s.handler.cfgMu.RLock()
+	serverCfg := s.handler.config.Server
+	s.handler.cfgMu.RUnlock()
+
 	s.httpServer = &http.Server{
-		Addr:         ":" + s.port,
 		Handler:      s.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  serverCfg.ReadTimeoutDuration,
+		WriteTimeout: serverCfg.WriteTimeoutDuration,
+		IdleTimeout:  serverCfg.IdleTimeoutDuration,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	var listeners []net.Listener
+	closeAll := func() {
+		cancel()
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}
+
+	if serverCfg.Listen != "" {
+		ln, err := net.Listen("tcp", serverCfg.Listen)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("监听 %s 失败: %w", serverCfg.Listen, err)
+		}
+
+		scheme := "http"
+		if serverCfg.TLS.Enabled() {
+			reloader, err := newCertReloader(serverCfg.TLS.CertFile, serverCfg.TLS.KeyFile)
+			if err != nil {
+				ln.Close()
+				closeAll()
+				return err
+			}
+			if err := reloader.watch(ctx); err != nil {
+				log.Printf("[API] ⚠️ 证书自动重载不可用: %v", err)
+			}
+			ln = tls.NewListener(ln, &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: reloader.GetCertificate,
+				NextProtos:     []string{"h2", "http/1.1"},
+			})
+			scheme = "https"
+		}
+		listeners = append(listeners, ln)
+
+		host := serverCfg.Listen
+		if strings.HasPrefix(host, ":") {
+			host = "localhost" + host
+		}
+		log.Printf("\n🚀 监控服务已启动 (监听 %s)", serverCfg.Listen)
+		log.Printf("👉 Web 界面: %s://%s", scheme, host)
+		log.Printf("👉 API 地址: %s://%s/api/status", scheme, host)
+		log.Printf("👉 健康检查: %s://%s/health\n", scheme, host)
+	}
+
+	if serverCfg.UnixSocket != "" {
+		ln, err := listenUnixSocket(serverCfg.UnixSocket, serverCfg.UnixSocketFileMode)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, ln)
+		log.Printf("👉 Unix socket: %s", serverCfg.UnixSocket)
 	}
 
-	log.Printf("\n🚀 监控服务已启动")
-	log.Printf("👉 Web 界面: http://localhost:%s", s.port)
-	log.Printf("👉 API 地址: http://localhost:%s/api/status", s.port)
-	log.Printf("👉 健康检查: http://localhost:%s/health\n", s.port)
+	errCh := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		go func(ln net.Listener) {
+			errCh <- s.httpServer.Serve(ln)
+		}(ln)
+	}
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	// 任一监听器退出即返回（Shutdown 时所有监听器返回 ErrServerClosed）
+	if err := <-errCh; err != nil && err != http.ErrServerClosed {
+		closeAll()
 		return fmt.Errorf("启动HTTP服务失败: %w", err)
 	}
 
 	return nil
 }
 
+// listenUnixSocket 监听 Unix domain socket（清理上次异常退出残留的 socket 文件）
+func listenUnixSocket(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket 路径 %s 已存在且不是 socket 文件", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("清理残留 unix socket 失败: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("监听 unix socket %s 失败: %w", path, err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("设置 unix socket 权限失败: %w", err)
+	}
+	return ln, nil
+}
+
 // Stop 停止服务器
 func (s *Server) Stop(ctx context.Context) error {
 	log.Println("[API] 正在关闭HTTP服务器...")
 
+	if s.cancel != nil {
+		s.cancel()
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
@@ -174,6 +270,7 @@ func (s *Server) Stop(ctx context.Context) error {
 // UpdateConfig 更新配置（热更新时调用）
 func (s *Server) UpdateConfig(cfg *config.AppConfig) {
 	s.handler.UpdateConfig(cfg)
+	s.policy.Store(newHTTPPolicy(cfg.Server, s.policy.Load()))
 }
 
 // setupStaticFiles 设置静态文件服务（前端）
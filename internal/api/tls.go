@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// certReloader 持有当前 TLS 证书，证书文件变更后自动重新加载
+// 监听证书所在目录而非文件本身，兼容 certbot / K8s Secret 通过符号链接替换文件的方式
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// newCertReloader 加载证书（首次加载失败直接返回错误）
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload 重新读取证书和私钥，失败时保留旧证书
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载 TLS 证书失败: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate 供 tls.Config 使用
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// watch 监听证书目录变更并重新加载，直到 ctx 取消
+func (r *certReloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dirs := map[string]struct{}{
+		filepath.Dir(r.certFile): {},
+		filepath.Dir(r.keyFile):  {},
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("监听证书目录 %s 失败: %w", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var debounceTimer *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+					continue
+				}
+
+				// 防抖：证书和私钥通常先后写入
+				if debounceTimer != nil {
+					debounceTimer.Stop()
+				}
+				debounceTimer = time.AfterFunc(500*time.Millisecond, func() {
+					if err := r.reload(); err != nil {
+						log.Printf("[API] TLS 证书重新加载失败，继续使用旧证书: %v", err)
+						return
+					}
+					log.Printf("[API] TLS 证书已重新加载: %s", r.certFile)
+				})
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("[API] 证书监听错误: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
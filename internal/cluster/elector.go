@@ -0,0 +1,212 @@
+// Package cluster 多副本部署协调（基于共享数据库的租约选主）
+package cluster
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// LeaderLeaseName 调度器主节点租约名称
+const LeaderLeaseName = "scheduler-leader"
+
+// Status 集群状态（用于健康检查展示）
+type Status struct {
+	Mode      string `json:"mode"` // leader 或 shard
+	NodeID    string `json:"node_id"`
+	LastError string `json:"last_error,omitempty"` // 最近一次续约/心跳/查询失败原因
+
+	// 主备模式
+	IsLeader       bool      `json:"is_leader"`
+	Leader         string    `json:"leader,omitempty"`           // 当前主节点（未知时为空）
+	LeaseExpiresAt time.Time `json:"lease_expires_at,omitempty"` // 当前租约到期时间（数据库时间）
+
+	// 分片模式
+	Members []string `json:"members,omitempty"` // 当前存活成员（按 node_id 排序）
+}
+
+// Elector 基于租约的主节点选举
+// 每 ttl/3 尝试获取或续约一次；续约失败且本地计时超过 ttl 后主动降级，
+// 保证旧主节点停止探测的时间不晚于其他副本可以接管的时间；
+// 每次数据库调用限时 ttl/4，续约超时视为无法确认租约，立即降级（数据库卡住时不会一直以主节点身份探测）
+type Elector struct {
+	store  storage.LeaseStore
+	name   string
+	nodeID string
+	ttl    time.Duration
+
+	onChange func(isLeader bool)
+
+	mu          sync.RWMutex
+	isLeader    bool
+	validUntil  time.Time // 本地计算的租约有效期（以发起续约的时间为起点，保守估计）
+	leader      string
+	leaseExpiry time.Time
+	lastErr     error
+
+	now func() time.Time
+}
+
+// NewElector 创建选主器；onChange 在成为主节点或失去主节点身份时调用（在选主协程中同步执行）
+func NewElector(store storage.LeaseStore, nodeID string, ttl time.Duration, onChange func(isLeader bool)) *Elector {
+	return &Elector{
+		store:    store,
+		name:     LeaderLeaseName,
+		nodeID:   nodeID,
+		ttl:      ttl,
+		onChange: onChange,
+		now:      time.Now,
+	}
+}
+
+// Run 运行选主循环，直到 ctx 取消；退出时释放持有的租约，便于其他副本立即接管
+func (e *Elector) Run(ctx context.Context) {
+	log.Printf("[Cluster] 节点 %s 参与选主（租约有效期 %v）", e.nodeID, e.ttl)
+
+	e.tick()
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				callCtx, cancel := e.callContext()
+				err := e.boundStore(callCtx).ReleaseLease(e.name, e.nodeID)
+				cancel()
+				if err != nil {
+					log.Printf("[Cluster] 释放租约失败: %v", err)
+				}
+				e.setLeader(false)
+			}
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+// callContext 返回单次数据库调用的限时 context（ttl/4，小于续约间隔）
+func (e *Elector) callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.ttl/4)
+}
+
+// boundStore 返回绑定 ctx 的租约存储（存储实现支持 WithContext 时），使调用受 ctx 的超时约束
+func (e *Elector) boundStore(ctx context.Context) storage.LeaseStore {
+	if s, ok := e.store.(storage.Storage); ok {
+		if bound, ok := s.WithContext(ctx).(storage.LeaseStore); ok {
+			return bound
+		}
+	}
+	return e.store
+}
+
+// tick 执行一次获取/续约
+func (e *Elector) tick() {
+	start := e.now()
+	callCtx, cancel := e.callContext()
+	acquired, err := e.boundStore(callCtx).AcquireLease(e.name, e.nodeID, e.ttl)
+	timedOut := callCtx.Err() == context.DeadlineExceeded
+	cancel()
+
+	e.mu.Lock()
+	e.lastErr = err
+	if err == nil && acquired {
+		e.validUntil = start.Add(e.ttl)
+	}
+	e.mu.Unlock()
+
+	switch {
+	case err != nil && timedOut:
+		log.Printf("[Cluster] 续约超时（%v）: %v", e.ttl/4, err)
+		if e.IsLeader() {
+			log.Printf("[Cluster] ⚠️ 无法确认租约，降级为备节点")
+			e.setLeader(false)
+		}
+	case err != nil:
+		// 无法确认租约状态：在本地计时到期前保持原身份，到期后降级
+		log.Printf("[Cluster] 续约失败: %v", err)
+		if e.IsLeader() && !e.stillValid() {
+			log.Printf("[Cluster] ⚠️ 租约已过期，降级为备节点")
+			e.setLeader(false)
+		}
+	case acquired:
+		if !e.IsLeader() {
+			log.Printf("[Cluster] ✅ 节点 %s 成为主节点", e.nodeID)
+			e.setLeader(true)
+		}
+	default:
+		if e.IsLeader() {
+			log.Printf("[Cluster] ⚠️ 租约已被其他节点持有，降级为备节点")
+			e.setLeader(false)
+		}
+	}
+
+	e.refreshLeader()
+}
+
+// refreshLeader 查询当前租约持有者（仅用于展示）
+func (e *Elector) refreshLeader() {
+	callCtx, cancel := e.callContext()
+	lease, err := e.boundStore(callCtx).GetLease(e.name)
+	cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		if e.lastErr == nil {
+			e.lastErr = err
+		}
+		return
+	}
+	if lease == nil {
+		e.leader, e.leaseExpiry = "", time.Time{}
+		return
+	}
+	e.leader, e.leaseExpiry = lease.Holder, lease.ExpiresAt
+}
+
+func (e *Elector) stillValid() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.now().Before(e.validUntil)
+}
+
+func (e *Elector) setLeader(isLeader bool) {
+	e.mu.Lock()
+	changed := e.isLeader != isLeader
+	e.isLeader = isLeader
+	e.mu.Unlock()
+
+	if changed && e.onChange != nil {
+		e.onChange(isLeader)
+	}
+}
+
+// IsLeader 当前节点是否为主节点
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Status 返回选主状态
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	st := Status{
+		Mode:           "leader",
+		NodeID:         e.nodeID,
+		IsLeader:       e.isLeader,
+		Leader:         e.leader,
+		LeaseExpiresAt: e.leaseExpiry,
+	}
+	if e.lastErr != nil {
+		st.LastError = e.lastErr.Error()
+	}
+	return st
+}
@@ -0,0 +1,170 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// memLeaseStore 内存租约存储（共享时钟，模拟数据库时间）
+type memLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]*storage.Lease
+	now    *time.Time
+	fail   map[string]bool // 模拟节点与数据库断连
+}
+
+func newMemLeaseStore(now *time.Time) *memLeaseStore {
+	return &memLeaseStore{leases: map[string]*storage.Lease{}, now: now, fail: map[string]bool{}}
+}
+
+func (m *memLeaseStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[holder] {
+		return false, errors.New("connection refused")
+	}
+	lease, ok := m.leases[name]
+	if ok && lease.Holder != holder && m.now.Before(lease.ExpiresAt) {
+		return false, nil
+	}
+	m.leases[name] = &storage.Lease{Name: name, Holder: holder, ExpiresAt: m.now.Add(ttl)}
+	return true, nil
+}
+
+func (m *memLeaseStore) ReleaseLease(name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lease, ok := m.leases[name]; ok && lease.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+func (m *memLeaseStore) GetLease(name string) (*storage.Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lease, ok := m.leases[name]; ok {
+		copied := *lease
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// TestElectorFailover 验证只有一个主节点，且主节点失联后在一个租约有效期内完成切换
+func TestElectorFailover(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := newMemLeaseStore(&now)
+	ttl := 30 * time.Second
+
+	var changes []string
+	newNode := func(id string) *Elector {
+		e := NewElector(store, id, ttl, func(isLeader bool) {
+			if isLeader {
+				changes = append(changes, id+":leader")
+			} else {
+				changes = append(changes, id+":standby")
+			}
+		})
+		e.now = func() time.Time { return now }
+		return e
+	}
+	a, b := newNode("a"), newNode("b")
+
+	a.tick()
+	b.tick()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("应由 a 成为主节点: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if st := b.Status(); st.Leader != "a" || st.IsLeader {
+		t.Errorf("b 看到的主节点 = %+v, want a", st)
+	}
+
+	// a 与数据库断连：本地租约到期前保持主节点，到期后降级
+	store.fail["a"] = true
+	now = now.Add(ttl / 3)
+	a.tick()
+	b.tick()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("租约未过期时不应切换主节点")
+	}
+
+	now = now.Add(ttl)
+	a.tick()
+	if a.IsLeader() {
+		t.Fatal("续约失败且租约过期后 a 应降级")
+	}
+	b.tick()
+	if !b.IsLeader() {
+		t.Fatal("a 的租约过期后 b 应接管")
+	}
+
+	want := []string{"a:leader", "a:standby", "b:leader"}
+	if len(changes) != len(want) {
+		t.Fatalf("状态变化 = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("状态变化 = %v, want %v", changes, want)
+		}
+	}
+
+	// 恢复连接后 a 不能抢占
+	store.fail["a"] = false
+	a.tick()
+	if a.IsLeader() {
+		t.Fatal("b 持有租约期间 a 不应成为主节点")
+	}
+}
+
+// hangingLeaseStore 模拟数据库卡住：hang 为 true 时租约调用阻塞到 context 结束
+// 嵌入 storage.Storage 只为通过 WithContext 绑定 context，其他方法不会被调用
+type hangingLeaseStore struct {
+	storage.Storage
+	*memLeaseStore
+	hang *bool
+	ctx  context.Context
+}
+
+func (h *hangingLeaseStore) WithContext(ctx context.Context) storage.Storage {
+	return &hangingLeaseStore{memLeaseStore: h.memLeaseStore, hang: h.hang, ctx: ctx}
+}
+
+func (h *hangingLeaseStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	if *h.hang {
+		<-h.ctx.Done()
+		return false, h.ctx.Err()
+	}
+	return h.memLeaseStore.AcquireLease(name, holder, ttl)
+}
+
+// TestElectorStepsDownOnTimeout 验证续约调用在 ttl/4 内超时返回，且主节点立即降级
+func TestElectorStepsDownOnTimeout(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	hang := false
+	store := &hangingLeaseStore{memLeaseStore: newMemLeaseStore(&now), hang: &hang, ctx: context.Background()}
+	ttl := 200 * time.Millisecond
+
+	e := NewElector(store, "a", ttl, nil)
+	e.tick()
+	if !e.IsLeader() {
+		t.Fatal("a 应成为主节点")
+	}
+
+	hang = true
+	start := time.Now()
+	e.tick()
+	if elapsed := time.Since(start); elapsed >= ttl/3 {
+		t.Errorf("续约耗时 %v，应在续约间隔 %v 内超时返回", elapsed, ttl/3)
+	}
+	if e.IsLeader() {
+		t.Error("续约超时后应降级为备节点")
+	}
+	if st := e.Status(); st.LastError == "" {
+		t.Error("续约超时应记录在 last_error 中")
+	}
+}
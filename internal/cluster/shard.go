@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// MaintenanceKey 维护任务（如清理旧记录）的分片键，由其所属节点执行
+const MaintenanceKey = "__maintenance__"
+
+// Sharder 分片模式成员管理
+// 各副本定期登记心跳并刷新存活成员列表，监控项按 rendezvous（最高随机权重）哈希分配给成员：
+// 成员变化时只有离开/加入节点相关的监控项会迁移，其余监控项的归属保持不变
+type Sharder struct {
+	store  storage.MemberStore
+	nodeID string
+	ttl    time.Duration
+
+	mu            sync.RWMutex
+	members       []string
+	lastHeartbeat time.Time // 最近一次心跳成功的本地时间
+	lastErr       error
+
+	now func() time.Time
+}
+
+// NewSharder 创建分片成员管理器
+func NewSharder(store storage.MemberStore, nodeID string, ttl time.Duration) *Sharder {
+	return &Sharder{store: store, nodeID: nodeID, ttl: ttl, now: time.Now}
+}
+
+// Run 运行心跳循环，直到 ctx 取消；退出时注销自身，便于其他节点立即接管
+func (s *Sharder) Run(ctx context.Context) {
+	log.Printf("[Cluster] 节点 %s 以分片模式加入集群（心跳有效期 %v）", s.nodeID, s.ttl)
+
+	s.tick()
+	ticker := time.NewTicker(s.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.store.RemoveMember(s.nodeID); err != nil {
+				log.Printf("[Cluster] 注销节点失败: %v", err)
+			}
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+// tick 登记心跳并刷新成员列表
+func (s *Sharder) tick() {
+	start := s.now()
+	err := s.store.Heartbeat(s.nodeID)
+
+	var members []string
+	if err == nil {
+		var list []storage.Member
+		list, err = s.store.ListMembers(s.ttl)
+		for _, m := range list {
+			members = append(members, m.NodeID)
+		}
+		// 刚登记的心跳理应可见，防御性地保证自身在列表中
+		if err == nil && !slices.Contains(members, s.nodeID) {
+			members = append(members, s.nodeID)
+			slices.Sort(members)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastErr = err
+	if err != nil {
+		log.Printf("[Cluster] 心跳失败: %v", err)
+		return
+	}
+
+	s.lastHeartbeat = start
+	if !slices.Equal(s.members, members) {
+		log.Printf("[Cluster] 集群成员变化: [%s] → [%s]", strings.Join(s.members, ", "), strings.Join(members, ", "))
+		s.members = members
+	}
+}
+
+// Owns 判断分片键是否归属当前节点
+// 心跳超过有效期未成功时（其他节点已将本节点视为离线并接管）不再认领任何分片
+func (s *Sharder) Owns(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.members) == 0 || s.now().Sub(s.lastHeartbeat) > s.ttl {
+		return false
+	}
+	return Owner(s.members, key) == s.nodeID
+}
+
+// Status 返回分片状态
+func (s *Sharder) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := Status{
+		Mode:    "shard",
+		NodeID:  s.nodeID,
+		Members: slices.Clone(s.members),
+	}
+	if s.lastErr != nil {
+		st.LastError = s.lastErr.Error()
+	}
+	return st
+}
+
+// Owner 返回分片键在成员中的归属节点（rendezvous 哈希，所有节点基于相同成员列表得出相同结果）
+func Owner(members []string, key string) string {
+	var owner string
+	var best uint64
+	for _, m := range members {
+		h := fnv.New64a()
+		h.Write([]byte(m))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		score := mix64(h.Sum64())
+		if owner == "" || score > best || (score == best && m < owner) {
+			owner, best = m, score
+		}
+	}
+	return owner
+}
+
+// mix64 对 FNV 结果做雪崩处理（murmur3 finalizer），使相近的节点名也能均匀分配
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
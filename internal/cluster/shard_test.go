@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor/internal/storage"
+)
+
+// memMemberStore 内存成员心跳存储（共享时钟，模拟数据库时间）
+type memMemberStore struct {
+	mu        sync.Mutex
+	heartbeat map[string]time.Time
+	now       *time.Time
+	fail      map[string]bool
+}
+
+func newMemMemberStore(now *time.Time) *memMemberStore {
+	return &memMemberStore{heartbeat: map[string]time.Time{}, now: now, fail: map[string]bool{}}
+}
+
+func (m *memMemberStore) Heartbeat(nodeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[nodeID] {
+		return errors.New("connection refused")
+	}
+	m.heartbeat[nodeID] = *m.now
+	return nil
+}
+
+func (m *memMemberStore) ListMembers(ttl time.Duration) ([]storage.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var members []storage.Member
+	for id, at := range m.heartbeat {
+		if m.now.Sub(at) < ttl {
+			members = append(members, storage.Member{NodeID: id, HeartbeatAt: at})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].NodeID < members[j].NodeID })
+	return members, nil
+}
+
+func (m *memMemberStore) RemoveMember(nodeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.heartbeat, nodeID)
+	return nil
+}
+
+func shardKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("provider-%d/cc/vip", i)
+	}
+	return keys
+}
+
+// TestOwnerRebalance 验证成员加入时只迁移到新节点，其余归属不变，且分配大致均匀
+func TestOwnerRebalance(t *testing.T) {
+	keys := shardKeys(300)
+	before := []string{"monitor-0", "monitor-1", "monitor-2"}
+	after := []string{"monitor-0", "monitor-1", "monitor-2", "monitor-3"}
+
+	counts := map[string]int{}
+	for _, key := range keys {
+		oldOwner, newOwner := Owner(before, key), Owner(after, key)
+		if oldOwner != newOwner && newOwner != "monitor-3" {
+			t.Fatalf("%s 从 %s 迁移到 %s，应只迁移到新节点", key, oldOwner, newOwner)
+		}
+		counts[newOwner]++
+	}
+
+	for _, node := range after {
+		if counts[node] < 40 || counts[node] > 110 {
+			t.Errorf("分配不均匀: %v", counts)
+			break
+		}
+	}
+}
+
+// TestSharderOwnership 验证各节点视图一致时每个监控项恰好归属一个节点，节点失联后由其他节点接管
+func TestSharderOwnership(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := newMemMemberStore(&now)
+	ttl := 30 * time.Second
+
+	nodes := make([]*Sharder, 3)
+	for i := range nodes {
+		nodes[i] = NewSharder(store, fmt.Sprintf("monitor-%d", i), ttl)
+		nodes[i].now = func() time.Time { return now }
+	}
+	tickAll := func() {
+		for _, n := range nodes {
+			n.tick()
+		}
+	}
+	assertExactlyOne := func(alive []*Sharder) {
+		t.Helper()
+		for _, key := range shardKeys(50) {
+			owners := 0
+			for _, n := range alive {
+				if n.Owns(key) {
+					owners++
+				}
+			}
+			if owners != 1 {
+				t.Fatalf("%s 被 %d 个节点认领", key, owners)
+			}
+		}
+	}
+
+	tickAll()
+	tickAll() // 第二轮所有节点都能看到完整成员列表
+	if got := nodes[0].Status().Members; len(got) != 3 {
+		t.Fatalf("成员 = %v, want 3 个", got)
+	}
+	assertExactlyOne(nodes)
+
+	// monitor-2 与数据库断连：心跳超过有效期后不再认领任何分片，其余节点接管
+	store.fail["monitor-2"] = true
+	now = now.Add(ttl + time.Second)
+	tickAll()
+	now = now.Add(ttl / 3)
+	tickAll() // 各节点在一个刷新周期内收敛到相同成员列表
+	if nodes[2].Owns(MaintenanceKey) || nodes[2].Owns("provider-1/cc/vip") {
+		t.Fatal("心跳失败超过有效期的节点不应认领分片")
+	}
+	assertExactlyOne(nodes[:2])
+}
@@ -3,11 +3,18 @@ package config
 import (
 	"fmt"
 	"log"
+	"maps"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"monitor/internal/schedule"
 )
 
 // ServiceConfig 单个服务监控配置
@@ -32,6 +39,220 @@ type ServiceConfig struct {
 	SlowLatencyDuration time.Duration `yaml:"-" json:"-"`
 
 	APIKey string `yaml:"api_key" json:"-"` // 不返回给前端
+
+	// Vars 可选：命名占位符，url、headers、body 中的 {{NAME}} 替换为对应的值（{{API_KEY}} 固定使用 api_key）
+	Vars map[string]string `yaml:"vars,omitempty" json:"vars,omitempty"`
+
+	// Labels 可选：自定义标签（如 tier: vip、region: hk），用于 /api/status 过滤、告警路由、消息模板和 Prometheus 指标
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// Disabled 停用的监控项保留在配置文件中，但不参与探测和展示（可通过管理 API 切换）
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+
+	// Confirm 可选：覆盖全局失败确认策略（未配置的字段继承全局 confirm）
+	Confirm *ConfirmConfig `yaml:"confirm,omitempty" json:"confirm,omitempty"`
+
+	// 解析后的失败确认策略（全局配置与监控项覆盖合并后的结果），用于调度器重试判定
+	ConfirmPolicy ConfirmConfig `yaml:"-" json:"-"`
+
+	// Adaptive 可选：覆盖全局自适应探测频率（未配置的字段继承全局 adaptive）
+	Adaptive *AdaptiveConfig `yaml:"adaptive,omitempty" json:"adaptive,omitempty"`
+
+	// 解析后的自适应探测频率（全局配置与监控项覆盖合并后的结果），用于调度器判定探测是否到期
+	AdaptivePolicy AdaptiveConfig `yaml:"-" json:"-"`
+
+	// Schedule 可选：探测日程（cron 表达式、活跃时段），未配置时全天按 interval 探测
+	Schedule *ScheduleConfig `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+
+	// 解析后的探测日程（未配置 schedule 时为 nil），用于调度器判定和时间轴渲染"未排期"时段
+	ScheduleSpec *schedule.Spec `yaml:"-" json:"-"`
+
+	// Extends 可选：继承的模板名（顶层 templates 中定义），加载时与模板深度合并
+	Extends string `yaml:"extends,omitempty" json:"extends,omitempty"`
+
+	// 在配置文件中的位置与继承来源（加载时记录），用于校验错误定位
+	source monitorSource
+}
+
+// where 返回监控项在错误信息中的描述；field 继承自模板时附带模板来源
+func (m *ServiceConfig) where(index int, field string) string {
+	var notes []string
+	if m.source.line > 0 {
+		notes = append(notes, location(m.source.file, m.source.line))
+	}
+	if from, ok := m.source.inherited[field]; ok {
+		notes = append(notes, fmt.Sprintf("%s 继承自 %s", field, from))
+	}
+	if len(notes) == 0 {
+		return fmt.Sprintf("monitor[%d]", index)
+	}
+	return fmt.Sprintf("monitor[%d]（%s）", index, strings.Join(notes, "，"))
+}
+
+// ScheduleConfig 探测日程：按 cron 表达式探测，和/或只在活跃时段内探测
+type ScheduleConfig struct {
+	// 5 段 cron 表达式（分 时 日 月 周），配置后替代 interval 决定探测时刻，例如 "*/5 * * * *"
+	Cron string `yaml:"cron,omitempty" json:"cron,omitempty"`
+
+	// cron 和活跃时段使用的 IANA 时区（默认服务器本地时区），例如 "Asia/Shanghai"
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// 活跃时段，例如 "09:00-18:00"、"09:00-12:00,14:00-18:00"、"22:00-06:00"（跨午夜）
+	ActiveHours string `yaml:"active_hours,omitempty" json:"active_hours,omitempty"`
+
+	// 活跃星期，例如 ["mon-fri"]、["sat", "sun"]
+	ActiveDays []string `yaml:"active_days,omitempty" json:"active_days,omitempty"`
+}
+
+// ConfirmConfig 失败确认策略：探测结果为红色时，先重试确认再记录，避免瞬时网络抖动导致误报
+type ConfirmConfig struct {
+	// 最大重试次数（默认 0，不重试；最多 5 次）
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+
+	// 重试间隔（默认 "3s"）
+	Delay string `yaml:"delay,omitempty" json:"delay,omitempty"`
+
+	// 需要重试的细分状态（默认 network_error、server_error）
+	// 认证失败、参数错误等确定性错误重试无意义，不建议加入
+	SubStatuses []string `yaml:"sub_statuses,omitempty" json:"sub_statuses,omitempty"`
+
+	// 解析后的重试间隔（内部使用）
+	DelayDuration time.Duration `yaml:"-" json:"-"`
+}
+
+// MaxConfirmRetries 失败确认的最大重试次数
+const MaxConfirmRetries = 5
+
+// defaultConfirmSubStatuses 默认重试的细分状态（瞬时故障）
+var defaultConfirmSubStatuses = []string{"network_error", "server_error"}
+
+// confirmableSubStatuses 红色结果可能出现的细分状态（与 storage.SubStatus 保持一致）
+var confirmableSubStatuses = []string{
+	"rate_limit", "server_error", "client_error", "auth_error",
+	"invalid_request", "network_error", "content_mismatch",
+}
+
+// RetryCount 返回最大重试次数（未配置时为 0）
+func (c ConfirmConfig) RetryCount() int {
+	if c.Retries == nil {
+		return 0
+	}
+	return *c.Retries
+}
+
+// ShouldRetry 判断指定细分状态的红色结果是否需要重试确认
+func (c ConfirmConfig) ShouldRetry(subStatus string) bool {
+	return c.RetryCount() > 0 && slices.Contains(c.SubStatuses, subStatus)
+}
+
+// resolve 以 base 为默认值合并覆盖配置，并解析、校验结果
+func (c *ConfirmConfig) resolve(base ConfirmConfig, field string) (ConfirmConfig, error) {
+	merged := base
+	if c != nil {
+		if c.Retries != nil {
+			merged.Retries = c.Retries
+		}
+		if c.Delay != "" {
+			merged.Delay = c.Delay
+		}
+		if c.SubStatuses != nil {
+			merged.SubStatuses = c.SubStatuses
+		}
+	}
+
+	if n := merged.RetryCount(); n < 0 || n > MaxConfirmRetries {
+		return merged, fmt.Errorf("%s.retries 必须在 0 到 %d 之间，当前值: %d", field, MaxConfirmRetries, n)
+	}
+
+	merged.DelayDuration = 3 * time.Second
+	if merged.Delay != "" {
+		d, err := time.ParseDuration(merged.Delay)
+		if err != nil || d < 0 {
+			return merged, fmt.Errorf("%s.delay '%s' 无效", field, merged.Delay)
+		}
+		merged.DelayDuration = d
+	}
+
+	if merged.SubStatuses == nil {
+		merged.SubStatuses = defaultConfirmSubStatuses
+	}
+	for _, ss := range merged.SubStatuses {
+		if !slices.Contains(confirmableSubStatuses, ss) {
+			return merged, fmt.Errorf("%s.sub_statuses 包含未知的细分状态 '%s'，可选值: %s",
+				field, ss, strings.Join(confirmableSubStatuses, ", "))
+		}
+	}
+	return merged, nil
+}
+
+// AdaptiveConfig 自适应探测频率：故障期间加快探测以尽快发现恢复，长期稳定后降低频率以节省额度
+type AdaptiveConfig struct {
+	// 红色或黄色期间的探测间隔（为空或 "0" 表示不加速，不能大于 interval）
+	IncidentInterval string `yaml:"incident_interval,omitempty" json:"incident_interval,omitempty"`
+
+	// 连续绿色达到 backoff_after 后的探测间隔（为空或 "0" 表示不降频，不能小于 interval）
+	BackoffInterval string `yaml:"backoff_interval,omitempty" json:"backoff_interval,omitempty"`
+
+	// 连续绿色多久后降频（默认 "24h"，仅配置 backoff_interval 时生效）
+	BackoffAfter string `yaml:"backoff_after,omitempty" json:"backoff_after,omitempty"`
+
+	// 解析后的时间间隔（内部使用，0 表示未启用）
+	IncidentIntervalDuration time.Duration `yaml:"-" json:"-"`
+	BackoffIntervalDuration  time.Duration `yaml:"-" json:"-"`
+	BackoffAfterDuration     time.Duration `yaml:"-" json:"-"`
+}
+
+// MinAdaptiveInterval 故障期间探测间隔的下限，避免对上游造成压力
+const MinAdaptiveInterval = 5 * time.Second
+
+// resolve 以 base 为默认值合并覆盖配置，并按巡检间隔 interval 解析、校验结果
+func (c *AdaptiveConfig) resolve(base AdaptiveConfig, interval time.Duration, field string) (AdaptiveConfig, error) {
+	merged := base
+	if c != nil {
+		if c.IncidentInterval != "" {
+			merged.IncidentInterval = c.IncidentInterval
+		}
+		if c.BackoffInterval != "" {
+			merged.BackoffInterval = c.BackoffInterval
+		}
+		if c.BackoffAfter != "" {
+			merged.BackoffAfter = c.BackoffAfter
+		}
+	}
+
+	parse := func(value, name string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("%s.%s '%s' 无效", field, name, value)
+		}
+		return d, nil
+	}
+
+	var err error
+	if merged.IncidentIntervalDuration, err = parse(merged.IncidentInterval, "incident_interval"); err != nil {
+		return merged, err
+	}
+	if d := merged.IncidentIntervalDuration; d > 0 && (d < MinAdaptiveInterval || d > interval) {
+		return merged, fmt.Errorf("%s.incident_interval 必须在 %v 到 interval(%v) 之间，当前值: %v", field, MinAdaptiveInterval, interval, d)
+	}
+
+	if merged.BackoffIntervalDuration, err = parse(merged.BackoffInterval, "backoff_interval"); err != nil {
+		return merged, err
+	}
+	if d := merged.BackoffIntervalDuration; d > 0 && d < interval {
+		return merged, fmt.Errorf("%s.backoff_interval 不能小于 interval(%v)，当前值: %v", field, interval, d)
+	}
+
+	if merged.BackoffAfterDuration, err = parse(merged.BackoffAfter, "backoff_after"); err != nil {
+		return merged, err
+	}
+	if merged.BackoffAfter == "" {
+		merged.BackoffAfterDuration = 24 * time.Hour
+	}
+	return merged, nil
 }
 
 // StorageConfig 存储配置
@@ -74,6 +295,22 @@ type NotifierConfig struct {
 
 	// 企业微信配置
 	WeCom WeComConfig `yaml:"wecom" json:"wecom"`
+
+	// Routes 可选：按监控项标签路由告警（按顺序匹配，未匹配任何路由的告警发送到上面的默认渠道）
+	Routes []NotifierRoute `yaml:"routes,omitempty" json:"routes,omitempty"`
+}
+
+// NotifierRoute 告警路由：标签全部匹配的监控项告警发送到单独的企业微信群
+type NotifierRoute struct {
+	Name        string            `yaml:"name" json:"name"`                 // 路由名称（用于日志）
+	MatchLabels map[string]string `yaml:"match_labels" json:"match_labels"` // 需全部匹配的标签（如 tier: vip）
+
+	// Continue 匹配后继续匹配后续路由（默认在第一个匹配的路由处停止）；
+	// 所有匹配的路由都设置了 continue 时，告警也会发送到默认渠道
+	Continue bool `yaml:"continue,omitempty" json:"continue,omitempty"`
+
+	// 企业微信配置（enabled 为 false 时忽略该路由）
+	WeCom WeComConfig `yaml:"wecom" json:"wecom"`
 }
 
 // MessageTemplate 消息模板配置
@@ -103,6 +340,244 @@ type WeComConfig struct {
 	Templates *MessageTemplates `yaml:"templates,omitempty" json:"templates,omitempty"`
 }
 
+// normalize 填充超时、重试次数和消息模板的默认值，field 为错误信息中的字段前缀
+func (w *WeComConfig) normalize(field string) error {
+	var errs ValidationErrors
+
+	w.TimeoutDuration = 5 * time.Second // 默认 5 秒
+	if w.Timeout != "" {
+		d, err := time.ParseDuration(w.Timeout)
+		switch {
+		case err != nil:
+			errs.add(fmt.Errorf("解析 %s.timeout 失败: %w", field, err))
+		case d <= 0:
+			errs.add(fmt.Errorf("%s.timeout 必须大于 0", field))
+		default:
+			w.TimeoutDuration = d
+		}
+	}
+
+	if w.RetryCount == 0 {
+		w.RetryCount = 2 // 默认重试 2 次
+	}
+	if w.RetryCount < 0 {
+		errs.add(fmt.Errorf("%s.retry_count 不能为负数，当前值: %d", field, w.RetryCount))
+	}
+
+	// 消息模板默认值
+	if w.Templates == nil {
+		w.Templates = GetDefaultMessageTemplates()
+	}
+	return errs.err()
+}
+
+// ServerConfig HTTP 服务配置
+// 监听地址、TLS、超时修改后需重启服务；cors/hsts/frame_ancestors/gzip_mode/rate_limit 支持热更新
+type ServerConfig struct {
+	// TCP 监听地址（host:port，如 ":8081"、"127.0.0.1:8081"）
+	// 未配置且未配置 unix_socket 时默认 ":8081"；仅配置 unix_socket 时不监听 TCP
+	Listen string `yaml:"listen" json:"listen"`
+
+	// Unix domain socket 路径（可选，可与 TCP 同时监听）
+	UnixSocket string `yaml:"unix_socket" json:"unix_socket"`
+
+	// Unix socket 文件权限（八进制字符串，默认 "0660"）
+	UnixSocketMode string `yaml:"unix_socket_mode" json:"unix_socket_mode"`
+
+	// TLS 配置（可选，仅作用于 TCP 监听；证书文件变更后自动重新加载）
+	TLS TLSConfig `yaml:"tls" json:"tls"`
+
+	// 超时配置（Go duration 格式，默认 read/write 15s、idle 60s）
+	ReadTimeout  string `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout string `yaml:"write_timeout" json:"write_timeout"`
+	IdleTimeout  string `yaml:"idle_timeout" json:"idle_timeout"`
+
+	// 以下中间件策略支持热更新
+
+	// 跨域配置
+	CORS CORSConfig `yaml:"cors" json:"cors"`
+
+	// HSTS 响应头（仅在 HTTPS 部署时有意义，纯 HTTP 内网可关闭）
+	HSTS HSTSConfig `yaml:"hsts" json:"hsts"`
+
+	// 页面嵌入策略（Content-Security-Policy frame-ancestors / X-Frame-Options）
+	FrameAncestors FrameAncestorsConfig `yaml:"frame_ancestors" json:"frame_ancestors"`
+
+	// gzip 策略（默认 "required"）
+	// - required: /api/status 等大响应接口拒绝不支持 gzip 的客户端（406），保护带宽
+	// - preferred: 客户端支持时压缩，不支持时返回未压缩内容
+	GzipMode string `yaml:"gzip_mode" json:"gzip_mode"`
+
+	// 按客户端 IP 限流（默认关闭）
+	RateLimit RateLimitConfig `yaml:"rate_limit" json:"rate_limit"`
+
+	// 解析后的值（内部使用）
+	UnixSocketFileMode   os.FileMode   `yaml:"-" json:"-"`
+	ReadTimeoutDuration  time.Duration `yaml:"-" json:"-"`
+	WriteTimeoutDuration time.Duration `yaml:"-" json:"-"`
+	IdleTimeoutDuration  time.Duration `yaml:"-" json:"-"`
+}
+
+// gzip 策略取值
+const (
+	GzipModeRequired  = "required"
+	GzipModePreferred = "preferred"
+)
+
+// defaultCORSOrigins 默认允许的跨域来源
+var defaultCORSOrigins = []string{"https://relaypulse.top"}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	// 允许的来源（默认 https://relaypulse.top，"*" 表示允许所有来源）
+	// 环境变量 MONITOR_CORS_ORIGINS（逗号分隔）会追加到该列表
+	AllowedOrigins []string `yaml:"allowed_origins" json:"allowed_origins"`
+
+	// 允许的方法（默认 GET、OPTIONS）
+	AllowedMethods []string `yaml:"allowed_methods" json:"allowed_methods"`
+}
+
+// HSTSConfig HSTS 配置
+type HSTSConfig struct {
+	Enabled           *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`                       // 默认 true
+	MaxAge            int   `yaml:"max_age" json:"max_age"`                                           // 秒，默认 31536000（1 年）
+	IncludeSubdomains *bool `yaml:"include_subdomains,omitempty" json:"include_subdomains,omitempty"` // 默认 true
+}
+
+// FrameAncestorsConfig 页面嵌入策略
+// 取值为 CSP frame-ancestors 源列表，如 "'self'"、"'none'"、"*"、"https://a.com https://b.com"
+type FrameAncestorsConfig struct {
+	// 默认策略（默认 "'self'"，仅允许同源嵌入）
+	Default string `yaml:"default" json:"default"`
+
+	// 按路径前缀覆盖（最长前缀优先），默认 {"/p/": "*"}：服务商页面允许任意嵌入
+	Paths map[string]string `yaml:"paths" json:"paths"`
+}
+
+// RateLimitConfig 按客户端 IP 的令牌桶限流配置
+// 自建部署没有 CDN 时，防止爬虫遍历 period/provider 组合打爆 /api/status
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// 可信代理（IP 或 CIDR）。仅当请求直接来自可信代理时，才读取
+	// CF-Connecting-IP / X-Forwarded-For / X-Real-IP 作为客户端 IP；未配置时只使用连接地址
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies"`
+
+	// 按路径前缀配置的限额（最长前缀优先）
+	// 未配置时默认限制 /api/status、/api/badge/、/api/export
+	Routes map[string]RateLimitRule `yaml:"routes" json:"routes"`
+
+	// 未匹配任何路由前缀的请求使用的限额（默认不限流）
+	Default RateLimitRule `yaml:"default" json:"default"`
+
+	// 解析后的可信代理网段（内部使用）
+	TrustedProxyNets []*net.IPNet `yaml:"-" json:"-"`
+}
+
+// RateLimitRule 单条限流规则（每个客户端 IP 独立计算）
+type RateLimitRule struct {
+	// 每分钟补充的请求数，0 表示不限流
+	PerMinute float64 `yaml:"per_minute" json:"per_minute"`
+
+	// 突发容量（令牌桶大小），默认 per_minute 的 1/6（约 10 秒的配额），至少为 1
+	Burst int `yaml:"burst" json:"burst"`
+}
+
+// defaultRateLimitRoutes 启用限流但未配置 routes 时的默认限额
+var defaultRateLimitRoutes = map[string]RateLimitRule{
+	"/api/status": {PerMinute: 60, Burst: 20},
+	"/api/badge/": {PerMinute: 120, Burst: 60},
+	"/api/export": {PerMinute: 6, Burst: 2},
+}
+
+// TLSConfig TLS 证书配置
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// Enabled 是否启用 TLS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// AdminConfig 管理 API 配置
+type AdminConfig struct {
+	// 访问令牌（请求头 Authorization: Bearer <token>），为空时禁用管理 API
+	// 可通过环境变量 MONITOR_ADMIN_TOKEN 覆盖
+	Token string `yaml:"token" json:"-"`
+}
+
+// 集群模式
+const (
+	ClusterModeSingle = "single" // 单实例（默认），每个副本都运行调度器
+	ClusterModeLeader = "leader" // 主备模式：多副本通过 PostgreSQL 租约选主，仅主节点探测和告警
+	ClusterModeShard  = "shard"  // 分片模式：监控项按一致性哈希分配到各副本，各副本只探测自己的分片
+)
+
+// ClusterConfig 多副本部署配置（修改后需重启）
+type ClusterConfig struct {
+	// 集群模式（默认 "single"）
+	Mode string `yaml:"mode" json:"mode"`
+
+	// 节点标识（默认 主机名-进程号），用于租约持有者和健康检查展示
+	// 可通过环境变量 MONITOR_NODE_ID 覆盖（如 K8s 中注入 Pod 名称）
+	NodeID string `yaml:"node_id" json:"node_id"`
+
+	// 租约/心跳有效期（默认 interval 的一半，最短 5s）
+	// 每 1/3 有效期续约（心跳）一次；节点宕机后，其他副本最迟在一个有效期后接管其工作
+	LeaseTTL string `yaml:"lease_ttl" json:"lease_ttl"`
+
+	// 解析后的租约有效期（内部使用）
+	LeaseTTLDuration time.Duration `yaml:"-" json:"-"`
+}
+
+// DefaultLocalRegion 本机探测结果（region 为空的记录）在多地域展示中的默认地域名
+const DefaultLocalRegion = "local"
+
+// IngestConfig 远程探测节点结果上报配置（中心服务端）
+type IngestConfig struct {
+	// 本机探测结果在多地域展示中的地域名（默认 "local"）
+	LocalRegion string `yaml:"local_region" json:"local_region"`
+
+	// 允许上报结果的探测节点，为空时禁用上报接口
+	Agents []IngestAgent `yaml:"agents" json:"agents"`
+}
+
+// IngestAgent 允许上报结果的探测节点
+type IngestAgent struct {
+	// 节点标识（请求头 X-Agent-ID）
+	ID string `yaml:"id" json:"id"`
+
+	// 节点所属地域，上报结果以此地域入库
+	Region string `yaml:"region" json:"region"`
+
+	// 签名密钥（HMAC-SHA256），与节点 agent.secret 一致
+	// 可通过环境变量 MONITOR_INGEST_<ID>_SECRET 覆盖
+	Secret string `yaml:"secret" json:"-"`
+}
+
+// AgentConfig 探测节点配置（仅 agent 子命令使用）
+type AgentConfig struct {
+	// 中心服务地址，例如 https://relaypulse.top
+	ServerURL string `yaml:"server_url" json:"server_url"`
+
+	// 节点标识，需与中心服务 ingest.agents[].id 一致
+	ID string `yaml:"id" json:"id"`
+
+	// 节点所属地域，需与中心服务 ingest.agents[].region 一致
+	Region string `yaml:"region" json:"region"`
+
+	// 签名密钥，可通过环境变量 MONITOR_AGENT_SECRET 覆盖
+	Secret string `yaml:"secret" json:"-"`
+
+	// 结果上报间隔（默认 "10s"），上报失败的结果保留到下次重试
+	FlushInterval string `yaml:"flush_interval" json:"flush_interval"`
+
+	// 解析后的上报间隔（内部使用）
+	FlushIntervalDuration time.Duration `yaml:"-" json:"-"`
+}
+
 // AppConfig 应用配置
 type AppConfig struct {
 	// 巡检间隔（支持 Go duration 格式，例如 "30s"、"1m", "5m"）
@@ -127,10 +602,29 @@ type AppConfig struct {
 	// - >0: 硬上限，超过时监控项会排队等待执行
 	MaxConcurrency int `yaml:"max_concurrency" json:"max_concurrency"`
 
+	// 同一主机（URL 的 host:port）同时进行的最大探测数（默认 0，不限制）
+	// 多个通道共用同一中转站时，避免同时请求触发对方限流而记录为 rate_limit
+	MaxConcurrencyPerHost int `yaml:"max_concurrency_per_host" json:"max_concurrency_per_host"`
+
+	// 同一服务商同时进行的最大探测数（默认 0，不限制）
+	MaxConcurrencyPerProvider int `yaml:"max_concurrency_per_provider" json:"max_concurrency_per_provider"`
+
+	// 同一主机相邻两次请求的最小间隔（默认不限制），支持 Go duration 格式，例如 "2s"
+	HostMinSpacing string `yaml:"host_min_spacing" json:"host_min_spacing"`
+
+	// 解析后的主机请求间隔（内部使用，不序列化）
+	HostMinSpacingDuration time.Duration `yaml:"-" json:"-"`
+
 	// 是否在单个周期内对探测进行错峰（默认 true）
-	// 开启后会将监控项均匀分散在整个巡检周期内，避免流量突发
+	// 开启后每个监控项按 provider/service/channel 的哈希固定在周期内的某个时刻探测，避免流量突发
 	StaggerProbes *bool `yaml:"stagger_probes,omitempty" json:"stagger_probes,omitempty"`
 
+	// 错峰随机抖动（默认 0，不抖动），每轮在固定时刻上叠加 ±jitter，必须小于 interval
+	StaggerJitter string `yaml:"stagger_jitter" json:"stagger_jitter"`
+
+	// 解析后的错峰抖动（内部使用，不序列化）
+	StaggerJitterDuration time.Duration `yaml:"-" json:"-"`
+
 	// 是否启用并发查询（API 层优化，默认 false）
 	// 开启后 /api/status 接口会使用 goroutine 并发查询多个监控项，显著降低响应时间
 	// 注意：需要确保数据库连接池足够大（建议 max_open_conns >= 50）
@@ -151,109 +645,223 @@ type AppConfig struct {
 	// 通知配置
 	Notifier NotifierConfig `yaml:"notifier" json:"notifier"`
 
+	// HTTP 服务配置
+	Server ServerConfig `yaml:"server" json:"server"`
+
+	// 管理 API 配置
+	Admin AdminConfig `yaml:"admin" json:"admin"`
+
+	// 多副本部署配置
+	Cluster ClusterConfig `yaml:"cluster" json:"cluster"`
+
+	// 失败确认策略（全局默认，监控项可通过 confirm 覆盖）
+	Confirm ConfirmConfig `yaml:"confirm" json:"confirm"`
+
+	// 自适应探测频率（全局默认，监控项可通过 adaptive 覆盖）
+	Adaptive AdaptiveConfig `yaml:"adaptive" json:"adaptive"`
+
+	// 服务商探测预算：provider -> 每小时最多探测次数（含加速探测与失败确认重试）
+	// 常规间隔的探测始终执行，预算只约束超出常规节奏的额外探测
+	ProviderBudgets map[string]int `yaml:"provider_budgets" json:"provider_budgets,omitempty"`
+
+	// 远程探测节点结果上报配置（中心服务端）
+	Ingest IngestConfig `yaml:"ingest" json:"ingest"`
+
+	// 探测节点配置（agent 子命令）
+	Agent AgentConfig `yaml:"agent" json:"agent"`
+
 	Monitors []ServiceConfig `yaml:"monitors"`
+
+	// 监控项模板：模板名 -> 不完整的监控项，监控项通过 extends 引用（加载时展开，模板本身不参与校验）
+	Templates map[string]ServiceConfig `yaml:"templates,omitempty" json:"-"`
+
+	// include 目录（相对路径基于主配置文件所在目录），目录下每个 *.yaml / *.yml 可包含 monitors 和 templates
+	IncludeDir string `yaml:"include_dir,omitempty" json:"-"`
+
+	// 已加载的 include 文件（相对主配置文件目录的路径，按加载顺序），由 Loader 填充（内部使用）
+	IncludeFiles []string `yaml:"-" json:"-"`
+
+	// 加载时的警告（如 warn 模式下的未知字段），由 Loader 填充（内部使用）
+	Warnings []string `yaml:"-" json:"-"`
+
+	// ${VAR} 和 file: 引用解析出的值（可能是密钥，输出配置和差异时脱敏），由 Loader 填充（内部使用）
+	ResolvedSecrets []string `yaml:"-" json:"-"`
+
+	// 配置版本（配置文件内容 SHA-256 的前 12 位）与加载时间，由 Loader 填充（内部使用）
+	Version  string    `yaml:"-" json:"-"`
+	LoadedAt time.Time `yaml:"-" json:"-"`
 }
 
-// Validate 验证配置合法性
+// ValidationErrors 配置校验发现的全部错误（校验不在第一个错误处停止，便于一次改完）
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "共 %d 处错误:", len(e))
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap 支持 errors.Is / errors.As 匹配其中任一错误
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// add 追加错误（多个错误时展开，保持扁平）
+func (e *ValidationErrors) add(err error) {
+	if err == nil {
+		return
+	}
+	if nested, ok := err.(ValidationErrors); ok {
+		*e = append(*e, nested...)
+		return
+	}
+	*e = append(*e, err)
+}
+
+// err 没有错误时返回 nil（避免返回非 nil 的空接口值）
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate 验证配置合法性（返回全部错误，多个错误时为 ValidationErrors）
 func (c *AppConfig) Validate() error {
 	if len(c.Monitors) == 0 {
 		return fmt.Errorf("至少需要配置一个监控项")
 	}
 
+	var errs ValidationErrors
+
 	// 检查重复和必填字段
-	seen := make(map[string]bool)
+	seen := make(map[string]int)
 	for i, m := range c.Monitors {
 		// 必填字段检查
 		if m.Provider == "" {
-			return fmt.Errorf("monitor[%d]: provider 不能为空", i)
+			errs.add(fmt.Errorf("%s: provider 不能为空", m.where(i, "provider")))
 		}
 		if m.Service == "" {
-			return fmt.Errorf("monitor[%d]: service 不能为空", i)
+			errs.add(fmt.Errorf("%s: service 不能为空", m.where(i, "service")))
 		}
 		if m.URL == "" {
-			return fmt.Errorf("monitor[%d]: URL 不能为空", i)
-		}
-		if m.Method == "" {
-			return fmt.Errorf("monitor[%d]: method 不能为空", i)
-		}
-		if m.Category == "" {
-			return fmt.Errorf("monitor[%d]: category 不能为空（必须是 commercial 或 public）", i)
+			errs.add(fmt.Errorf("%s: URL 不能为空", m.where(i, "url")))
 		}
 		if strings.TrimSpace(m.Sponsor) == "" {
-			return fmt.Errorf("monitor[%d]: sponsor 不能为空", i)
+			errs.add(fmt.Errorf("%s: sponsor 不能为空", m.where(i, "sponsor")))
 		}
 
-		// Method 枚举检查
+		// Method 必填及枚举检查
 		validMethods := map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true}
-		if !validMethods[strings.ToUpper(m.Method)] {
-			return fmt.Errorf("monitor[%d]: method '%s' 无效，必须是 GET/POST/PUT/DELETE/PATCH 之一", i, m.Method)
+		if m.Method == "" {
+			errs.add(fmt.Errorf("%s: method 不能为空", m.where(i, "method")))
+		} else if !validMethods[strings.ToUpper(m.Method)] {
+			errs.add(fmt.Errorf("%s: method '%s' 无效，必须是 GET/POST/PUT/DELETE/PATCH 之一", m.where(i, "method"), m.Method))
+		}
+
+		// Category 必填及枚举检查
+		if m.Category == "" {
+			errs.add(fmt.Errorf("%s: category 不能为空（必须是 commercial 或 public）", m.where(i, "category")))
+		} else if !isValidCategory(m.Category) {
+			errs.add(fmt.Errorf("%s: category '%s' 无效，必须是 commercial 或 public", m.where(i, "category"), m.Category))
 		}
 
-		// Category 枚举检查
-		if !isValidCategory(m.Category) {
-			return fmt.Errorf("monitor[%d]: category '%s' 无效，必须是 commercial 或 public", i, m.Category)
+		// Labels 验证（可选字段）
+		if err := validateLabels(m.Labels); err != nil {
+			errs.add(fmt.Errorf("%s: labels: %w", m.where(i, "labels"), err))
 		}
 
 		// ProviderURL 验证（可选字段）
 		if m.ProviderURL != "" {
 			if err := validateURL(m.ProviderURL, "provider_url"); err != nil {
-				return fmt.Errorf("monitor[%d]: %w", i, err)
+				errs.add(fmt.Errorf("%s: %w", m.where(i, "provider_url"), err))
 			}
 		}
 
 		// SponsorURL 验证（可选字段）
 		if m.SponsorURL != "" {
 			if err := validateURL(m.SponsorURL, "sponsor_url"); err != nil {
-				return fmt.Errorf("monitor[%d]: %w", i, err)
+				errs.add(fmt.Errorf("%s: %w", m.where(i, "sponsor_url"), err))
 			}
 		}
 
 		// 唯一性检查（provider + service + channel 组合唯一）
 		key := m.Provider + "/" + m.Service + "/" + m.Channel
-		if seen[key] {
-			return fmt.Errorf("重复的监控项: provider=%s, service=%s, channel=%s", m.Provider, m.Service, m.Channel)
+		if j, ok := seen[key]; ok {
+			errs.add(fmt.Errorf("%s: 重复的监控项: provider=%s, service=%s, channel=%s（与 %s重复）",
+				m.where(i, ""), m.Provider, m.Service, m.Channel, c.Monitors[j].where(j, "")))
+		} else {
+			seen[key] = i
 		}
-		seen[key] = true
 	}
 
 	// 验证消息模板（如果通知已启用且企业微信已配置）
 	if c.Notifier.Enabled && c.Notifier.WeCom.Enabled {
 		if err := validateMessageTemplates(c.Notifier.WeCom.Templates); err != nil {
-			return fmt.Errorf("消息模板验证失败: %w", err)
+			errs.add(fmt.Errorf("消息模板验证失败: %w", err))
 		}
 	}
 
-	return nil
+	// 验证告警路由
+	for i, r := range c.Notifier.Routes {
+		where := fmt.Sprintf("notifier.routes[%d]", i)
+		if len(r.MatchLabels) == 0 {
+			errs.add(fmt.Errorf("%s: match_labels 不能为空", where))
+		} else if err := validateLabels(r.MatchLabels); err != nil {
+			errs.add(fmt.Errorf("%s: match_labels: %w", where, err))
+		}
+		if !r.WeCom.Enabled {
+			continue
+		}
+		if r.WeCom.WebhookURL == "" {
+			errs.add(fmt.Errorf("%s: wecom.webhook_url 不能为空", where))
+		}
+		if err := validateMessageTemplates(r.WeCom.Templates); err != nil {
+			errs.add(fmt.Errorf("%s: 消息模板验证失败: %w", where, err))
+		}
+	}
+
+	return errs.err()
 }
 
 // Normalize 规范化配置（填充默认值等）
 func (c *AppConfig) Normalize() error {
+	// 收集全部错误后统一返回；出错的字段使用默认值，以便继续检查其余字段
+	var errs ValidationErrors
+
 	// 巡检间隔
-	if c.Interval == "" {
-		c.IntervalDuration = time.Minute
-	} else {
+	c.IntervalDuration = time.Minute
+	if c.Interval != "" {
 		d, err := time.ParseDuration(c.Interval)
-		if err != nil {
-			return fmt.Errorf("解析 interval 失败: %w", err)
+		switch {
+		case err != nil:
+			errs.add(fmt.Errorf("解析 interval 失败: %w", err))
+		case d <= 0:
+			errs.add(fmt.Errorf("interval 必须大于 0"))
+		default:
+			c.IntervalDuration = d
 		}
-		if d <= 0 {
-			return fmt.Errorf("interval 必须大于 0")
-		}
-		c.IntervalDuration = d
 	}
 
 	// 慢请求阈值
-	if c.SlowLatency == "" {
-		c.SlowLatencyDuration = 5 * time.Second
-	} else {
+	c.SlowLatencyDuration = 5 * time.Second
+	if c.SlowLatency != "" {
 		d, err := time.ParseDuration(c.SlowLatency)
-		if err != nil {
-			return fmt.Errorf("解析 slow_latency 失败: %w", err)
-		}
-		if d <= 0 {
-			return fmt.Errorf("slow_latency 必须大于 0")
+		switch {
+		case err != nil:
+			errs.add(fmt.Errorf("解析 slow_latency 失败: %w", err))
+		case d <= 0:
+			errs.add(fmt.Errorf("slow_latency 必须大于 0"))
+		default:
+			c.SlowLatencyDuration = d
 		}
-		c.SlowLatencyDuration = d
 	}
 
 	// 黄色状态权重（默认 0.7，允许 0.01-1.0）
@@ -263,7 +871,7 @@ func (c *AppConfig) Normalize() error {
 		c.DegradedWeight = 0.7 // 未配置时使用默认值
 	}
 	if c.DegradedWeight < 0 || c.DegradedWeight > 1 {
-		return fmt.Errorf("degraded_weight 必须在 0 到 1 之间（0 表示使用默认值 0.7），当前值: %.2f", c.DegradedWeight)
+		errs.add(fmt.Errorf("degraded_weight 必须在 0 到 1 之间（0 表示使用默认值 0.7），当前值: %.2f", c.DegradedWeight))
 	}
 
 	// 公开访问的基础 URL（默认 https://relaypulse.top）
@@ -274,7 +882,7 @@ func (c *AppConfig) Normalize() error {
 	// 规范化 baseURL：去除尾随斜杠、验证协议
 	c.PublicBaseURL = strings.TrimRight(c.PublicBaseURL, "/")
 	if err := validateBaseURL(c.PublicBaseURL); err != nil {
-		return fmt.Errorf("public_base_url 无效: %w", err)
+		errs.add(fmt.Errorf("public_base_url 无效: %w", err))
 	}
 
 	// 最大并发数（默认 10）
@@ -285,7 +893,24 @@ func (c *AppConfig) Normalize() error {
 		c.MaxConcurrency = 10
 	}
 	if c.MaxConcurrency < -1 {
-		return fmt.Errorf("max_concurrency 无效值 %d，有效值：-1(无限制)、0(默认10)、>0(硬上限)", c.MaxConcurrency)
+		errs.add(fmt.Errorf("max_concurrency 无效值 %d，有效值：-1(无限制)、0(默认10)、>0(硬上限)", c.MaxConcurrency))
+	}
+
+	// 按主机和服务商的并发限制与请求间隔（默认不限制）
+	if c.MaxConcurrencyPerHost < 0 {
+		errs.add(fmt.Errorf("max_concurrency_per_host 不能为负数，当前值: %d", c.MaxConcurrencyPerHost))
+	}
+	if c.MaxConcurrencyPerProvider < 0 {
+		errs.add(fmt.Errorf("max_concurrency_per_provider 不能为负数，当前值: %d", c.MaxConcurrencyPerProvider))
+	}
+	c.HostMinSpacingDuration = 0
+	if c.HostMinSpacing != "" {
+		d, err := time.ParseDuration(c.HostMinSpacing)
+		if err != nil || d < 0 {
+			errs.add(fmt.Errorf("host_min_spacing '%s' 无效", c.HostMinSpacing))
+		} else {
+			c.HostMinSpacingDuration = d
+		}
 	}
 
 	// 探测错峰（默认开启）
@@ -293,15 +918,27 @@ func (c *AppConfig) Normalize() error {
 		defaultValue := true
 		c.StaggerProbes = &defaultValue
 	}
+	c.StaggerJitterDuration = 0
+	if c.StaggerJitter != "" {
+		d, err := time.ParseDuration(c.StaggerJitter)
+		if err != nil || d < 0 || d >= c.IntervalDuration {
+			errs.add(fmt.Errorf("stagger_jitter '%s' 无效，必须为非负数且小于 interval (%v)", c.StaggerJitter, c.IntervalDuration))
+		} else {
+			c.StaggerJitterDuration = d
+		}
+	}
 
 	// 并发查询限制（默认 10）
 	if c.ConcurrentQueryLimit == 0 {
 		c.ConcurrentQueryLimit = 10
 	}
 	if c.ConcurrentQueryLimit < 1 {
-		return fmt.Errorf("concurrent_query_limit 必须 >= 1，当前值: %d", c.ConcurrentQueryLimit)
+		errs.add(fmt.Errorf("concurrent_query_limit 必须 >= 1，当前值: %d", c.ConcurrentQueryLimit))
 	}
 
+	// HTTP 服务配置
+	errs.add(c.Server.Normalize())
+
 	// 存储配置默认值
 	if c.Storage.Type == "" {
 		c.Storage.Type = "sqlite" // 默认使用 SQLite
@@ -348,6 +985,13 @@ func (c *AppConfig) Normalize() error {
 		}
 	}
 
+	// 集群配置
+	errs.add(c.normalizeCluster())
+
+	// 多地域探测配置
+	errs.add(c.normalizeIngest())
+	errs.add(c.normalizeAgent())
+
 	// SQLite 场景下的并发查询警告
 	if c.Storage.Type == "sqlite" && c.EnableConcurrentQuery {
 		log.Println("[Config] 警告: SQLite 使用单连接（max_open_conns=1），并发查询无性能收益，建议关闭 enable_concurrent_query")
@@ -358,47 +1002,27 @@ func (c *AppConfig) Normalize() error {
 		c.Notifier.ContinuousFailureThreshold = 3 // 默认连续失败 3 次
 	}
 	if c.Notifier.ContinuousFailureThreshold < 1 {
-		return fmt.Errorf("continuous_failure_threshold 必须 >= 1，当前值: %d", c.Notifier.ContinuousFailureThreshold)
+		errs.add(fmt.Errorf("continuous_failure_threshold 必须 >= 1，当前值: %d", c.Notifier.ContinuousFailureThreshold))
 	}
 
 	// 最小通知间隔（冷却期）
-	if c.Notifier.MinNotifyInterval == "" {
-		c.Notifier.MinNotifyIntervalDuration = 5 * time.Minute // 默认 5 分钟
-	} else {
+	c.Notifier.MinNotifyIntervalDuration = 5 * time.Minute // 默认 5 分钟
+	if c.Notifier.MinNotifyInterval != "" {
 		d, err := time.ParseDuration(c.Notifier.MinNotifyInterval)
-		if err != nil {
-			return fmt.Errorf("解析 min_notify_interval 失败: %w", err)
+		switch {
+		case err != nil:
+			errs.add(fmt.Errorf("解析 min_notify_interval 失败: %w", err))
+		case d <= 0:
+			errs.add(fmt.Errorf("min_notify_interval 必须大于 0"))
+		default:
+			c.Notifier.MinNotifyIntervalDuration = d
 		}
-		if d <= 0 {
-			return fmt.Errorf("min_notify_interval 必须大于 0")
-		}
-		c.Notifier.MinNotifyIntervalDuration = d
 	}
 
 	// 企业微信配置默认值
-	if c.Notifier.WeCom.Timeout == "" {
-		c.Notifier.WeCom.TimeoutDuration = 5 * time.Second // 默认 5 秒
-	} else {
-		d, err := time.ParseDuration(c.Notifier.WeCom.Timeout)
-		if err != nil {
-			return fmt.Errorf("解析 wecom.timeout 失败: %w", err)
-		}
-		if d <= 0 {
-			return fmt.Errorf("wecom.timeout 必须大于 0")
-		}
-		c.Notifier.WeCom.TimeoutDuration = d
-	}
-
-	if c.Notifier.WeCom.RetryCount == 0 {
-		c.Notifier.WeCom.RetryCount = 2 // 默认重试 2 次
-	}
-	if c.Notifier.WeCom.RetryCount < 0 {
-		return fmt.Errorf("wecom.retry_count 不能为负数，当前值: %d", c.Notifier.WeCom.RetryCount)
-	}
-
-	// 企业微信消息模板默认值
-	if c.Notifier.WeCom.Templates == nil {
-		c.Notifier.WeCom.Templates = GetDefaultMessageTemplates()
+	errs.add(c.Notifier.WeCom.normalize("wecom"))
+	for i := range c.Notifier.Routes {
+		errs.add(c.Notifier.Routes[i].WeCom.normalize(fmt.Sprintf("notifier.routes[%d].wecom", i)))
 	}
 
 	// 企业微信启用但未配置 webhook URL 时的警告
@@ -406,12 +1030,52 @@ func (c *AppConfig) Normalize() error {
 		log.Println("[Config] 警告: 企业微信通知已启用但未配置 webhook_url，告警将无法发送")
 	}
 
-	// 将全局慢请求阈值下发到每个监控项，并标准化 category、URLs、provider_slug
+	// 全局失败确认策略
+	globalConfirm, err := c.Confirm.resolve(ConfirmConfig{}, "confirm")
+	errs.add(err)
+	c.Confirm = globalConfirm
+
+	// 全局自适应探测频率
+	globalAdaptive, err := c.Adaptive.resolve(AdaptiveConfig{}, c.IntervalDuration, "adaptive")
+	errs.add(err)
+	c.Adaptive = globalAdaptive
+
+	// 将全局慢请求阈值、失败确认策略和自适应探测频率下发到每个监控项，并标准化 category、URLs、provider_slug
 	slugSet := make(map[string]int) // slug -> monitor index (用于检测重复)
 	for i := range c.Monitors {
 		if c.Monitors[i].SlowLatencyDuration == 0 {
 			c.Monitors[i].SlowLatencyDuration = c.SlowLatencyDuration
 		}
+
+		policy, err := c.Monitors[i].Confirm.resolve(globalConfirm, "confirm")
+		if err != nil {
+			errs.add(fmt.Errorf("%s: %w", c.Monitors[i].where(i, "confirm"), err))
+		}
+		if wait := time.Duration(policy.RetryCount()) * policy.DelayDuration; wait >= c.IntervalDuration {
+			log.Printf("[Config] 警告: monitor[%d] 失败确认最长等待 %v，不小于巡检间隔 %v", i, wait, c.IntervalDuration)
+		}
+		c.Monitors[i].ConfirmPolicy = policy
+
+		adaptive, err := c.Monitors[i].Adaptive.resolve(globalAdaptive, c.IntervalDuration, "adaptive")
+		if err != nil {
+			errs.add(fmt.Errorf("%s: %w", c.Monitors[i].where(i, "adaptive"), err))
+		}
+		c.Monitors[i].AdaptivePolicy = adaptive
+
+		if err := c.Monitors[i].validatePlaceholders(); err != nil {
+			errs.add(fmt.Errorf("%s: %w", c.Monitors[i].where(i, "vars"), err))
+		}
+
+		c.Monitors[i].ScheduleSpec = nil
+		if sc := c.Monitors[i].Schedule; sc != nil {
+			spec, err := schedule.Parse(sc.Cron, sc.Timezone, sc.ActiveHours, sc.ActiveDays)
+			if err != nil {
+				errs.add(fmt.Errorf("%s: schedule: %w", c.Monitors[i].where(i, "schedule"), err))
+			} else {
+				c.Monitors[i].ScheduleSpec = spec
+			}
+		}
+
 		// 标准化 category 为小写
 		c.Monitors[i].Category = strings.ToLower(c.Monitors[i].Category)
 
@@ -428,11 +1092,12 @@ func (c *AppConfig) Normalize() error {
 
 		// 无论自动生成还是手动配置，都进行格式验证
 		// 确保配置期即可发现 slug 格式问题，避免运行时 404
-		if err := validateProviderSlug(slug); err != nil {
-			return fmt.Errorf("monitor[%d]: provider_slug '%s' 无效 (来源: %s): %w",
-				i, slug,
+		// provider 为空时 Validate 已报告，不再重复报告自动生成的空 slug
+		if err := validateProviderSlug(slug); err != nil && slug != "" {
+			errs.add(fmt.Errorf("%s: provider_slug '%s' 无效 (来源: %s): %w",
+				c.Monitors[i].where(i, "provider_slug"), slug,
 				map[bool]string{true: "自动生成", false: "手动配置"}[c.Monitors[i].ProviderSlug == ""],
-				err)
+				err))
 		}
 
 		c.Monitors[i].ProviderSlug = slug
@@ -445,9 +1110,85 @@ func (c *AppConfig) Normalize() error {
 		}
 	}
 
+	// 移除已停用的监控项（已完成校验，仍保留在配置文件中）
+	active := c.Monitors[:0]
+	for _, m := range c.Monitors {
+		if m.Disabled {
+			log.Printf("[Config] 监控项已停用，跳过: %s/%s/%s", m.Provider, m.Service, m.Channel)
+			continue
+		}
+		active = append(active, m)
+	}
+	c.Monitors = active
+
+	// 主机请求间隔过大时，同一主机的监控项无法在一个巡检周期内探测完
+	if c.HostMinSpacingDuration > 0 {
+		perHost := make(map[string]int)
+		for _, m := range c.Monitors {
+			if u, err := url.Parse(m.URL); err == nil {
+				perHost[strings.ToLower(u.Host)]++
+			}
+		}
+		for host, n := range perHost {
+			if need := time.Duration(n) * c.HostMinSpacingDuration; need > c.IntervalDuration {
+				log.Printf("[Config] 警告: 主机 %s 有 %d 个监控项，按 host_min_spacing=%v 需要 %v，超过巡检间隔 %v",
+					host, n, c.HostMinSpacingDuration, need, c.IntervalDuration)
+			}
+		}
+	}
+
+	errs.add(c.validateProviderBudgets())
+	return errs.err()
+}
+
+// validateProviderBudgets 校验服务商探测预算能覆盖常规间隔的探测
+func (c *AppConfig) validateProviderBudgets() error {
+	for provider, budget := range c.ProviderBudgets {
+		if budget < 0 {
+			return fmt.Errorf("provider_budgets[%s] 不能为负数，当前值: %d", provider, budget)
+		}
+		base := c.baseProbesPerHour(provider)
+		if base == 0 {
+			log.Printf("[Config] 警告: provider_budgets[%s] 没有对应的监控项", provider)
+			continue
+		}
+		if budget < base {
+			return fmt.Errorf("provider_budgets[%s]=%d 小于常规间隔所需的 %d 次/小时，请调大预算或 interval", provider, budget, base)
+		}
+	}
 	return nil
 }
 
+// baseProbesPerHour 返回服务商按常规间隔每小时的探测次数
+func (c *AppConfig) baseProbesPerHour(provider string) int {
+	if c.IntervalDuration <= 0 {
+		return 0
+	}
+	perMonitor := int((time.Hour + c.IntervalDuration - 1) / c.IntervalDuration)
+	total := 0
+	for _, m := range c.Monitors {
+		if m.Provider != provider {
+			continue
+		}
+		if m.ScheduleSpec.HasCron() {
+			total += m.ScheduleSpec.MaxPerHour()
+		} else {
+			total += perMonitor
+		}
+	}
+	return total
+}
+
+// ExtraProbeBudget 返回服务商每小时可用于额外探测（加速探测与失败确认重试）的次数
+// 未配置预算时 ok 为 false，表示不限制
+func (c *AppConfig) ExtraProbeBudget(provider string) (limit int, ok bool) {
+	budget, ok := c.ProviderBudgets[provider]
+	if !ok {
+		return 0, false
+	}
+	return max(budget-c.baseProbesPerHour(provider), 0), true
+}
+
 // ApplyEnvOverrides 应用环境变量覆盖
 // API Key 格式：MONITOR_<PROVIDER>_<SERVICE>_API_KEY
 // 存储配置格式：MONITOR_STORAGE_TYPE, MONITOR_POSTGRES_HOST 等
@@ -489,33 +1230,124 @@ func (c *AppConfig) ApplyEnvOverrides() {
 		c.Storage.SQLite.Path = envPath
 	}
 
+	// 额外的跨域来源（逗号分隔，追加到 server.cors.allowed_origins）
+	if extraOrigins := os.Getenv("MONITOR_CORS_ORIGINS"); extraOrigins != "" {
+		if len(c.Server.CORS.AllowedOrigins) == 0 {
+			c.Server.CORS.AllowedOrigins = append([]string(nil), defaultCORSOrigins...)
+		}
+		c.Server.CORS.AllowedOrigins = append(c.Server.CORS.AllowedOrigins, strings.Split(extraOrigins, ",")...)
+	}
+
+	// HTTP 监听地址环境变量覆盖
+	if envListen := os.Getenv("MONITOR_SERVER_LISTEN"); envListen != "" {
+		c.Server.Listen = envListen
+	}
+
+	// 管理 API 令牌环境变量覆盖
+	if envToken := os.Getenv("MONITOR_ADMIN_TOKEN"); envToken != "" {
+		c.Admin.Token = envToken
+	}
+
+	// 集群节点标识环境变量覆盖
+	if envNodeID := os.Getenv("MONITOR_NODE_ID"); envNodeID != "" {
+		c.Cluster.NodeID = envNodeID
+	}
+
+	// 探测节点签名密钥环境变量覆盖
+	if envSecret := os.Getenv("MONITOR_AGENT_SECRET"); envSecret != "" {
+		c.Agent.Secret = envSecret
+	}
+	for i := range c.Ingest.Agents {
+		a := &c.Ingest.Agents[i]
+		envKey := fmt.Sprintf("MONITOR_INGEST_%s_SECRET", strings.ToUpper(strings.ReplaceAll(a.ID, "-", "_")))
+		if envVal := os.Getenv(envKey); envVal != "" {
+			a.Secret = envVal
+		}
+	}
+
 	// 通知配置环境变量覆盖
 	if envWebhook := os.Getenv("MONITOR_NOTIFIER_WECOM_WEBHOOK_URL"); envWebhook != "" {
 		c.Notifier.WeCom.WebhookURL = envWebhook
 	}
 
-	// API Key 覆盖
+	// API Key 覆盖：MONITOR_<PROVIDER>_<SERVICE>_<CHANNEL>_API_KEY 优先于 MONITOR_<PROVIDER>_<SERVICE>_API_KEY
 	for i := range c.Monitors {
 		m := &c.Monitors[i]
-		envKey := fmt.Sprintf("MONITOR_%s_%s_API_KEY",
-			strings.ToUpper(strings.ReplaceAll(m.Provider, "-", "_")),
-			strings.ToUpper(strings.ReplaceAll(m.Service, "-", "_")))
+		for _, envKey := range apiKeyEnvNames(m) {
+			if envVal := os.Getenv(envKey); envVal != "" {
+				m.APIKey = envVal
+				break
+			}
+		}
+	}
+}
 
-		if envVal := os.Getenv(envKey); envVal != "" {
-			m.APIKey = envVal
+// apiKeyEnvNames 返回监控项 API Key 的环境变量名（按优先级排列，- 替换为 _）
+func apiKeyEnvNames(m *ServiceConfig) []string {
+	envPart := func(s string) string {
+		return strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+	}
+	base := fmt.Sprintf("MONITOR_%s_%s", envPart(m.Provider), envPart(m.Service))
+	if m.Channel == "" {
+		return []string{base + "_API_KEY"}
+	}
+	return []string{fmt.Sprintf("%s_%s_API_KEY", base, envPart(m.Channel)), base + "_API_KEY"}
+}
+
+// placeholderPattern 匹配 {{NAME}} 形式的命名占位符
+var placeholderPattern = regexp.MustCompile(`\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}`)
+
+// validatePlaceholders 检查 vars 的名称和 url、headers、body 中引用的占位符是否都有定义（避免拼写错误的占位符原样发给上游）
+func (m *ServiceConfig) validatePlaceholders() error {
+	for name := range m.Vars {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("vars: 名称 '%s' 无效（只能包含字母、数字和下划线，且不能以数字开头）", name)
+		}
+		if name == "API_KEY" {
+			return fmt.Errorf("vars: 不能定义 API_KEY（请使用 api_key 字段）")
 		}
 	}
+
+	texts := []string{m.URL, m.Body}
+	for _, v := range m.Headers {
+		texts = append(texts, v)
+	}
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if name := match[1]; name != "API_KEY" {
+				if _, ok := m.Vars[name]; !ok {
+					return fmt.Errorf("占位符 {{%s}} 未在 vars 中定义", name)
+				}
+			}
+		}
+	}
+	return nil
 }
 
-// ProcessPlaceholders 处理 {{API_KEY}} 占位符替换（headers 和 body）
+// ProcessPlaceholders 处理占位符替换（url、headers 和 body）：{{API_KEY}} 替换为 api_key，{{NAME}} 替换为 vars 中的值
 func (m *ServiceConfig) ProcessPlaceholders() {
+	replace := func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+			name := match[2 : len(match)-2]
+			if name == "API_KEY" {
+				return m.APIKey
+			}
+			if value, ok := m.Vars[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	m.URL = replace(m.URL)
+
 	// Headers 中替换
 	for k, v := range m.Headers {
-		m.Headers[k] = strings.ReplaceAll(v, "{{API_KEY}}", m.APIKey)
+		m.Headers[k] = replace(v)
 	}
 
 	// Body 中替换
-	m.Body = strings.ReplaceAll(m.Body, "{{API_KEY}}", m.APIKey)
+	m.Body = replace(m.Body)
 }
 
 // ResolveBodyIncludes 允许 body 字段引用 data/ 目录下的 JSON 文件
@@ -564,6 +1396,298 @@ func (m *ServiceConfig) resolveBodyInclude(configDir string) error {
 	return nil
 }
 
+// Normalize 填充 HTTP 服务配置默认值、解析超时并校验中间件策略（可重复调用）
+func (s *ServerConfig) Normalize() error {
+	s.Listen = strings.TrimSpace(s.Listen)
+	s.UnixSocket = strings.TrimSpace(s.UnixSocket)
+	if s.Listen == "" && s.UnixSocket == "" {
+		s.Listen = ":8081"
+	}
+
+	if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		return fmt.Errorf("server.tls.cert_file 与 server.tls.key_file 必须同时配置")
+	}
+
+	s.UnixSocketFileMode = 0o660
+	if s.UnixSocketMode != "" {
+		mode, err := strconv.ParseUint(s.UnixSocketMode, 8, 32)
+		if err != nil || mode > 0o777 {
+			return fmt.Errorf("server.unix_socket_mode 无效（需为八进制权限，如 \"0660\"）: %s", s.UnixSocketMode)
+		}
+		s.UnixSocketFileMode = os.FileMode(mode)
+	}
+
+	if err := s.normalizePolicy(); err != nil {
+		return err
+	}
+
+	timeouts := []struct {
+		name   string
+		raw    string
+		def    time.Duration
+		target *time.Duration
+	}{
+		{"read_timeout", s.ReadTimeout, 15 * time.Second, &s.ReadTimeoutDuration},
+		{"write_timeout", s.WriteTimeout, 15 * time.Second, &s.WriteTimeoutDuration},
+		{"idle_timeout", s.IdleTimeout, 60 * time.Second, &s.IdleTimeoutDuration},
+	}
+	for _, t := range timeouts {
+		if t.raw == "" {
+			*t.target = t.def
+			continue
+		}
+		d, err := time.ParseDuration(t.raw)
+		if err != nil {
+			return fmt.Errorf("解析 server.%s 失败: %w", t.name, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("server.%s 必须大于 0", t.name)
+		}
+		*t.target = d
+	}
+
+	return nil
+}
+
+// normalizePolicy 填充并校验中间件策略默认值
+func (s *ServerConfig) normalizePolicy() error {
+	// CORS 来源
+	if len(s.CORS.AllowedOrigins) == 0 {
+		s.CORS.AllowedOrigins = append([]string(nil), defaultCORSOrigins...)
+	}
+	origins := make([]string, 0, len(s.CORS.AllowedOrigins))
+	for _, origin := range s.CORS.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("server.cors.allowed_origins: '%s' 无效，必须以 http:// 或 https:// 开头（或使用 \"*\"）", origin)
+		}
+		origins = append(origins, origin)
+	}
+	s.CORS.AllowedOrigins = origins
+
+	// CORS 方法
+	if len(s.CORS.AllowedMethods) == 0 {
+		s.CORS.AllowedMethods = []string{"GET", "OPTIONS"}
+	}
+	validMethods := map[string]bool{"GET": true, "HEAD": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "OPTIONS": true}
+	for i, method := range s.CORS.AllowedMethods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if !validMethods[method] {
+			return fmt.Errorf("server.cors.allowed_methods: '%s' 无效", method)
+		}
+		s.CORS.AllowedMethods[i] = method
+	}
+
+	// HSTS
+	if s.HSTS.Enabled == nil {
+		enabled := true
+		s.HSTS.Enabled = &enabled
+	}
+	if s.HSTS.IncludeSubdomains == nil {
+		include := true
+		s.HSTS.IncludeSubdomains = &include
+	}
+	if s.HSTS.MaxAge == 0 {
+		s.HSTS.MaxAge = 31536000
+	}
+	if s.HSTS.MaxAge < 0 {
+		return fmt.Errorf("server.hsts.max_age 不能为负数，当前值: %d", s.HSTS.MaxAge)
+	}
+
+	// frame-ancestors
+	s.FrameAncestors.Default = strings.TrimSpace(s.FrameAncestors.Default)
+	if s.FrameAncestors.Default == "" {
+		s.FrameAncestors.Default = "'self'"
+	}
+	if s.FrameAncestors.Paths == nil {
+		s.FrameAncestors.Paths = map[string]string{"/p/": "*"}
+	}
+	for prefix := range s.FrameAncestors.Paths {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("server.frame_ancestors.paths: 路径前缀 '%s' 必须以 / 开头", prefix)
+		}
+	}
+
+	// gzip 策略
+	s.GzipMode = strings.ToLower(strings.TrimSpace(s.GzipMode))
+	if s.GzipMode == "" {
+		s.GzipMode = GzipModeRequired
+	}
+	if s.GzipMode != GzipModeRequired && s.GzipMode != GzipModePreferred {
+		return fmt.Errorf("server.gzip_mode '%s' 无效，必须是 required 或 preferred", s.GzipMode)
+	}
+
+	return s.RateLimit.normalize()
+}
+
+// normalize 填充限流默认值并解析可信代理
+func (r *RateLimitConfig) normalize() error {
+	r.TrustedProxyNets = nil
+	for _, proxy := range r.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("server.rate_limit.trusted_proxies: '%s' 不是有效的 IP 或 CIDR", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			r.TrustedProxyNets = append(r.TrustedProxyNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("server.rate_limit.trusted_proxies: '%s' 不是有效的 IP 或 CIDR", proxy)
+		}
+		r.TrustedProxyNets = append(r.TrustedProxyNets, ipNet)
+	}
+
+	source := r.Routes
+	if source == nil {
+		source = defaultRateLimitRoutes
+	}
+	routes := make(map[string]RateLimitRule, len(source))
+	for prefix, rule := range source {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("server.rate_limit.routes: 路径前缀 '%s' 必须以 / 开头", prefix)
+		}
+		if err := rule.normalize(); err != nil {
+			return fmt.Errorf("server.rate_limit.routes[%s]: %w", prefix, err)
+		}
+		routes[prefix] = rule
+	}
+	r.Routes = routes
+
+	if err := r.Default.normalize(); err != nil {
+		return fmt.Errorf("server.rate_limit.default: %w", err)
+	}
+	return nil
+}
+
+func (r *RateLimitRule) normalize() error {
+	if r.PerMinute < 0 {
+		return fmt.Errorf("per_minute 不能为负数，当前值: %v", r.PerMinute)
+	}
+	if r.Burst < 0 {
+		return fmt.Errorf("burst 不能为负数，当前值: %d", r.Burst)
+	}
+	if r.PerMinute > 0 && r.Burst == 0 {
+		r.Burst = max(1, int(r.PerMinute/6))
+	}
+	return nil
+}
+
+// normalizeCluster 填充集群配置默认值（依赖已解析的 interval 和存储类型）
+func (c *AppConfig) normalizeCluster() error {
+	c.Cluster.Mode = strings.ToLower(strings.TrimSpace(c.Cluster.Mode))
+	if c.Cluster.Mode == "" {
+		c.Cluster.Mode = ClusterModeSingle
+	}
+	switch c.Cluster.Mode {
+	case ClusterModeSingle:
+		return nil
+	case ClusterModeLeader, ClusterModeShard:
+	default:
+		return fmt.Errorf("cluster.mode '%s' 无效，必须是 single、leader 或 shard", c.Cluster.Mode)
+	}
+
+	if c.Storage.Type != "postgres" {
+		return fmt.Errorf("cluster.mode=%s 需要 PostgreSQL 存储（多副本共享租约/心跳表）", c.Cluster.Mode)
+	}
+
+	c.Cluster.NodeID = strings.TrimSpace(c.Cluster.NodeID)
+	if c.Cluster.NodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "monitor"
+		}
+		c.Cluster.NodeID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	if c.Cluster.LeaseTTL == "" {
+		c.Cluster.LeaseTTLDuration = max(c.IntervalDuration/2, 5*time.Second)
+	} else {
+		d, err := time.ParseDuration(c.Cluster.LeaseTTL)
+		if err != nil {
+			return fmt.Errorf("解析 cluster.lease_ttl 失败: %w", err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("cluster.lease_ttl 不能小于 1s，当前值: %v", d)
+		}
+		c.Cluster.LeaseTTLDuration = d
+	}
+	if c.Cluster.LeaseTTLDuration >= c.IntervalDuration {
+		log.Printf("[Config] 警告: cluster.lease_ttl(%v) >= interval(%v)，节点故障后可能错过一个以上的巡检周期",
+			c.Cluster.LeaseTTLDuration, c.IntervalDuration)
+	}
+	return nil
+}
+
+// normalizeIngest 校验探测节点上报配置
+func (c *AppConfig) normalizeIngest() error {
+	c.Ingest.LocalRegion = strings.TrimSpace(c.Ingest.LocalRegion)
+	if c.Ingest.LocalRegion == "" {
+		c.Ingest.LocalRegion = DefaultLocalRegion
+	}
+
+	seen := make(map[string]bool)
+	for i := range c.Ingest.Agents {
+		a := &c.Ingest.Agents[i]
+		a.ID = strings.TrimSpace(a.ID)
+		a.Region = strings.TrimSpace(a.Region)
+		if a.ID == "" {
+			return fmt.Errorf("ingest.agents[%d]: id 不能为空", i)
+		}
+		if seen[a.ID] {
+			return fmt.Errorf("ingest.agents[%d]: id '%s' 重复", i, a.ID)
+		}
+		seen[a.ID] = true
+		if a.Region == "" {
+			return fmt.Errorf("ingest.agents[%d] (%s): region 不能为空", i, a.ID)
+		}
+		if a.Region == c.Ingest.LocalRegion {
+			return fmt.Errorf("ingest.agents[%d] (%s): region 不能与 local_region '%s' 相同", i, a.ID, a.Region)
+		}
+		if a.Secret == "" {
+			return fmt.Errorf("ingest.agents[%d] (%s): secret 不能为空", i, a.ID)
+		}
+	}
+	return nil
+}
+
+// normalizeAgent 校验探测节点配置（未配置 server_url 时跳过，仅 agent 子命令要求必填）
+func (c *AppConfig) normalizeAgent() error {
+	c.Agent.ServerURL = strings.TrimRight(strings.TrimSpace(c.Agent.ServerURL), "/")
+	c.Agent.ID = strings.TrimSpace(c.Agent.ID)
+	c.Agent.Region = strings.TrimSpace(c.Agent.Region)
+
+	if c.Agent.FlushInterval == "" {
+		c.Agent.FlushIntervalDuration = 10 * time.Second
+	} else {
+		d, err := time.ParseDuration(c.Agent.FlushInterval)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("agent.flush_interval '%s' 无效，必须是正的时间间隔", c.Agent.FlushInterval)
+		}
+		c.Agent.FlushIntervalDuration = d
+	}
+
+	if c.Agent.ServerURL == "" {
+		return nil
+	}
+	if err := validateURL(c.Agent.ServerURL, "agent.server_url"); err != nil {
+		return err
+	}
+	if c.Agent.ID == "" || c.Agent.Region == "" || c.Agent.Secret == "" {
+		return fmt.Errorf("agent: 配置 server_url 时 id、region、secret 均不能为空")
+	}
+	return nil
+}
+
 // isValidCategory 检查 category 是否为有效值
 func isValidCategory(category string) bool {
 	normalized := strings.ToLower(strings.TrimSpace(category))
@@ -580,21 +1704,44 @@ func (c *AppConfig) Clone() *AppConfig {
 	}
 
 	clone := &AppConfig{
-		Interval:              c.Interval,
-		IntervalDuration:      c.IntervalDuration,
-		SlowLatency:           c.SlowLatency,
-		SlowLatencyDuration:   c.SlowLatencyDuration,
-		DegradedWeight:        c.DegradedWeight,
-		MaxConcurrency:        c.MaxConcurrency,
-		StaggerProbes:         staggerPtr,
-		EnableConcurrentQuery: c.EnableConcurrentQuery,
-		ConcurrentQueryLimit:  c.ConcurrentQueryLimit,
-		Storage:               c.Storage,
-		PublicBaseURL:         c.PublicBaseURL,
-		Notifier:              c.Notifier,
-		Monitors:              make([]ServiceConfig, len(c.Monitors)),
+		Interval:                  c.Interval,
+		IntervalDuration:          c.IntervalDuration,
+		SlowLatency:               c.SlowLatency,
+		SlowLatencyDuration:       c.SlowLatencyDuration,
+		DegradedWeight:            c.DegradedWeight,
+		MaxConcurrency:            c.MaxConcurrency,
+		MaxConcurrencyPerHost:     c.MaxConcurrencyPerHost,
+		MaxConcurrencyPerProvider: c.MaxConcurrencyPerProvider,
+		HostMinSpacing:            c.HostMinSpacing,
+		HostMinSpacingDuration:    c.HostMinSpacingDuration,
+		StaggerProbes:             staggerPtr,
+		StaggerJitter:             c.StaggerJitter,
+		StaggerJitterDuration:     c.StaggerJitterDuration,
+		EnableConcurrentQuery:     c.EnableConcurrentQuery,
+		ConcurrentQueryLimit:      c.ConcurrentQueryLimit,
+		Storage:                   c.Storage,
+		PublicBaseURL:             c.PublicBaseURL,
+		Notifier:                  c.Notifier,
+		Server:                    c.Server,
+		Admin:                     c.Admin,
+		Cluster:                   c.Cluster,
+		Confirm:                   c.Confirm,
+		Adaptive:                  c.Adaptive,
+		ProviderBudgets:           maps.Clone(c.ProviderBudgets),
+		Ingest:                    c.Ingest,
+		Agent:                     c.Agent,
+		Monitors:                  make([]ServiceConfig, len(c.Monitors)),
+		Templates:                 maps.Clone(c.Templates),
+		IncludeDir:                c.IncludeDir,
+		IncludeFiles:              append([]string(nil), c.IncludeFiles...),
+		Warnings:                  append([]string(nil), c.Warnings...),
+		ResolvedSecrets:           append([]string(nil), c.ResolvedSecrets...),
+		Version:                   c.Version,
+		LoadedAt:                  c.LoadedAt,
 	}
 	copy(clone.Monitors, c.Monitors)
+	clone.Ingest.Agents = append([]IngestAgent(nil), c.Ingest.Agents...)
+	clone.Notifier.Routes = append([]NotifierRoute(nil), c.Notifier.Routes...)
 	return clone
 }
 
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestResolveBodyIncludes(t *testing.T) {
@@ -122,3 +124,204 @@ func TestBaseURLNormalization(t *testing.T) {
 	}
 }
 
+
+func TestServerConfigNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      ServerConfig
+		wantListen string
+		wantErr    bool
+	}{
+		{"默认监听 8081", ServerConfig{}, ":8081", false},
+		{"仅 unix socket 时不监听 TCP", ServerConfig{UnixSocket: "/tmp/monitor.sock"}, "", false},
+		{"自定义地址", ServerConfig{Listen: "127.0.0.1:9000"}, "127.0.0.1:9000", false},
+		{"TLS 缺少 key", ServerConfig{TLS: TLSConfig{CertFile: "cert.pem"}}, "", true},
+		{"超时格式错误", ServerConfig{ReadTimeout: "abc"}, "", true},
+		{"socket 权限错误", ServerConfig{UnixSocket: "/tmp/m.sock", UnixSocketMode: "999"}, "", true},
+		{"CORS 来源缺少协议", ServerConfig{CORS: CORSConfig{AllowedOrigins: []string{"example.com"}}}, "", true},
+		{"CORS 方法无效", ServerConfig{CORS: CORSConfig{AllowedMethods: []string{"FETCH"}}}, "", true},
+		{"frame-ancestors 前缀无效", ServerConfig{FrameAncestors: FrameAncestorsConfig{Paths: map[string]string{"p/": "*"}}}, "", true},
+		{"gzip_mode 无效", ServerConfig{GzipMode: "always"}, "", true},
+		{"可信代理无效", ServerConfig{RateLimit: RateLimitConfig{TrustedProxies: []string{"10.0.0.0/33"}}}, "", true},
+		{"限流速率为负", ServerConfig{RateLimit: RateLimitConfig{Routes: map[string]RateLimitRule{"/api/": {PerMinute: -1}}}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.input
+			err := cfg.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalize() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Listen != tt.wantListen {
+				t.Errorf("Listen = %q, want %q", cfg.Listen, tt.wantListen)
+			}
+			if cfg.ReadTimeoutDuration <= 0 || cfg.WriteTimeoutDuration <= 0 || cfg.IdleTimeoutDuration <= 0 {
+				t.Errorf("超时应填充默认值: %+v", cfg)
+			}
+			if cfg.GzipMode != GzipModeRequired || cfg.HSTS.Enabled == nil || !*cfg.HSTS.Enabled || len(cfg.CORS.AllowedOrigins) == 0 {
+				t.Errorf("中间件策略应填充默认值: %+v", cfg)
+			}
+		})
+	}
+}
+
+// TestClusterConfigNormalize 验证主备模式的存储要求与租约默认值
+func TestClusterConfigNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cluster ClusterConfig
+		storage string
+		wantTTL time.Duration
+		wantErr bool
+	}{
+		{"默认单实例", ClusterConfig{}, "sqlite", 0, false},
+		{"主备模式默认租约为 interval 一半", ClusterConfig{Mode: "leader", NodeID: "a"}, "postgres", 30 * time.Second, false},
+		{"自定义租约", ClusterConfig{Mode: "leader", NodeID: "a", LeaseTTL: "10s"}, "postgres", 10 * time.Second, false},
+		{"主备模式需要 PostgreSQL", ClusterConfig{Mode: "leader"}, "sqlite", 0, true},
+		{"分片模式", ClusterConfig{Mode: "shard", NodeID: "a", LeaseTTL: "15s"}, "postgres", 15 * time.Second, false},
+		{"模式无效", ClusterConfig{Mode: "multi"}, "postgres", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AppConfig{IntervalDuration: time.Minute, Cluster: tt.cluster}
+			cfg.Storage.Type = tt.storage
+			err := cfg.normalizeCluster()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeCluster() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Cluster.LeaseTTLDuration != tt.wantTTL {
+				t.Errorf("LeaseTTLDuration = %v, want %v", cfg.Cluster.LeaseTTLDuration, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestIngestAndAgentConfigNormalize(t *testing.T) {
+	t.Parallel()
+
+	valid := IngestAgent{ID: "sg-1", Region: "sg", Secret: "s"}
+	tests := []struct {
+		name    string
+		ingest  IngestConfig
+		agent   AgentConfig
+		wantErr bool
+	}{
+		{"默认不启用", IngestConfig{}, AgentConfig{}, false},
+		{"合法节点", IngestConfig{Agents: []IngestAgent{valid}}, AgentConfig{}, false},
+		{"节点 ID 重复", IngestConfig{Agents: []IngestAgent{valid, valid}}, AgentConfig{}, true},
+		{"缺少密钥", IngestConfig{Agents: []IngestAgent{{ID: "sg-1", Region: "sg"}}}, AgentConfig{}, true},
+		{"地域与本机相同", IngestConfig{Agents: []IngestAgent{{ID: "a", Region: "local", Secret: "s"}}}, AgentConfig{}, true},
+		{"探测节点配置完整", IngestConfig{}, AgentConfig{ServerURL: "https://example.com/", ID: "sg-1", Region: "sg", Secret: "s"}, false},
+		{"探测节点缺少密钥", IngestConfig{}, AgentConfig{ServerURL: "https://example.com", ID: "sg-1", Region: "sg"}, true},
+		{"上报间隔无效", IngestConfig{}, AgentConfig{FlushInterval: "soon"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AppConfig{Ingest: tt.ingest, Agent: tt.agent}
+			err := cfg.normalizeIngest()
+			if err == nil {
+				err = cfg.normalizeAgent()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.Ingest.LocalRegion != DefaultLocalRegion || strings.HasSuffix(cfg.Agent.ServerURL, "/")) {
+				t.Errorf("normalize 结果 = %+v / %+v", cfg.Ingest, cfg.Agent)
+			}
+		})
+	}
+}
+
+func TestConfirmPolicyResolve(t *testing.T) {
+	t.Parallel()
+
+	intPtr := func(v int) *int { return &v }
+	cfg := AppConfig{
+		Confirm: ConfirmConfig{Retries: intPtr(2), Delay: "1s"},
+		Monitors: []ServiceConfig{
+			{Provider: "a", Service: "cc"},
+			{Provider: "b", Service: "cc", Confirm: &ConfirmConfig{Retries: intPtr(0)}},
+			{Provider: "c", Service: "cc", Confirm: &ConfirmConfig{SubStatuses: []string{"auth_error"}}},
+		},
+	}
+	if err := cfg.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	inherited := cfg.Monitors[0].ConfirmPolicy
+	if inherited.RetryCount() != 2 || inherited.DelayDuration != time.Second {
+		t.Errorf("继承全局策略 = %+v", inherited)
+	}
+	if !inherited.ShouldRetry("network_error") || inherited.ShouldRetry("auth_error") {
+		t.Errorf("默认只重试瞬时故障: %+v", inherited.SubStatuses)
+	}
+	if cfg.Monitors[1].ConfirmPolicy.ShouldRetry("network_error") {
+		t.Error("retries=0 的监控项不应重试")
+	}
+	custom := cfg.Monitors[2].ConfirmPolicy
+	if !custom.ShouldRetry("auth_error") || custom.ShouldRetry("network_error") || custom.RetryCount() != 2 {
+		t.Errorf("覆盖 sub_statuses = %+v", custom)
+	}
+
+	for _, bad := range []ConfirmConfig{
+		{Retries: intPtr(MaxConfirmRetries + 1)},
+		{Delay: "soon"},
+		{SubStatuses: []string{"timeout"}},
+	} {
+		invalid := AppConfig{Confirm: bad}
+		if err := invalid.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) 应返回错误", bad)
+		}
+	}
+}
+
+func TestAdaptivePolicyResolve(t *testing.T) {
+	t.Parallel()
+
+	cfg := AppConfig{
+		Adaptive:        AdaptiveConfig{IncidentInterval: "15s", BackoffInterval: "5m"},
+		ProviderBudgets: map[string]int{"a": 200},
+		Monitors: []ServiceConfig{
+			{Provider: "a", Service: "cc"},
+			{Provider: "a", Service: "cx", Adaptive: &AdaptiveConfig{IncidentInterval: "0"}},
+		},
+	}
+	if err := cfg.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	inherited := cfg.Monitors[0].AdaptivePolicy
+	if inherited.IncidentIntervalDuration != 15*time.Second || inherited.BackoffIntervalDuration != 5*time.Minute || inherited.BackoffAfterDuration != 24*time.Hour {
+		t.Errorf("继承全局策略 = %+v", inherited)
+	}
+	if cfg.Monitors[1].AdaptivePolicy.IncidentIntervalDuration != 0 {
+		t.Error("incident_interval=0 的监控项不应加速")
+	}
+	if limit, ok := cfg.ExtraProbeBudget("a"); !ok || limit != 80 {
+		t.Errorf("ExtraProbeBudget(a) = %d, %v; want 80", limit, ok)
+	}
+	if _, ok := cfg.ExtraProbeBudget("b"); ok {
+		t.Error("未配置预算的服务商不应限制")
+	}
+
+	for name, bad := range map[string]AppConfig{
+		"加速间隔大于 interval": {Adaptive: AdaptiveConfig{IncidentInterval: "2m"}},
+		"加速间隔过小":         {Adaptive: AdaptiveConfig{IncidentInterval: "1s"}},
+		"降频间隔小于 interval": {Adaptive: AdaptiveConfig{BackoffInterval: "30s"}},
+		"预算不足常规探测":       {ProviderBudgets: map[string]int{"a": 59}, Monitors: []ServiceConfig{{Provider: "a", Service: "cc"}}},
+	} {
+		if err := bad.Normalize(); err == nil {
+			t.Errorf("%s: Normalize() 应返回错误", name)
+		}
+	}
+}
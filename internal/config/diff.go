@@ -0,0 +1,295 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldChange 单个字段的变化（字段为点分路径，如 headers.Authorization；密钥类字段的值已脱敏）
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// MonitorChange 修改过的监控项及其字段变化
+type MonitorChange struct {
+	Monitor MonitorKey    `json:"monitor"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// ConfigDiff 两份配置之间的结构化差异，用于热更新日志和配置修订记录
+type ConfigDiff struct {
+	Global   []FieldChange   `json:"global,omitempty"`   // 全局配置（不含 monitors/templates）
+	Added    []MonitorKey    `json:"added,omitempty"`    // 新增的监控项
+	Removed  []MonitorKey    `json:"removed,omitempty"`  // 移除（或停用）的监控项
+	Modified []MonitorChange `json:"modified,omitempty"` // 修改过的监控项
+}
+
+const (
+	// maskedValue 脱敏后的占位值
+	maskedValue = "******"
+
+	// maxDiffValueLen 差异中单个值的最大长度（超出截断，避免 body 等长字段刷屏）
+	maxDiffValueLen = 120
+)
+
+// secretFieldHints 字段名（不区分大小写）包含这些片段时视为密钥，值一律脱敏
+var secretFieldHints = []string{"api_key", "apikey", "api-key", "token", "secret", "password", "webhook", "authorization", "cookie"}
+
+// Diff 计算从 old 到 new 的配置差异（比较的是展开模板、替换变量和规范化之后的生效配置）
+// old 为 nil 时全部监控项视为新增
+func Diff(old, new *AppConfig) *ConfigDiff {
+	diff := &ConfigDiff{}
+	if new == nil {
+		return diff
+	}
+	masker := newSecretMasker(old, new)
+
+	if old != nil {
+		diff.Global = diffFields(flattenConfig(globalPart(old)), flattenConfig(globalPart(new)), masker)
+	}
+
+	oldMonitors := make(map[MonitorKey]ServiceConfig)
+	if old != nil {
+		for _, m := range old.Monitors {
+			oldMonitors[KeyOf(m)] = m
+		}
+	}
+	newKeys := make(map[MonitorKey]bool)
+	for _, m := range new.Monitors {
+		key := KeyOf(m)
+		newKeys[key] = true
+		prev, ok := oldMonitors[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+		if changes := diffFields(flattenConfig(prev), flattenConfig(m), masker); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, MonitorChange{Monitor: key, Changes: changes})
+		}
+	}
+	if old != nil {
+		for _, m := range old.Monitors {
+			if key := KeyOf(m); !newKeys[key] {
+				diff.Removed = append(diff.Removed, key)
+			}
+		}
+	}
+	return diff
+}
+
+// Empty 是否没有任何变化
+func (d *ConfigDiff) Empty() bool {
+	return d == nil || len(d.Global)+len(d.Added)+len(d.Removed)+len(d.Modified) == 0
+}
+
+// Summary 返回一行变更摘要
+func (d *ConfigDiff) Summary() string {
+	if d.Empty() {
+		return "无变化"
+	}
+	var parts []string
+	if n := len(d.Added); n > 0 {
+		parts = append(parts, fmt.Sprintf("新增 %d 个", n))
+	}
+	if n := len(d.Removed); n > 0 {
+		parts = append(parts, fmt.Sprintf("移除 %d 个", n))
+	}
+	if n := len(d.Modified); n > 0 {
+		parts = append(parts, fmt.Sprintf("修改 %d 个", n))
+	}
+	summary := ""
+	if len(parts) > 0 {
+		summary = strings.Join(parts, "、") + "监控项"
+	}
+	if n := len(d.Global); n > 0 {
+		if summary != "" {
+			summary += "，"
+		}
+		summary += fmt.Sprintf("全局配置 %d 处变化", n)
+	}
+	return summary
+}
+
+// Lines 返回逐条变更描述（用于日志）
+func (d *ConfigDiff) Lines() []string {
+	if d.Empty() {
+		return nil
+	}
+	var lines []string
+	for _, c := range d.Global {
+		lines = append(lines, "全局 "+c.String())
+	}
+	for _, key := range d.Added {
+		lines = append(lines, "新增 "+key.String())
+	}
+	for _, key := range d.Removed {
+		lines = append(lines, "移除 "+key.String())
+	}
+	for _, m := range d.Modified {
+		for _, c := range m.Changes {
+			lines = append(lines, "修改 "+m.Monitor.String()+" "+c.String())
+		}
+	}
+	return lines
+}
+
+// String 返回 field: old → new 形式的描述
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Field, displayValue(c.Old), displayValue(c.New))
+}
+
+func displayValue(v string) string {
+	if v == "" {
+		return "（空）"
+	}
+	return v
+}
+
+// JSON 返回差异的 JSON 编码（用于保存配置修订记录）
+func (d *ConfigDiff) JSON() string {
+	if d == nil {
+		return ""
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// globalPart 返回去掉监控项和模板后的全局配置
+func globalPart(cfg *AppConfig) AppConfig {
+	g := *cfg
+	g.Monitors = nil
+	g.Templates = nil
+	return g
+}
+
+// flattenConfig 将配置按 YAML 字段名展开为 点分路径 -> 值（列表整体作为一个值，yaml:"-" 的内部字段不参与比较）
+func flattenConfig(v any) map[string]string {
+	out := make(map[string]string)
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return out
+	}
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return out
+	}
+	flattenInto(out, "", tree)
+	return out
+}
+
+func flattenInto(out map[string]string, prefix string, v any) {
+	switch t := v.(type) {
+	case nil:
+	case map[string]any:
+		for k, child := range t {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flattenInto(out, path, child)
+		}
+	case []any:
+		if len(t) == 0 {
+			return
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			out[prefix] = fmt.Sprint(t)
+			return
+		}
+		out[prefix] = string(data)
+	default:
+		out[prefix] = fmt.Sprint(t)
+	}
+}
+
+// diffFields 比较两组展开后的字段，按字段名排序返回变化（值已脱敏和截断）
+func diffFields(old, new map[string]string, masker *secretMasker) []FieldChange {
+	fields := make(map[string]bool)
+	for k := range old {
+		fields[k] = true
+	}
+	for k := range new {
+		fields[k] = true
+	}
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		if old[k] != new[k] {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]FieldChange, 0, len(names))
+	for _, name := range names {
+		changes = append(changes, FieldChange{
+			Field: name,
+			Old:   masker.mask(name, old[name]),
+			New:   masker.mask(name, new[name]),
+		})
+	}
+	return changes
+}
+
+// secretMasker 对差异中的密钥脱敏：字段名像密钥，或值中包含任一已知密钥（如 headers 中的 Bearer <api_key>、${VAR} 和 file: 解析出的值）
+type secretMasker struct {
+	secrets []string
+}
+
+func newSecretMasker(configs ...*AppConfig) *secretMasker {
+	m := &secretMasker{}
+	for _, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+		m.add(cfg.Admin.Token, cfg.Storage.Postgres.Password, cfg.Notifier.WeCom.WebhookURL, cfg.Agent.Secret)
+		for _, a := range cfg.Ingest.Agents {
+			m.add(a.Secret)
+		}
+		for _, r := range cfg.Notifier.Routes {
+			m.add(r.WeCom.WebhookURL)
+		}
+		for _, mon := range cfg.Monitors {
+			m.add(mon.APIKey)
+		}
+		m.add(cfg.ResolvedSecrets...)
+	}
+	return m
+}
+
+func (m *secretMasker) add(secrets ...string) {
+	for _, s := range secrets {
+		// 过短的值（如占位的 "x"）容易误伤普通字段，不参与匹配
+		if len(s) >= 6 {
+			m.secrets = append(m.secrets, s)
+		}
+	}
+}
+
+func (m *secretMasker) mask(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	last := strings.ToLower(field[strings.LastIndex(field, ".")+1:])
+	for _, hint := range secretFieldHints {
+		if strings.Contains(last, hint) {
+			return maskedValue
+		}
+	}
+	for _, s := range m.secrets {
+		value = strings.ReplaceAll(value, s, maskedValue)
+	}
+	if r := []rune(value); len(r) > maxDiffValueLen {
+		value = string(r[:maxDiffValueLen]) + "…"
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	oldCfg := &AppConfig{
+		Interval: "1m",
+		Admin:    AdminConfig{Token: "admin-token-old"},
+		Monitors: []ServiceConfig{
+			{Provider: "A", Service: "cc", URL: "https://a.example.com", Method: "POST", APIKey: "sk-old-secret",
+				Headers: map[string]string{"Authorization": "Bearer sk-old-secret", "X-Trace": "1"},
+				Body:    `{"key":"sk-old-secret"}`},
+			{Provider: "B", Service: "cc", URL: "https://b.example.com", Method: "GET"},
+		},
+	}
+	newCfg := &AppConfig{
+		Interval: "30s",
+		Admin:    AdminConfig{Token: "admin-token-new"},
+		Monitors: []ServiceConfig{
+			{Provider: "A", Service: "cc", URL: "https://a2.example.com", Method: "POST", APIKey: "sk-new-secret",
+				Headers: map[string]string{"Authorization": "Bearer sk-new-secret", "X-Trace": "1"},
+				Body:    `{"key":"sk-new-secret"}`},
+			{Provider: "C", Service: "cx", Channel: "vip", URL: "https://c.example.com", Method: "GET"},
+		},
+	}
+
+	diff := Diff(oldCfg, newCfg)
+	if got := diff.Summary(); got != "新增 1 个、移除 1 个、修改 1 个监控项，全局配置 2 处变化" {
+		t.Fatalf("Summary = %q", got)
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != (MonitorKey{Provider: "C", Service: "cx", Channel: "vip"}) {
+		t.Errorf("Added = %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Provider != "B" {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+
+	want := []FieldChange{
+		{Field: "admin.token", Old: maskedValue, New: maskedValue},
+		{Field: "interval", Old: "1m", New: "30s"},
+	}
+	if len(diff.Global) != len(want) {
+		t.Fatalf("Global = %+v, want %+v", diff.Global, want)
+	}
+	for i := range want {
+		if diff.Global[i] != want[i] {
+			t.Errorf("Global[%d] = %+v, want %+v", i, diff.Global[i], want[i])
+		}
+	}
+
+	if len(diff.Modified) != 1 {
+		t.Fatalf("Modified = %+v", diff.Modified)
+	}
+	fields := make(map[string]FieldChange)
+	for _, c := range diff.Modified[0].Changes {
+		fields[c.Field] = c
+	}
+	if _, ok := fields["headers.X-Trace"]; ok {
+		t.Errorf("未变化的字段不应出现在差异中: %+v", fields)
+	}
+	if c := fields["url"]; c.Old != "https://a.example.com" || c.New != "https://a2.example.com" {
+		t.Errorf("url change = %+v", c)
+	}
+	if c := fields["body"]; c.Old != `{"key":"`+maskedValue+`"}` || c.New != `{"key":"`+maskedValue+`"}` {
+		t.Errorf("body 中的 api_key 应脱敏: %+v", c)
+	}
+	for _, line := range diff.Lines() {
+		if strings.Contains(line, "secret") || strings.Contains(line, "admin-token") {
+			t.Errorf("差异日志泄露密钥: %s", line)
+		}
+	}
+	if strings.Contains(diff.JSON(), "secret") {
+		t.Errorf("差异 JSON 泄露密钥: %s", diff.JSON())
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	t.Parallel()
+
+	cfg := &AppConfig{Monitors: []ServiceConfig{{Provider: "A", Service: "cc", URL: "https://a.example.com"}}}
+	if diff := Diff(cfg, cfg.Clone()); !diff.Empty() || diff.Summary() != "无变化" {
+		t.Fatalf("相同配置应无差异: %+v", diff)
+	}
+	if diff := Diff(nil, cfg); len(diff.Added) != 1 || len(diff.Global) != 0 {
+		t.Fatalf("旧配置为空时全部监控项应视为新增: %+v", diff)
+	}
+}
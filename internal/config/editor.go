@@ -0,0 +1,415 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrMonitorNotFound 监控项不存在
+	ErrMonitorNotFound = errors.New("监控项不存在")
+	// ErrMonitorExists 监控项已存在（provider + service + channel 重复）
+	ErrMonitorExists = errors.New("监控项已存在")
+	// ErrInvalidConfig 修改后的配置未通过校验（配置文件保持不变）
+	ErrInvalidConfig = errors.New("配置无效")
+	// ErrMonitorReadOnly 监控项定义在 include 文件中（管理 API 只修改主配置文件）
+	ErrMonitorReadOnly = errors.New("监控项定义在 include 文件中，请直接编辑该文件")
+)
+
+// MonitorKey 监控项唯一标识
+type MonitorKey struct {
+	Provider string `json:"provider"`
+	Service  string `json:"service"`
+	Channel  string `json:"channel"`
+}
+
+// String 返回 provider/service/channel 形式的标识
+func (k MonitorKey) String() string {
+	return k.Provider + "/" + k.Service + "/" + k.Channel
+}
+
+// KeyOf 返回监控项的唯一标识
+func KeyOf(m ServiceConfig) MonitorKey {
+	return MonitorKey{Provider: m.Provider, Service: m.Service, Channel: m.Channel}
+}
+
+// Editor 配置文件编辑器（供管理 API 在运行时增删改监控项）
+// 基于 yaml.Node 修改文件，尽量保留原有注释和字段顺序；
+// 写入前执行与 Load 相同的校验/规范化流程，写入采用临时文件 + rename 保证原子性
+// 只修改主配置文件，include 目录中的监控项只读（新增的监控项写入主配置文件）
+type Editor struct {
+	loader   *Loader
+	filename string
+	mu       sync.Mutex // 串行化 读取-修改-写入
+}
+
+// NewEditor 创建配置文件编辑器
+func NewEditor(loader *Loader, filename string) *Editor {
+	return &Editor{loader: loader, filename: filename}
+}
+
+// ListMonitors 返回配置文件（含 include 文件）中的全部监控项（展开模板后的原始值，包含已停用的监控项）
+func (e *Editor) ListMonitors() ([]ServiceConfig, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, err := os.ReadFile(e.filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	includes, err := loadIncludes(&doc, e.filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := expandTemplates(&doc, includes); err != nil {
+		return nil, fmt.Errorf("展开监控项模板失败: %w", err)
+	}
+
+	var raw struct {
+		Monitors []ServiceConfig `yaml:"monitors"`
+	}
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+	}
+	return raw.Monitors, nil
+}
+
+// CreateMonitor 新增监控项，返回生效后的配置
+func (e *Editor) CreateMonitor(m ServiceConfig) (*AppConfig, error) {
+	return e.modify(func(s *editSession) error {
+		if _, err := s.find(KeyOf(m)); err == nil || errors.Is(err, ErrMonitorReadOnly) {
+			return fmt.Errorf("%w: %s", ErrMonitorExists, KeyOf(m))
+		} else if !errors.Is(err, ErrMonitorNotFound) {
+			return err
+		}
+
+		node, err := encodeMonitorNode(m, s.templates)
+		if err != nil {
+			return err
+		}
+		s.seq.Content = append(s.seq.Content, node)
+		return nil
+	})
+}
+
+// UpdateMonitor 整体替换监控项（api_key 为空时保留原值），返回生效后的配置
+func (e *Editor) UpdateMonitor(key MonitorKey, m ServiceConfig) (*AppConfig, error) {
+	return e.modify(func(s *editSession) error {
+		idx, err := s.find(key)
+		if err != nil {
+			return err
+		}
+
+		// 标识变更时检查是否与其他监控项冲突
+		if newKey := KeyOf(m); newKey != key {
+			if _, err := s.find(newKey); err == nil || errors.Is(err, ErrMonitorReadOnly) {
+				return fmt.Errorf("%w: %s", ErrMonitorExists, newKey)
+			}
+		}
+
+		// 列表接口不返回 api_key，更新时未提供则沿用原值（可能继承自模板）
+		if m.APIKey == "" {
+			old, err := s.decode(s.seq.Content[idx], idx, "")
+			if err != nil {
+				return err
+			}
+			m.APIKey = old.APIKey
+		}
+
+		node, err := encodeMonitorNode(m, s.templates)
+		if err != nil {
+			return err
+		}
+		mergeMappingNode(s.seq.Content[idx], node)
+		return nil
+	})
+}
+
+// SetMonitorDisabled 停用或启用监控项，返回生效后的配置
+func (e *Editor) SetMonitorDisabled(key MonitorKey, disabled bool) (*AppConfig, error) {
+	return e.modify(func(s *editSession) error {
+		idx, err := s.find(key)
+		if err != nil {
+			return err
+		}
+
+		item := s.seq.Content[idx]
+		removeMappingKey(item, "disabled")
+		if current, err := s.decode(item, idx, ""); err != nil {
+			return err
+		} else if current.Disabled == disabled {
+			return nil // 模板中的 disabled 已满足要求
+		}
+
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(disabled)}
+		if i := mappingKeyIndex(item, "disabled"); i >= 0 {
+			item.Content[i+1] = value
+		} else {
+			item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "disabled"}, value)
+		}
+		return nil
+	})
+}
+
+// DeleteMonitor 删除监控项，返回生效后的配置
+func (e *Editor) DeleteMonitor(key MonitorKey) (*AppConfig, error) {
+	return e.modify(func(s *editSession) error {
+		idx, err := s.find(key)
+		if err != nil {
+			return err
+		}
+		s.seq.Content = append(s.seq.Content[:idx], s.seq.Content[idx+1:]...)
+		return nil
+	})
+}
+
+// modify 读取配置文件 → 修改 monitors 节点 → 校验 → 原子写回
+func (e *Editor) modify(mutate func(s *editSession) error) (*AppConfig, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	data, err := os.ReadFile(e.filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置文件根节点必须是映射")
+	}
+	root := doc.Content[0]
+
+	var seq *yaml.Node
+	if i := mappingKeyIndex(root, "monitors"); i >= 0 {
+		seq = root.Content[i+1]
+	} else {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "monitors"}, seq)
+	}
+	if seq.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("配置文件中 monitors 必须是列表")
+	}
+
+	includes, err := loadIncludes(&doc, e.filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	templates, err := newTemplateSet(root, includes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if err := mutate(&editSession{seq: seq, templates: templates, includes: includes}); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	// 与文件加载走相同的流程，校验失败则不写入
+	cfg, err := e.loader.parse(buf.Bytes(), e.filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if err := writeFileAtomic(e.filename, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	e.loader.setCurrent(cfg)
+	return cfg, nil
+}
+
+// editSession 一次修改操作读取到的配置：主配置文件的 monitors 节点、全部模板和 include 文件
+type editSession struct {
+	seq       *yaml.Node
+	templates *templateSet
+	includes  []includeDoc
+}
+
+// find 在主配置文件的 monitors 中查找监控项（按展开模板后的标识匹配），返回其下标
+// 监控项定义在 include 文件中时返回 ErrMonitorReadOnly
+func (s *editSession) find(key MonitorKey) (int, error) {
+	for i, item := range s.seq.Content {
+		m, err := s.decode(item, i, "")
+		if err != nil {
+			return -1, err
+		}
+		if KeyOf(m) == key {
+			return i, nil
+		}
+	}
+
+	index := len(s.seq.Content)
+	for _, inc := range s.includes {
+		for _, item := range inc.monitors() {
+			m, err := s.decode(item, index, inc.name)
+			if err != nil {
+				return -1, err
+			}
+			if KeyOf(m) == key {
+				return -1, fmt.Errorf("%w: %s（%s）", ErrMonitorReadOnly, key, inc.name)
+			}
+			index++
+		}
+	}
+	return -1, fmt.Errorf("%w: %s", ErrMonitorNotFound, key)
+}
+
+// decode 展开模板后解码监控项节点
+func (s *editSession) decode(item *yaml.Node, index int, file string) (ServiceConfig, error) {
+	var m ServiceConfig
+	node, _, err := s.templates.expandMonitor(item, index, file)
+	if err != nil {
+		return m, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := node.Decode(&m); err != nil {
+		return m, fmt.Errorf("解析 monitor[%d] 失败: %w", index, err)
+	}
+	return m, nil
+}
+
+// encodeMonitorNode 将监控项编码为 YAML 节点（省略空字段，保持配置文件简洁）
+// 监控项使用 extends 时只保留与模板不同的字段
+func encodeMonitorNode(m ServiceConfig, templates *templateSet) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(m); err != nil {
+		return nil, fmt.Errorf("编码监控项失败: %w", err)
+	}
+
+	pruned := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if isEmptyNode(value) {
+			continue
+		}
+		pruned = append(pruned, node.Content[i], value)
+	}
+	node.Content = pruned
+
+	if m.Extends != "" {
+		base, err := templates.resolve(m.Extends, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+		pruneInherited(&node, base.node)
+	}
+	return &node, nil
+}
+
+func isEmptyNode(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Tag == "!!null" || (n.Tag == "!!str" && n.Value == "") || (n.Tag == "!!bool" && n.Value == "false")
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(n.Content) == 0
+	}
+	return false
+}
+
+// mergeMappingNode 用 src 的字段替换 dst，保留 dst 中已有字段的注释与顺序
+func mergeMappingNode(dst, src *yaml.Node) {
+	keep := make(map[string]bool, len(src.Content)/2)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		keep[key.Value] = true
+
+		if j := mappingKeyIndex(dst, key.Value); j >= 0 {
+			old := dst.Content[j+1]
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			dst.Content[j+1] = value
+		} else {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+
+	merged := dst.Content[:0]
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		if keep[dst.Content[i].Value] {
+			merged = append(merged, dst.Content[i], dst.Content[i+1])
+		}
+	}
+	dst.Content = merged
+}
+
+// mappingKeyIndex 返回映射节点中 key 所在下标（值位于下标 +1），不存在时返回 -1
+func mappingKeyIndex(n *yaml.Node, key string) int {
+	if n.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeMappingKey(n *yaml.Node, key string) {
+	if i := mappingKeyIndex(n, key); i >= 0 {
+		n.Content = append(n.Content[:i], n.Content[i+2:]...)
+	}
+}
+
+// writeFileAtomic 先写入同目录临时文件再 rename，避免进程中断导致配置文件损坏
+func writeFileAtomic(filename string, data []byte) error {
+	// 配置文件为符号链接时写入链接目标，避免把链接替换成普通文件
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时配置文件失败: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // rename 成功后为空操作
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时配置文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时配置文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入临时配置文件失败: %w", err)
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("设置配置文件权限失败: %w", err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("替换配置文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editorTestConfig = `# 全局配置
+interval: "1m"
+
+monitors:
+  # 主力线路
+  - provider: "Demo"
+    service: "cc"
+    category: "public"
+    sponsor: "Alice"
+    url: "https://demo.example.com/v1/messages"
+    method: "POST" # 保留的行内注释
+    api_key: "sk-demo"
+`
+
+func newTestEditor(t *testing.T) (*Editor, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(editorTestConfig), 0o600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	return NewEditor(NewLoader(), path), path
+}
+
+func TestEditorLifecycle(t *testing.T) {
+	t.Parallel()
+
+	editor, path := newTestEditor(t)
+	demo := MonitorKey{Provider: "Demo", Service: "cc"}
+
+	// 新增
+	cfg, err := editor.CreateMonitor(ServiceConfig{
+		Provider: "Other", Service: "cx", Category: "commercial", Sponsor: "Bob",
+		URL: "https://other.example.com", Method: "GET",
+	})
+	if err != nil {
+		t.Fatalf("新增失败: %v", err)
+	}
+	if len(cfg.Monitors) != 2 {
+		t.Fatalf("新增后监控项数量 = %d, want 2", len(cfg.Monitors))
+	}
+
+	// 重复新增
+	if _, err := editor.CreateMonitor(ServiceConfig{Provider: "Other", Service: "cx"}); !errors.Is(err, ErrMonitorExists) {
+		t.Fatalf("重复新增应返回 ErrMonitorExists，got=%v", err)
+	}
+
+	// 更新（未提供 api_key 时保留原值）
+	if _, err := editor.UpdateMonitor(demo, ServiceConfig{
+		Provider: "Demo", Service: "cc", Category: "public", Sponsor: "Carol",
+		URL: "https://demo.example.com/v1/messages", Method: "POST",
+	}); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+
+	// 停用：保留在文件中，但不出现在运行时配置
+	cfg, err = editor.SetMonitorDisabled(demo, true)
+	if err != nil {
+		t.Fatalf("停用失败: %v", err)
+	}
+	if len(cfg.Monitors) != 1 || cfg.Monitors[0].Provider != "Other" {
+		t.Fatalf("停用后运行时配置应只剩 Other，got=%+v", cfg.Monitors)
+	}
+	listed, err := editor.ListMonitors()
+	if err != nil || len(listed) != 2 || !listed[0].Disabled {
+		t.Fatalf("列表应包含已停用的监控项，got=%+v err=%v", listed, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, want := range []string{"# 全局配置", "# 主力线路", "# 保留的行内注释", "sponsor: Carol", "api_key: sk-demo", "disabled: true"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("配置文件中缺少 %q:\n%s", want, content)
+		}
+	}
+
+	// 删除
+	if _, err := editor.DeleteMonitor(MonitorKey{Provider: "Other", Service: "cx"}); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err := editor.DeleteMonitor(MonitorKey{Provider: "Other", Service: "cx"}); !errors.Is(err, ErrMonitorNotFound) {
+		t.Fatalf("删除不存在的监控项应返回 ErrMonitorNotFound，got=%v", err)
+	}
+}
+
+func TestEditorRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	editor, path := newTestEditor(t)
+	before, _ := os.ReadFile(path)
+
+	// 缺少 url/method/category/sponsor，应被校验拦截
+	_, err := editor.CreateMonitor(ServiceConfig{Provider: "Bad", Service: "cc"})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("期望 ErrInvalidConfig，got=%v", err)
+	}
+
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Fatalf("校验失败时不应修改配置文件")
+	}
+}
+
+// TestWatcherSkipsAdminWrite 验证管理 API 写入后触发的文件事件不会重复应用同一份配置
+func TestWatcherSkipsAdminWrite(t *testing.T) {
+	t.Parallel()
+
+	editor, path := newTestEditor(t)
+	if _, err := editor.loader.Load(path); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	reloads := 0
+	w := &Watcher{loader: editor.loader, filename: path, onReload: func(*AppConfig) { reloads++ }}
+
+	if _, err := editor.SetMonitorDisabled(MonitorKey{Provider: "Demo", Service: "cc"}, true); err != nil {
+		t.Fatalf("停用失败: %v", err)
+	}
+	w.reload()
+	if reloads != 0 {
+		t.Fatalf("管理 API 写入后重载了 %d 次，want 0", reloads)
+	}
+
+	// 外部修改文件仍然正常重载
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), `"1m"`, `"2m"`, 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.reload()
+	if reloads != 1 {
+		t.Errorf("外部修改后重载了 %d 次，want 1", reloads)
+	}
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxExtendsDepth 模板继承链的最大深度（防止误配置导致过深的递归）
+const maxExtendsDepth = 10
+
+// templateSet 配置文件顶层 templates 中的监控项模板
+// 模板是不完整的监控项，可通过 extends 继承另一个模板；监控项通过 extends 引用模板，
+// 展开时按继承链深度合并：映射（如 headers、confirm）逐键合并，其他字段由子级覆盖
+type templateSet struct {
+	nodes    map[string]*yaml.Node // 模板名 -> 原始映射节点
+	files    map[string]string     // 模板名 -> 所在的 include 文件（主配置文件为空）
+	resolved map[string]*expanded  // 模板名 -> 展开后的结果（缓存）
+}
+
+// expanded 展开 extends 后的映射节点
+type expanded struct {
+	node   *yaml.Node
+	origin map[string]string // 顶层字段 -> 提供该字段值的模板（如 "templates.base（第 3 行）"），用于错误定位
+}
+
+// newTemplateSet 读取主配置文件和 include 文件中的 templates（都不存在时返回空集合）
+func newTemplateSet(root *yaml.Node, includes []includeDoc) (*templateSet, error) {
+	set := &templateSet{nodes: map[string]*yaml.Node{}, files: map[string]string{}, resolved: map[string]*expanded{}}
+	if err := set.add(root, ""); err != nil {
+		return nil, err
+	}
+	for _, inc := range includes {
+		if err := set.add(inc.root, inc.name); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// add 读取根节点中的 templates，file 为所在的 include 文件（主配置文件为空）
+func (t *templateSet) add(root *yaml.Node, file string) error {
+	i := mappingKeyIndex(root, "templates")
+	if i < 0 {
+		return nil
+	}
+	node := resolveAlias(root.Content[i+1])
+	if node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("templates（%s）必须是映射", location(file, node.Line))
+	}
+
+	for j := 0; j+1 < len(node.Content); j += 2 {
+		name, value := node.Content[j].Value, resolveAlias(node.Content[j+1])
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("templates（%s）: 模板名不能为空", location(file, node.Content[j].Line))
+		}
+		if value.Kind != yaml.MappingNode {
+			return fmt.Errorf("templates.%s（%s）必须是映射", name, location(file, value.Line))
+		}
+		if prev, ok := t.nodes[name]; ok {
+			return fmt.Errorf("templates.%s（%s）与（%s）重名", name, location(file, value.Line), location(t.files[name], prev.Line))
+		}
+		t.nodes[name] = value
+		t.files[name] = file
+	}
+	return nil
+}
+
+// resolve 返回模板沿继承链展开后的结果
+func (t *templateSet) resolve(name string, chain []string) (*expanded, error) {
+	if r, ok := t.resolved[name]; ok {
+		return r, nil
+	}
+	for _, c := range chain {
+		if c == name {
+			return nil, fmt.Errorf("extends 循环引用: %s", strings.Join(append(chain, name), " → "))
+		}
+	}
+	if len(chain) >= maxExtendsDepth {
+		return nil, fmt.Errorf("extends 继承链超过 %d 层: %s", maxExtendsDepth, strings.Join(append(chain, name), " → "))
+	}
+
+	node, ok := t.nodes[name]
+	if !ok {
+		return nil, fmt.Errorf("模板 '%s' 不存在", name)
+	}
+
+	where := fmt.Sprintf("templates.%s（%s）", name, location(t.files[name], node.Line))
+	r, err := t.expand(node, where, append(chain, name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", where, err)
+	}
+	t.resolved[name] = r
+	return r, nil
+}
+
+// expand 展开映射节点的 extends；where 为该节点自身的来源描述（记录为其直接定义的字段的来源）
+// 返回的节点保留 extends 字段，便于校验报错和编辑器回写时识别继承关系
+func (t *templateSet) expand(node *yaml.Node, where string, chain []string) (*expanded, error) {
+	parent, err := extendsOf(node)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make(map[string]string, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		own[node.Content[i].Value] = where
+	}
+	if parent == "" {
+		return &expanded{node: node, origin: own}, nil
+	}
+
+	base, err := t.resolve(parent, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := make(map[string]string, len(base.origin)+len(own))
+	for field, from := range base.origin {
+		origin[field] = from
+	}
+	for field, from := range own {
+		origin[field] = from
+	}
+	return &expanded{node: mergeNodes(base.node, node), origin: origin}, nil
+}
+
+// monitorSource 监控项在配置文件中的位置，用于校验错误定位
+type monitorSource struct {
+	file      string            // 所在的 include 文件（主配置文件为空）
+	line      int               // 监控项所在行号（0 表示不是从配置文件加载）
+	inherited map[string]string // 继承自模板的顶层字段 -> 模板来源
+}
+
+// expandMonitor 展开单个监控项的 extends，未使用 extends 时原样返回
+// file 为监控项所在的 include 文件（主配置文件为空）
+func (t *templateSet) expandMonitor(item *yaml.Node, index int, file string) (*yaml.Node, monitorSource, error) {
+	item = resolveAlias(item)
+	src := monitorSource{file: file, line: item.Line}
+	if item.Kind != yaml.MappingNode {
+		return item, src, nil
+	}
+
+	where := fmt.Sprintf("monitor[%d]（%s）", index, location(file, item.Line))
+	r, err := t.expand(item, where, nil)
+	if err != nil {
+		return nil, src, fmt.Errorf("%s: %w", where, err)
+	}
+	for field, from := range r.origin {
+		if from != where {
+			if src.inherited == nil {
+				src.inherited = make(map[string]string)
+			}
+			src.inherited[field] = from
+		}
+	}
+	return r.node, src, nil
+}
+
+// expandTemplates 展开配置文件中所有监控项的 extends（在解码为 AppConfig 之前执行）
+// include 文件中的监控项按文件顺序追加到主配置文件的 monitors 之后；
+// 返回每个监控项的来源，用于在校验错误中指出所在文件、行和继承自哪个模板
+func expandTemplates(doc *yaml.Node, includes []includeDoc) ([]monitorSource, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil // 交由后续解码报错
+	}
+	root := doc.Content[0]
+
+	set, err := newTemplateSet(root, includes)
+	if err != nil {
+		return nil, err
+	}
+
+	var seq *yaml.Node
+	if i := mappingKeyIndex(root, "monitors"); i >= 0 {
+		seq = resolveAlias(root.Content[i+1])
+	} else if len(includes) > 0 {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "monitors"}, seq)
+	}
+	if seq == nil || (seq.Kind != yaml.SequenceNode && seq.Tag != "!!null") {
+		return nil, nil
+	}
+
+	var sources []monitorSource
+	expandAll := func(items []*yaml.Node, file string) error {
+		for _, item := range items {
+			node, src, err := set.expandMonitor(item, len(sources), file)
+			if err != nil {
+				return err
+			}
+			seq.Content[len(sources)] = node
+			sources = append(sources, src)
+		}
+		return nil
+	}
+
+	own := seq.Content
+	for _, inc := range includes {
+		seq.Content = append(seq.Content, inc.monitors()...)
+	}
+	seq.Kind, seq.Tag = yaml.SequenceNode, "!!seq"
+	if err := expandAll(own, ""); err != nil {
+		return nil, err
+	}
+	for _, inc := range includes {
+		if err := expandAll(inc.monitors(), inc.name); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// location 返回错误定位中的位置描述，如 "第 3 行"、"conf.d/88code.yaml 第 3 行"
+func location(file string, line int) string {
+	if file == "" {
+		return fmt.Sprintf("第 %d 行", line)
+	}
+	return fmt.Sprintf("%s 第 %d 行", file, line)
+}
+
+// extendsOf 返回映射节点 extends 字段引用的模板名（未设置时为空）
+func extendsOf(node *yaml.Node) (string, error) {
+	i := mappingKeyIndex(node, "extends")
+	if i < 0 {
+		return "", nil
+	}
+	value := resolveAlias(node.Content[i+1])
+	if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+		return "", fmt.Errorf("extends（第 %d 行）必须是模板名", value.Line)
+	}
+	return strings.TrimSpace(value.Value), nil
+}
+
+// mergeNodes 深度合并：两侧都是映射时逐键合并（子级覆盖父级），否则子级整体替换父级
+// 返回新节点，不修改输入
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	base, override = resolveAlias(base), resolveAlias(override)
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+
+	merged := *override
+	merged.Content = make([]*yaml.Node, 0, len(base.Content)+len(override.Content))
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key, value := base.Content[i], base.Content[i+1]
+		if key.Value == "extends" {
+			continue // 继承关系只保留子级自己的 extends
+		}
+		if j := mappingKeyIndex(override, key.Value); j >= 0 {
+			value = mergeNodes(value, override.Content[j+1])
+		}
+		merged.Content = append(merged.Content, key, value)
+	}
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		if mappingKeyIndex(base, override.Content[i].Value) < 0 || override.Content[i].Value == "extends" {
+			merged.Content = append(merged.Content, override.Content[i], override.Content[i+1])
+		}
+	}
+	return &merged
+}
+
+// pruneInherited 删除 node 中与模板展开结果 base 相同的字段（映射逐键比较），
+// 编辑器回写使用 extends 的监控项时只保留与模板不同的字段
+func pruneInherited(node, base *yaml.Node) {
+	base = resolveAlias(base)
+	if node.Kind != yaml.MappingNode || base.Kind != yaml.MappingNode {
+		return
+	}
+
+	pruned := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if j := mappingKeyIndex(base, key.Value); j >= 0 && key.Value != "extends" {
+			inherited := resolveAlias(base.Content[j+1])
+			if value.Kind == yaml.MappingNode && inherited.Kind == yaml.MappingNode {
+				pruneInherited(value, inherited)
+				if len(value.Content) == 0 {
+					continue
+				}
+			} else if nodesEqual(value, inherited) {
+				continue
+			}
+		}
+		pruned = append(pruned, key, value)
+	}
+	node.Content = pruned
+}
+
+// nodesEqual 按解码后的值比较两个节点
+func nodesEqual(a, b *yaml.Node) bool {
+	var va, vb any
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const extendsTestConfig = `interval: "1m"
+
+templates:
+  base:
+    category: "public"
+    sponsor: "Alice"
+    method: "POST"
+    url: "https://base.example.com/v1/messages"
+    headers:
+      Authorization: "Bearer {{API_KEY}}"
+      X-Base: "1"
+  claude:
+    extends: base
+    service: "cc"
+    headers:
+      X-Base: "2"
+      anthropic-version: "2023-06-01"
+
+monitors:
+  - provider: "Demo"
+    extends: claude
+    api_key: "sk-demo"
+    headers:
+      X-Extra: "yes"
+  - provider: "Other"
+    extends: base
+    service: "cx"
+    method: "GET"
+`
+
+func TestExpandTemplates(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := NewLoader().parse([]byte(extendsTestConfig), "config.yaml")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(cfg.Monitors) != 2 {
+		t.Fatalf("监控项数量 = %d, want 2", len(cfg.Monitors))
+	}
+
+	demo := cfg.Monitors[0]
+	if demo.Service != "cc" || demo.Category != "public" || demo.Method != "POST" || demo.Extends != "claude" {
+		t.Fatalf("继承链字段未展开: %+v", demo)
+	}
+	wantHeaders := map[string]string{
+		"Authorization":     "Bearer sk-demo",
+		"X-Base":            "2",
+		"anthropic-version": "2023-06-01",
+		"X-Extra":           "yes",
+	}
+	for k, v := range wantHeaders {
+		if demo.Headers[k] != v {
+			t.Errorf("headers[%s] = %q, want %q", k, demo.Headers[k], v)
+		}
+	}
+	if len(demo.Headers) != len(wantHeaders) {
+		t.Errorf("headers = %v, want %v", demo.Headers, wantHeaders)
+	}
+
+	other := cfg.Monitors[1]
+	if other.Method != "GET" || other.Service != "cx" || other.Headers["X-Base"] != "1" {
+		t.Fatalf("子级字段应覆盖模板: %+v", other)
+	}
+}
+
+func TestExpandTemplatesErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name: "unknown template",
+			config: `templates: {}
+monitors:
+  - provider: "Demo"
+    extends: missing
+`,
+			want: "模板 'missing' 不存在",
+		},
+		{
+			name: "cycle",
+			config: `templates:
+  a: {extends: b}
+  b: {extends: a}
+monitors:
+  - provider: "Demo"
+    extends: a
+`,
+			want: "循环引用",
+		},
+		{
+			name: "inherited field error",
+			config: `templates:
+  base:
+    category: "public"
+    sponsor: "Alice"
+    method: "FETCH"
+    url: "https://base.example.com"
+monitors:
+  - provider: "Demo"
+    service: "cc"
+    extends: base
+`,
+			want: "method 继承自 templates.base（第 3 行）",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLoader().parse([]byte(tc.config), "config.yaml")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want contains %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEditorKeepsExtends(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(extendsTestConfig), 0o600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	editor := NewEditor(NewLoader(), path)
+
+	monitors, err := editor.ListMonitors()
+	if err != nil {
+		t.Fatalf("列出监控项失败: %v", err)
+	}
+	demo := monitors[0]
+	if demo.Extends != "claude" || demo.Sponsor != "Alice" {
+		t.Fatalf("ListMonitors 应返回展开后的值: %+v", demo)
+	}
+
+	// 只修改 sponsor，回写时只保留与模板不同的字段
+	demo.Sponsor = "Bob"
+	demo.APIKey = ""
+	if _, err := editor.UpdateMonitor(KeyOf(demo), demo); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	text := string(data)
+	section := text[strings.Index(text, "monitors:"):strings.Index(text, "Other")]
+	for _, want := range []string{"extends: claude", "sponsor: Bob", "api_key: sk-demo", "X-Extra: \"yes\""} {
+		if !strings.Contains(section, want) {
+			t.Errorf("监控项缺少 %q:\n%s", want, section)
+		}
+	}
+	for _, inherited := range []string{"category:", "anthropic-version", "url:"} {
+		if strings.Contains(section, inherited) {
+			t.Errorf("继承的字段 %q 不应写入监控项:\n%s", inherited, section)
+		}
+	}
+
+	// 停用后重新启用，模板未设置 disabled 时不写入该字段
+	if _, err := editor.SetMonitorDisabled(KeyOf(demo), true); err != nil {
+		t.Fatalf("停用失败: %v", err)
+	}
+	if _, err := editor.SetMonitorDisabled(KeyOf(demo), false); err != nil {
+		t.Fatalf("启用失败: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "disabled") {
+		t.Errorf("启用后不应保留 disabled 字段:\n%s", data)
+	}
+}
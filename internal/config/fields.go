@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownFieldMode 配置文件中未知字段（如拼写错误的 sucess_contains）的处理方式
+type UnknownFieldMode string
+
+const (
+	UnknownFieldsStrict UnknownFieldMode = "strict" // 默认：未知字段视为配置错误
+	UnknownFieldsWarn   UnknownFieldMode = "warn"   // 仅输出警告（用于新旧版本混合部署时的过渡）
+)
+
+const (
+	// unknownFieldModeEnv 设置默认处理方式的环境变量
+	unknownFieldModeEnv = "MONITOR_CONFIG_UNKNOWN_FIELDS"
+
+	// extensionPrefix 以此开头的顶层字段不做检查，供定义 YAML 锚点使用（与 docker compose 的约定一致）
+	extensionPrefix = "x-"
+)
+
+// ParseUnknownFieldMode 解析未知字段处理方式（空字符串表示默认的 strict）
+func ParseUnknownFieldMode(s string) (UnknownFieldMode, error) {
+	switch mode := UnknownFieldMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return UnknownFieldsStrict, nil
+	case UnknownFieldsStrict, UnknownFieldsWarn:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("未知字段处理方式 '%s' 无效，必须是 strict 或 warn", s)
+	}
+}
+
+// defaultUnknownFieldMode 返回环境变量 MONITOR_CONFIG_UNKNOWN_FIELDS 指定的处理方式（无效值按 strict 处理）
+func defaultUnknownFieldMode() UnknownFieldMode {
+	mode, err := ParseUnknownFieldMode(os.Getenv(unknownFieldModeEnv))
+	if err != nil {
+		log.Printf("[Config] 警告: %s: %v，使用 strict", unknownFieldModeEnv, err)
+		return UnknownFieldsStrict
+	}
+	return mode
+}
+
+// yamlField 结构体中可出现在配置文件里的字段
+type yamlField struct {
+	name string
+	typ  reflect.Type
+}
+
+// yamlFields 按 yaml 标签列出结构体字段（跳过 yaml:"-" 和未导出字段，展开 inline 字段）
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			fields = append(fields, yamlFields(derefType(f.Type))...)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name) // 与 yaml.v3 的默认字段名一致
+		}
+		fields = append(fields, yamlField{name: name, typ: f.Type})
+	}
+	return fields
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// checkUnknownFields 按 AppConfig 的字段定义检查节点树，返回全部未知字段（含位置和拼写建议）
+// 类型错误（如 interval: [1]）不在这里检查，由解码报告
+func checkUnknownFields(node *yaml.Node, file string) []error {
+	var errs []error
+	walkUnknownFields(node, reflect.TypeOf(AppConfig{}), "", file, &errs)
+	return errs
+}
+
+func walkUnknownFields(node *yaml.Node, t reflect.Type, path, file string, errs *[]error) {
+	node = resolveAlias(node)
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			walkUnknownFields(child, t, path, file, errs)
+		}
+		return
+	}
+
+	switch t = derefType(t); t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" { // 合并键：被合并的映射按同一类型检查
+				merged := resolveAlias(value)
+				if merged.Kind == yaml.SequenceNode {
+					for _, item := range merged.Content {
+						walkUnknownFields(item, t, path, file, errs)
+					}
+				} else {
+					walkUnknownFields(merged, t, path, file, errs)
+				}
+				continue
+			}
+			if path == "" && strings.HasPrefix(key.Value, extensionPrefix) {
+				continue // 扩展字段（如 x-common: &common），仅用于定义 YAML 锚点
+			}
+			field, ok := findField(fields, key.Value)
+			if !ok {
+				*errs = append(*errs, unknownFieldError(fields, key, path, file))
+				continue
+			}
+			walkUnknownFields(value, field.typ, joinFieldPath(path, key.Value), file, errs)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkUnknownFields(node.Content[i+1], t.Elem(), joinFieldPath(path, node.Content[i].Value), file, errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			walkUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), file, errs)
+		}
+	}
+}
+
+func findField(fields []yamlField, name string) (yamlField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return yamlField{}, false
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// unknownFieldError 构造未知字段错误，存在相近的字段名时给出建议
+func unknownFieldError(fields []yamlField, key *yaml.Node, path, file string) error {
+	msg := fmt.Sprintf("%s: 未知字段 '%s'", location(file, key.Line), joinFieldPath(path, key.Value))
+	if suggestion := closestField(fields, key.Value); suggestion != "" {
+		msg += fmt.Sprintf("（是否为 '%s'？）", suggestion)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// closestField 返回编辑距离最小且不超过阈值的字段名（阈值随长度放宽，最多 3）
+func closestField(fields []yamlField, name string) string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	sort.Strings(names)
+
+	best, bestDist := "", min(3, max(1, len(name)/4))+1
+	for _, candidate := range names {
+		if d := editDistance(strings.ToLower(name), candidate); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离（相邻字符交换计为一次编辑，如 confrim -> confirm）
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const unknownFieldsConfig = `interval: "1m"
+slow_latancy: "5s"
+x-common: &common
+  category: "public"
+  sponsor: "Alice"
+monitors:
+  - <<: *common
+    provider: "Demo"
+    service: "cc"
+    url: "https://demo.example.com"
+    method: "POST"
+    sucess_contains: "ok"
+    headers:
+      X-Custom-Header: "kept"
+`
+
+func TestUnknownFields(t *testing.T) {
+	_, err := NewLoader().parse([]byte(unknownFieldsConfig), "config.yaml")
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("应报告 2 个未知字段，got %v", err)
+	}
+	for i, want := range []string{
+		"第 2 行: 未知字段 'slow_latancy'（是否为 'slow_latency'？）",
+		"第 12 行: 未知字段 'monitors[0].sucess_contains'（是否为 'success_contains'？）",
+	} {
+		if errs[i].Error() != want {
+			t.Errorf("errs[%d] = %q, want %q", i, errs[i], want)
+		}
+	}
+
+	loader := NewLoader()
+	loader.SetUnknownFieldMode(UnknownFieldsWarn)
+	cfg, err := loader.parse([]byte(unknownFieldsConfig), "config.yaml")
+	if err != nil {
+		t.Fatalf("warn 模式不应报错: %v", err)
+	}
+	if len(cfg.Warnings) != 2 || cfg.Monitors[0].Category != "public" {
+		t.Fatalf("warn 模式应记录警告并正常加载: warnings=%v monitor=%+v", cfg.Warnings, cfg.Monitors[0])
+	}
+}
+
+func TestUnknownFieldsInIncludeAndTemplate(t *testing.T) {
+	t.Parallel()
+
+	main := strings.Replace(includeMainConfig, `    method: "POST"`, `    method: "POST"
+    confrim: {retries: 1}`, 1)
+	path := writeIncludeConfig(t, main, map[string]string{"other.yaml": `monitors:
+  - provider: "Other"
+    service: "cx"
+    extends: base
+    url: "https://other.example.com"
+    schedule: {cron: "* * * * *", timezon: "UTC"}
+`})
+
+	_, err := NewLoader().Load(path)
+	if err == nil ||
+		!strings.Contains(err.Error(), "第 9 行: 未知字段 'templates.base.confrim'（是否为 'confirm'？）") ||
+		!strings.Contains(err.Error(), filepath.Join("conf.d", "other.yaml")+" 第 6 行: 未知字段 'monitors[0].schedule.timezon'（是否为 'timezone'？）") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	t.Parallel()
+
+	config := `interval: "abc"
+monitors:
+  - provider: "Demo"
+    service: "cc"
+    category: "public"
+    sponsor: "Alice"
+    url: "https://demo.example.com"
+    method: "FETCH"
+  - provider: "Other"
+    service: "cc"
+    category: "unknown"
+    url: "https://other.example.com"
+    method: "GET"
+`
+	_, err := NewLoader().parse([]byte(config), "config.yaml")
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("应返回 ValidationErrors，got %v", err)
+	}
+	want := []string{"method 'FETCH' 无效", "sponsor 不能为空", "category 'unknown' 无效", "解析 interval 失败"}
+	if len(errs) != len(want) {
+		t.Fatalf("错误数量 = %d, want %d: %v", len(errs), len(want), err)
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("缺少错误 %q: %v", w, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "共 4 处错误") {
+		t.Errorf("多个错误应汇总展示: %v", err)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := JSONSchema()
+	defs := schema["$defs"].(map[string]any)
+	service := defs["ServiceConfig"].(map[string]any)
+	if service["additionalProperties"] != false {
+		t.Errorf("ServiceConfig 应禁止未知字段")
+	}
+	props := service["properties"].(map[string]any)
+	for _, name := range []string{"success_contains", "extends", "vars", "schedule", "disabled"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("ServiceConfig 缺少字段 %s", name)
+		}
+	}
+	for _, name := range []string{"SlowLatencyDuration", "slow_latency_duration", "confirm_policy"} {
+		if _, ok := props[name]; ok {
+			t.Errorf("内部字段 %s 不应出现在 Schema 中", name)
+		}
+	}
+
+	root := schema["properties"].(map[string]any)
+	for _, name := range []string{"templates", "include_dir", "monitors"} {
+		if _, ok := root[name]; !ok {
+			t.Errorf("根对象缺少字段 %s", name)
+		}
+	}
+	if _, ok := root["version"]; ok {
+		t.Errorf("Loader 填充的字段不应出现在 Schema 中")
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeDoc include 目录中的一个配置文件
+// 只能包含 monitors 和 templates，用于按服务商等维度拆分监控项，减少多人修改同一文件的冲突
+type includeDoc struct {
+	name string     // 相对主配置文件目录的路径，用于错误定位（如 "conf.d/88code.yaml"）
+	data []byte     // 文件内容（用于计算配置版本）
+	root *yaml.Node // 根映射节点（空文件为空映射）
+}
+
+// includeKeys include 文件允许的顶层字段
+var includeKeys = map[string]bool{"monitors": true, "templates": true}
+
+// monitors 返回 include 文件中的监控项节点
+func (d includeDoc) monitors() []*yaml.Node {
+	if i := mappingKeyIndex(d.root, "monitors"); i >= 0 {
+		return resolveAlias(d.root.Content[i+1]).Content
+	}
+	return nil
+}
+
+// includeDirOf 返回主配置文件中 include_dir 指向的目录（相对路径基于主配置文件所在目录），未配置时为空
+func includeDirOf(doc *yaml.Node, configFile string) (string, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return "", nil
+	}
+	i := mappingKeyIndex(doc.Content[0], "include_dir")
+	if i < 0 {
+		return "", nil
+	}
+	value := resolveAlias(doc.Content[0].Content[i+1])
+	if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+		return "", fmt.Errorf("include_dir（第 %d 行）必须是目录路径", value.Line)
+	}
+	return resolveIncludeDir(value.Value, configFile)
+}
+
+// resolveIncludeDir 将 include_dir 解析为绝对路径
+func resolveIncludeDir(dir, configFile string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(dir) {
+		absConfig, err := filepath.Abs(configFile)
+		if err != nil {
+			return "", fmt.Errorf("解析配置文件路径失败: %w", err)
+		}
+		dir = filepath.Join(filepath.Dir(absConfig), dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// isIncludeFile 判断目录项是否为需要加载的 include 文件（*.yaml / *.yml，忽略隐藏文件）
+func isIncludeFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadIncludes 读取主配置文件 include_dir 下的全部 include 文件（按文件名排序，不递归子目录）
+// 主配置文件本身位于该目录时跳过
+func loadIncludes(doc *yaml.Node, configFile string) ([]includeDoc, error) {
+	dir, err := includeDirOf(doc, configFile)
+	if err != nil || dir == "" {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取 include 目录失败: %w", err)
+	}
+
+	absConfig, _ := filepath.Abs(configFile)
+	configDir := filepath.Dir(absConfig)
+
+	var names []string
+	for _, entry := range entries {
+		if isIncludeFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var docs []includeDoc
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if path == absConfig {
+			continue
+		}
+		// 跟随符号链接（如 Kubernetes ConfigMap 挂载），跳过目录
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+
+		label := path
+		if rel, err := filepath.Rel(configDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			label = rel
+		}
+
+		inc, err := parseInclude(path, label)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, inc)
+	}
+	return docs, nil
+}
+
+// parseInclude 读取并检查单个 include 文件
+func parseInclude(path, label string) (includeDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return includeDoc{}, fmt.Errorf("读取 include 文件失败: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return includeDoc{}, fmt.Errorf("解析 %s 失败: %w", label, err)
+	}
+	inc := includeDoc{name: label, data: data, root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}
+	if len(doc.Content) == 0 {
+		return inc, nil // 空文件
+	}
+
+	root := resolveAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return includeDoc{}, fmt.Errorf("%s: 根节点必须是映射", label)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if !includeKeys[key.Value] {
+			return includeDoc{}, fmt.Errorf("%s: 不支持顶层字段 '%s'（include 文件只能包含 monitors 和 templates）",
+				location(label, key.Line), key.Value)
+		}
+	}
+	if i := mappingKeyIndex(root, "monitors"); i >= 0 {
+		if seq := resolveAlias(root.Content[i+1]); seq.Kind != yaml.SequenceNode && seq.Tag != "!!null" {
+			return includeDoc{}, fmt.Errorf("%s: monitors 必须是列表", location(label, seq.Line))
+		}
+	}
+	inc.root = root
+	return inc, nil
+}
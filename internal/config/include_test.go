@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const includeMainConfig = `interval: "1m"
+include_dir: "conf.d"
+
+templates:
+  base:
+    category: "public"
+    sponsor: "Alice"
+    method: "POST"
+
+monitors:
+  - provider: "Main"
+    service: "cc"
+    extends: base
+    url: "https://main.example.com"
+`
+
+// writeIncludeConfig 在临时目录中写入主配置文件和 conf.d 下的 include 文件
+func writeIncludeConfig(t *testing.T, main string, includes map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0o755); err != nil {
+		t.Fatalf("创建 include 目录失败: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(main), 0o600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	for name, content := range includes {
+		if err := os.WriteFile(filepath.Join(dir, "conf.d", name), []byte(content), 0o600); err != nil {
+			t.Fatalf("写入 include 文件失败: %v", err)
+		}
+	}
+	return path
+}
+
+func TestLoadIncludeDir(t *testing.T) {
+	t.Parallel()
+
+	path := writeIncludeConfig(t, includeMainConfig, map[string]string{
+		"b-other.yaml": `monitors:
+  - provider: "Other"
+    service: "cx"
+    extends: shared
+    url: "https://other.example.com"
+`,
+		"a-templates.yml": `templates:
+  shared:
+    extends: base
+    method: "GET"
+`,
+		"notes.txt":    "ignored",
+		".hidden.yaml": "interval: 1s",
+		"empty.yaml":   "",
+	})
+
+	cfg, err := NewLoader().Load(path)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	if len(cfg.Monitors) != 2 {
+		t.Fatalf("监控项数量 = %d, want 2", len(cfg.Monitors))
+	}
+	if other := cfg.Monitors[1]; other.Provider != "Other" || other.Method != "GET" || other.Sponsor != "Alice" {
+		t.Fatalf("include 文件中的监控项未按模板展开: %+v", other)
+	}
+	want := []string{filepath.Join("conf.d", "a-templates.yml"), filepath.Join("conf.d", "b-other.yaml"), filepath.Join("conf.d", "empty.yaml")}
+	if strings.Join(cfg.IncludeFiles, ",") != strings.Join(want, ",") {
+		t.Fatalf("IncludeFiles = %v, want %v", cfg.IncludeFiles, want)
+	}
+
+	// include 文件变化时配置版本随之变化
+	version := cfg.Version
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "conf.d", "empty.yaml"), []byte("# changed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = NewLoader().Load(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if cfg.Version == version {
+		t.Fatalf("include 文件变化后配置版本未变化")
+	}
+}
+
+func TestLoadIncludeDirErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		includes map[string]string
+		want     string
+	}{
+		{
+			name: "duplicate across files",
+			includes: map[string]string{"dup.yaml": `
+monitors:
+  - provider: "Main"
+    service: "cc"
+    extends: base
+    url: "https://dup.example.com"
+`},
+			want: "monitor[1]（" + filepath.Join("conf.d", "dup.yaml") + " 第 3 行）: 重复的监控项: provider=Main, service=cc, channel=（与 monitor[0]（第 11 行）重复）",
+		},
+		{
+			name:     "global key in include",
+			includes: map[string]string{"bad.yaml": "interval: 5m\n"},
+			want:     filepath.Join("conf.d", "bad.yaml") + " 第 1 行: 不支持顶层字段 'interval'",
+		},
+		{
+			name: "duplicate template",
+			includes: map[string]string{"tmpl.yaml": `templates:
+  base:
+    method: "GET"
+`},
+			want: "templates.base（" + filepath.Join("conf.d", "tmpl.yaml") + " 第 3 行）与（第 6 行）重名",
+		},
+		{
+			name: "invalid field",
+			includes: map[string]string{"bad.yaml": `monitors:
+  - provider: "Bad"
+    service: "cc"
+    extends: base
+    url: "https://bad.example.com"
+    method: "FETCH"
+`},
+			want: "monitor[1]（" + filepath.Join("conf.d", "bad.yaml") + " 第 2 行）: method 'FETCH' 无效",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeIncludeConfig(t, includeMainConfig, tc.includes)
+			_, err := NewLoader().Load(path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want contains %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEditorIncludeMonitorsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	path := writeIncludeConfig(t, includeMainConfig, map[string]string{"other.yaml": `monitors:
+  - provider: "Other"
+    service: "cx"
+    extends: base
+    url: "https://other.example.com"
+`})
+	editor := NewEditor(NewLoader(), path)
+
+	monitors, err := editor.ListMonitors()
+	if err != nil {
+		t.Fatalf("列出监控项失败: %v", err)
+	}
+	if len(monitors) != 2 {
+		t.Fatalf("ListMonitors 应包含 include 文件中的监控项，got %d", len(monitors))
+	}
+
+	other := MonitorKey{Provider: "Other", Service: "cx"}
+	if _, err := editor.SetMonitorDisabled(other, true); !errors.Is(err, ErrMonitorReadOnly) {
+		t.Fatalf("修改 include 文件中的监控项应返回 ErrMonitorReadOnly，got=%v", err)
+	}
+	if _, err := editor.CreateMonitor(ServiceConfig{Provider: "Other", Service: "cx", Extends: "base", URL: "https://x.example.com"}); !errors.Is(err, ErrMonitorExists) {
+		t.Fatalf("与 include 文件中的监控项重复应返回 ErrMonitorExists，got=%v", err)
+	}
+
+	// 新增的监控项写入主配置文件
+	cfg, err := editor.CreateMonitor(ServiceConfig{Provider: "New", Service: "cc", Extends: "base", URL: "https://new.example.com"})
+	if err != nil {
+		t.Fatalf("新增失败: %v", err)
+	}
+	if len(cfg.Monitors) != 3 {
+		t.Fatalf("新增后监控项数量 = %d, want 3", len(cfg.Monitors))
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "provider: New") {
+		t.Fatalf("新增的监控项应写入主配置文件:\n%s", data)
+	}
+}
+
+// TestWatcherIncludeDirChange 验证 include_dir 改为其他目录或被移除后不再监听旧目录
+func TestWatcherIncludeDirChange(t *testing.T) {
+	path := writeIncludeConfig(t, includeMainConfig, nil)
+	dir := filepath.Dir(path)
+	if err := os.Mkdir(filepath.Join(dir, "other.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(NewLoader(), path, nil)
+	if err != nil {
+		t.Fatalf("创建监听器失败: %v", err)
+	}
+	defer w.Stop()
+
+	watching := func(sub string) bool {
+		for _, p := range w.watcher.WatchList() {
+			if p == filepath.Join(dir, sub) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err := w.watchIncludeDir(&AppConfig{IncludeDir: "conf.d"}); err != nil {
+		t.Fatalf("监听 include 目录失败: %v", err)
+	}
+	if !watching("conf.d") || !w.isIncludeEvent(filepath.Join(dir, "conf.d", "a.yaml")) {
+		t.Fatal("应监听 conf.d")
+	}
+
+	if err := w.watchIncludeDir(&AppConfig{IncludeDir: "other.d"}); err != nil {
+		t.Fatalf("切换 include 目录失败: %v", err)
+	}
+	if watching("conf.d") || !watching("other.d") || w.isIncludeEvent(filepath.Join(dir, "conf.d", "a.yaml")) {
+		t.Errorf("切换后监听列表 = %v，应只监听 other.d", w.watcher.WatchList())
+	}
+
+	if err := w.watchIncludeDir(&AppConfig{}); err != nil {
+		t.Fatalf("移除 include_dir 失败: %v", err)
+	}
+	if watching("other.d") || w.includeDir != "" {
+		t.Errorf("移除 include_dir 后仍监听 %v（includeDir=%q）", w.watcher.WatchList(), w.includeDir)
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// interpolatePattern 匹配 ${VAR}、${VAR:-default}、${file:/path}；$${ 为转义，输出字面量 ${
+	interpolatePattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+	// envNamePattern 环境变量名
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// secretFilePrefix 标量值（替换环境变量后）以 file: 开头时读取文件内容，用于 Docker/Kubernetes secret
+const secretFilePrefix = "file:"
+
+// interpolateNode 替换节点树中所有标量值（不含映射的键）中的 ${VAR} 引用和 file: 引用
+// 在解码和模板展开之前执行，因此对所有字段（含模板和 include 文件）生效；
+// file 为节点所在的 include 文件（主配置文件为空），configDir 用于解析 file: 的相对路径；
+// 解析出的环境变量值和文件内容追加到 resolved（可能是密钥，输出配置时脱敏）
+func interpolateNode(node *yaml.Node, file, configDir string, resolved *[]string) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := interpolateNode(child, file, configDir, resolved); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateNode(node.Content[i], file, configDir, resolved); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		value, err := interpolateValue(node.Value, configDir, resolved)
+		if err != nil {
+			return fmt.Errorf("%s: %w", location(file, node.Line), err)
+		}
+		if value != node.Value {
+			node.Value = value
+			// 未加引号的值按替换后的内容重新推断类型（如 port: ${PG_PORT}）
+			if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+			}
+		}
+	}
+	return nil
+}
+
+// interpolateValue 替换单个值中的 ${VAR} / ${VAR:-default} / ${file:/path}，
+// 替换后整个值为 file:/path 时返回文件内容；解析出的值（默认值除外）追加到 resolved
+func interpolateValue(value, configDir string, resolved *[]string) (string, error) {
+	if !strings.Contains(value, "${") && !strings.HasPrefix(value, secretFilePrefix) {
+		return value, nil
+	}
+
+	var firstErr error
+	result := interpolatePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		expr := match[2 : len(match)-1]
+		replaced, err := resolveReference(expr, configDir)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if replaced != "" && !strings.HasSuffix(expr, ":-"+replaced) {
+			*resolved = append(*resolved, replaced)
+		}
+		return replaced
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	if strings.HasPrefix(result, secretFilePrefix) {
+		content, err := readSecretFile(strings.TrimPrefix(result, secretFilePrefix), configDir)
+		if err == nil && content != "" {
+			*resolved = append(*resolved, content)
+		}
+		return content, err
+	}
+	return result, nil
+}
+
+// resolveReference 解析 ${...} 中的表达式
+// 未设置且没有默认值的环境变量视为错误，避免把空密钥发给上游；${VAR:-} 显式允许为空
+func resolveReference(expr, configDir string) (string, error) {
+	if path, ok := strings.CutPrefix(expr, secretFilePrefix); ok {
+		return readSecretFile(path, configDir)
+	}
+
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if !envNamePattern.MatchString(name) {
+		return "", fmt.Errorf("无效的变量引用 ${%s}", expr)
+	}
+	value, ok := os.LookupEnv(name)
+	if hasDefault && value == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置（可使用 ${%s:-默认值} 提供默认值）", name, name)
+	}
+	return value, nil
+}
+
+// readSecretFile 读取密钥文件内容（去掉末尾换行），相对路径基于主配置文件所在目录
+func readSecretFile(path, configDir string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("file: 引用的路径不能为空")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(configDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
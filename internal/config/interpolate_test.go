@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateConfig(t *testing.T) {
+	t.Setenv("TEST_RELAY_HOST", "relay.example.com")
+	t.Setenv("TEST_PG_PORT", "6543")
+	t.Setenv("TEST_EMPTY", "")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "org_id"), []byte("org-123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "api_key")
+	if err := os.WriteFile(secret, []byte("sk-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := `storage:
+  postgres:
+    port: ${TEST_PG_PORT}
+monitors:
+  - provider: "Demo"
+    service: "cc"
+    channel: "${TEST_CHANNEL:-vip}"
+    category: "public"
+    sponsor: "${TEST_EMPTY:-Alice}"
+    url: "https://${TEST_RELAY_HOST}/v1/{{MODEL}}"
+    method: "POST"
+    api_key: "file:` + secret + `"
+    vars:
+      ORG_ID: "${file:org_id}"
+      MODEL: "claude"
+    headers:
+      Authorization: "Bearer {{API_KEY}}"
+      X-Org: "{{ORG_ID}}"
+    body: '{"literal": "$${NOT_A_VAR}"}'
+`
+	cfg, err := NewLoader().parse([]byte(config), filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if cfg.Storage.Postgres.Port != 6543 {
+		t.Errorf("port = %d, want 6543", cfg.Storage.Postgres.Port)
+	}
+	m := cfg.Monitors[0]
+	checks := map[string][2]string{
+		"channel":       {m.Channel, "vip"},
+		"sponsor":       {m.Sponsor, "Alice"},
+		"url":           {m.URL, "https://relay.example.com/v1/claude"},
+		"api_key":       {m.APIKey, "sk-from-file"},
+		"Authorization": {m.Headers["Authorization"], "Bearer sk-from-file"},
+		"X-Org":         {m.Headers["X-Org"], "org-123"},
+		"body":          {m.Body, `{"literal": "${NOT_A_VAR}"}`},
+	}
+	for field, c := range checks {
+		if c[0] != c[1] {
+			t.Errorf("%s = %q, want %q", field, c[0], c[1])
+		}
+	}
+	// 解析出的值用于输出时脱敏（默认值来自配置文件本身，不计入）
+	if got, want := strings.Join(cfg.ResolvedSecrets, ","), "6543,relay.example.com,sk-from-file,org-123"; got != want {
+		t.Errorf("ResolvedSecrets = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolateConfigErrors(t *testing.T) {
+	base := `monitors:
+  - provider: "Demo"
+    service: "cc"
+    category: "public"
+    sponsor: "Alice"
+    method: "POST"
+`
+	cases := []struct {
+		name  string
+		extra string
+		want  string
+	}{
+		{"unset env", `    url: "https://${TEST_UNSET_VAR}/v1"` + "\n", "第 7 行: 环境变量 TEST_UNSET_VAR 未设置"},
+		{"invalid reference", `    url: "https://${1BAD}/v1"` + "\n", "无效的变量引用 ${1BAD}"},
+		{"missing secret file", `    url: "https://x.example.com"` + "\n" + `    api_key: "file:/nonexistent/secret"` + "\n", "第 8 行: 读取密钥文件失败"},
+		{"undefined placeholder", `    url: "https://x.example.com/{{MODLE}}"` + "\n" + "    vars: {MODEL: claude}\n", "占位符 {{MODLE}} 未在 vars 中定义"},
+		{"reserved var", `    url: "https://x.example.com"` + "\n" + "    vars: {API_KEY: x}\n", "不能定义 API_KEY"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLoader().parse([]byte(base+tc.extra), "config.yaml")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want contains %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyEnvOverridePerChannel(t *testing.T) {
+	t.Setenv("MONITOR_DEMO_RELAY_CC_API_KEY", "sk-service")
+	t.Setenv("MONITOR_DEMO_RELAY_CC_VIP_API_KEY", "sk-vip")
+
+	cfg := &AppConfig{Monitors: []ServiceConfig{
+		{Provider: "demo-relay", Service: "cc", Channel: "vip"},
+		{Provider: "demo-relay", Service: "cc", Channel: "free"},
+		{Provider: "demo-relay", Service: "cc"},
+	}}
+	cfg.ApplyEnvOverrides()
+
+	for i, want := range []string{"sk-vip", "sk-service", "sk-service"} {
+		if got := cfg.Monitors[i].APIKey; got != want {
+			t.Errorf("monitor[%d] api_key = %q, want %q", i, got, want)
+		}
+	}
+}
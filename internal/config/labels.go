@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxLabelValueLen 标签值的最大长度（字符数）
+const maxLabelValueLen = 128
+
+// validateLabels 检查标签名和标签值
+// 标签名与 Prometheus 标签名规则一致（字母、数字和下划线，不能以数字开头），导出指标时无需转换
+func validateLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := labels[k]
+		if !envNamePattern.MatchString(k) {
+			return fmt.Errorf("标签名 '%s' 无效（只能包含字母、数字和下划线，且不能以数字开头）", k)
+		}
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("标签 '%s' 的值不能为空", k)
+		}
+		if n := len([]rune(v)); n > maxLabelValueLen {
+			return fmt.Errorf("标签 '%s' 的值过长（当前 %d，最大 %d）", k, n, maxLabelValueLen)
+		}
+	}
+	return nil
+}
+
+// MatchLabels 判断 labels 是否包含 selector 中的全部键值对（selector 为空时总是匹配）
+func MatchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatLabels 将标签格式化为按名称排序的 "k=v, k=v"（用于日志和消息模板）
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + labels[k]
+	}
+	return strings.Join(parts, ", ")
+}
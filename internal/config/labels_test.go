@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMonitorLabels(t *testing.T) {
+	t.Parallel()
+
+	config := `templates:
+  vip:
+    category: "commercial"
+    sponsor: "Alice"
+    method: "POST"
+    labels: {tier: "vip", owner: "alice"}
+notifier:
+  routes:
+    - name: "vip"
+      match_labels: {tier: "vip"}
+      wecom: {enabled: true, webhook_url: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=vip"}
+    - match_labels: {}
+monitors:
+  - provider: "Demo"
+    service: "cc"
+    extends: vip
+    url: "https://demo.example.com"
+    labels: {region: "hk", owner: "bob"}
+  - provider: "Other"
+    service: "cc"
+    extends: vip
+    url: "https://other.example.com"
+    labels: {"1st": "x", team: " "}
+`
+	_, err := NewLoader().parse([]byte(config), "config.yaml")
+	for _, want := range []string{
+		"labels: 标签名 '1st' 无效",
+		"notifier.routes[1]: match_labels 不能为空",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("缺少错误 %q: %v", want, err)
+		}
+	}
+
+	config = strings.Replace(config, `    labels: {"1st": "x", team: " "}
+`, "", 1)
+	config = strings.Replace(config, "    - match_labels: {}\n", "", 1)
+	cfg, err := NewLoader().parse([]byte(config), "config.yaml")
+	if err != nil {
+		t.Fatalf("parse 失败: %v", err)
+	}
+	// 标签与模板深度合并，监控项的值优先
+	if got := FormatLabels(cfg.Monitors[0].Labels); got != "owner=bob, region=hk, tier=vip" {
+		t.Errorf("Monitors[0].Labels = %s", got)
+	}
+	if r := cfg.Notifier.Routes[0]; r.WeCom.TimeoutDuration == 0 || r.WeCom.RetryCount != 2 || r.WeCom.Templates == nil {
+		t.Errorf("路由的企业微信配置应填充默认值: %+v", r.WeCom)
+	}
+
+	if !MatchLabels(cfg.Monitors[0].Labels, map[string]string{"tier": "vip", "region": "hk"}) ||
+		MatchLabels(cfg.Monitors[1].Labels, map[string]string{"region": "hk"}) ||
+		!MatchLabels(nil, nil) {
+		t.Errorf("MatchLabels 结果不正确")
+	}
+}
@@ -1,21 +1,33 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Loader 配置加载器
 type Loader struct {
+	mu            sync.RWMutex
 	currentConfig *AppConfig
+	unknownFields UnknownFieldMode
 }
 
-// NewLoader 创建配置加载器
+// NewLoader 创建配置加载器（未知字段默认视为错误，可通过环境变量 MONITOR_CONFIG_UNKNOWN_FIELDS=warn 改为警告）
 func NewLoader() *Loader {
-	return &Loader{}
+	return &Loader{unknownFields: defaultUnknownFieldMode()}
+}
+
+// SetUnknownFieldMode 设置未知字段的处理方式（在 Load 之前调用）
+func (l *Loader) SetUnknownFieldMode(mode UnknownFieldMode) {
+	l.unknownFields = mode
 }
 
 // Load 加载并验证配置文件
@@ -26,34 +38,94 @@ func (l *Loader) Load(filename string) (*AppConfig, error) {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	// 解析 YAML
-	var cfg AppConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	cfg, err := l.parse(data, filename)
+	if err != nil {
+		return nil, err
 	}
 
+	l.setCurrent(cfg)
+	return cfg, nil
+}
+
+// parse 解析配置内容并执行完整的校验/规范化流程（不更新当前配置）
+// filename 用于定位 include_dir 和 body include 的 data/ 目录
+func (l *Loader) parse(data []byte, filename string) (*AppConfig, error) {
 	absPath, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, fmt.Errorf("解析配置文件路径失败: %w", err)
 	}
 	configDir := filepath.Dir(absPath)
 
-	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("配置验证失败: %w", err)
+	// 解析 YAML，替换 ${VAR} / file: 引用，合并 include 目录中的监控项并展开模板后再解码（校验在展开之后执行）
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	var resolved []string
+	if err := interpolateNode(&doc, "", configDir, &resolved); err != nil {
+		return nil, fmt.Errorf("替换配置变量失败: %w", err)
+	}
+	includes, err := loadIncludes(&doc, filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, inc := range includes {
+		if err := interpolateNode(inc.root, inc.name, configDir, &resolved); err != nil {
+			return nil, fmt.Errorf("替换配置变量失败: %w", err)
+		}
+	}
+
+	// 检查未知字段（在展开模板之前，错误位置指向原始文件）；strict 模式下与校验错误一起报告
+	var errs ValidationErrors
+	var warnings []string
+	unknown := checkUnknownFields(&doc, "")
+	for _, inc := range includes {
+		unknown = append(unknown, checkUnknownFields(inc.root, inc.name)...)
+	}
+	if l.unknownFields == UnknownFieldsWarn {
+		for _, err := range unknown {
+			log.Printf("[Config] 警告: %v", err)
+			warnings = append(warnings, err.Error())
+		}
+	} else {
+		errs = append(errs, unknown...)
 	}
 
+	sources, err := expandTemplates(&doc, includes)
+	if err != nil {
+		return nil, fmt.Errorf("展开监控项模板失败: %w", err)
+	}
+	var cfg AppConfig
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(&cfg); err != nil {
+			errs.add(err)
+			return nil, fmt.Errorf("解析配置文件失败: %w", errs)
+		}
+	}
+	for i := range cfg.Monitors {
+		if i < len(sources) {
+			cfg.Monitors[i].source = sources[i]
+		}
+	}
+	for _, inc := range includes {
+		cfg.IncludeFiles = append(cfg.IncludeFiles, inc.name)
+	}
+	cfg.Warnings = warnings
+	cfg.ResolvedSecrets = resolved
+
+	// 验证配置
+	errs.add(cfg.Validate())
+
 	// 应用环境变量覆盖
 	cfg.ApplyEnvOverrides()
 
 	// 解析 body include
-	if err := cfg.ResolveBodyIncludes(configDir); err != nil {
-		return nil, err
-	}
+	errs.add(cfg.ResolveBodyIncludes(configDir))
 
-	// 规范化配置（填充默认值等）
-	if err := cfg.Normalize(); err != nil {
-		return nil, fmt.Errorf("配置规范化失败: %w", err)
+	// 规范化配置（填充默认值等）；与上面的校验错误一起返回，一次报告全部问题
+	errs.add(cfg.Normalize())
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("配置验证失败: %w", errs)
 	}
 
 	// 处理占位符
@@ -61,7 +133,16 @@ func (l *Loader) Load(filename string) (*AppConfig, error) {
 		cfg.Monitors[i].ProcessPlaceholders()
 	}
 
-	l.currentConfig = &cfg
+	// 配置版本覆盖主配置文件和全部 include 文件
+	h := sha256.New()
+	h.Write(data)
+	for _, inc := range includes {
+		h.Write([]byte("\x00" + inc.name + "\x00"))
+		h.Write(inc.data)
+	}
+	cfg.Version = hex.EncodeToString(h.Sum(nil))[:12]
+	cfg.LoadedAt = time.Now()
+
 	return &cfg, nil
 }
 
@@ -70,8 +151,8 @@ func (l *Loader) LoadOrRollback(filename string) (*AppConfig, error) {
 	newConfig, err := l.Load(filename)
 	if err != nil {
 		// 返回错误但保持旧配置
-		if l.currentConfig != nil {
-			return l.currentConfig, fmt.Errorf("配置加载失败，保持旧配置: %w", err)
+		if current := l.GetCurrent(); current != nil {
+			return current, fmt.Errorf("配置加载失败，保持旧配置: %w", err)
 		}
 		return nil, err
 	}
@@ -80,5 +161,13 @@ func (l *Loader) LoadOrRollback(filename string) (*AppConfig, error) {
 
 // GetCurrent 获取当前配置
 func (l *Loader) GetCurrent() *AppConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	return l.currentConfig
 }
+
+func (l *Loader) setCurrent(cfg *AppConfig) {
+	l.mu.Lock()
+	l.currentConfig = cfg
+	l.mu.Unlock()
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// schemaEnums 取值固定且区分大小写的字段（类型名.yaml 字段名 -> 可选值）
+var schemaEnums = map[string][]string{
+	"StorageConfig.type": {"", "sqlite", "postgres", "postgresql"},
+}
+
+var (
+	schemaOnce   sync.Once
+	schemaResult map[string]any
+)
+
+// JSONSchema 返回根据 AppConfig 的 Go 类型生成的配置文件 JSON Schema（draft 2020-12）
+// 用于编辑器补全和 CI 校验：对象不允许未知字段，非字符串标量同时接受 ${VAR} 引用；
+// 取值范围、必填字段等语义校验仍以 monitor validate 为准
+func JSONSchema() map[string]any {
+	schemaOnce.Do(func() {
+		g := &schemaGenerator{defs: make(map[string]any)}
+		root := g.structSchema(reflect.TypeOf(AppConfig{}))
+		// 以 x- 开头的顶层字段用于定义 YAML 锚点，不做检查
+		root["patternProperties"] = map[string]any{"^" + extensionPrefix: map[string]any{}}
+		root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+		root["title"] = "Relay Pulse config.yaml"
+		root["$defs"] = g.defs
+		schemaResult = root
+	})
+	return schemaResult
+}
+
+// schemaGenerator 递归生成 Schema，命名结构体放入 $defs 并以 $ref 引用（避免 ServiceConfig 等重复展开）
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// interpolatedScalar 非字符串标量也可以写成 ${VAR} 引用（加载时替换）
+var interpolatedScalar = map[string]any{"type": "string", "pattern": `\$\{`}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": []string{"string", "number"}} // 未加引号的数字（如 channel: 2024）按字符串解码
+	case reflect.Bool:
+		return map[string]any{"anyOf": []any{map[string]any{"type": "boolean"}, interpolatedScalar}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"anyOf": []any{map[string]any{"type": "integer"}, interpolatedScalar}}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"anyOf": []any{map[string]any{"type": "number"}, interpolatedScalar}}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // 先占位，支持自引用
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for _, f := range yamlFields(t) {
+		prop := g.schema(f.typ)
+		if enum, ok := schemaEnums[t.Name()+"."+f.name]; ok {
+			prop = map[string]any{"type": "string", "enum": enum}
+		}
+		properties[f.name] = prop
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -18,9 +19,11 @@ type Watcher struct {
 	filename     string
 	watcher      *fsnotify.Watcher
 	onReload     func(*AppConfig)
+	onError      func(error) // 重载失败回调（可选，如通过告警渠道通知）
 	debounceTime time.Duration
 	watchMu      sync.Mutex
 	watchedDirs  map[string]struct{}
+	includeDir   string // 当前配置的 include 目录（受 watchMu 保护，配置重载后更新）
 }
 
 // NewWatcher 创建配置监听器
@@ -39,6 +42,11 @@ func NewWatcher(loader *Loader, filename string, onReload func(*AppConfig)) (*Wa
 	}, nil
 }
 
+// SetOnError 设置重载失败回调（在 Start 之前调用）
+func (w *Watcher) SetOnError(onError func(error)) {
+	w.onError = onError
+}
+
 // Start 启动监听（监听父目录以兼容不同编辑器）
 func (w *Watcher) Start(ctx context.Context) error {
 	// 监听父目录而非文件本身，避免编辑器 rename 导致监听失效
@@ -57,6 +65,13 @@ func (w *Watcher) Start(ctx context.Context) error {
 		}
 	}
 
+	// include 目录（include_dir 变化时在重载后更新）
+	if cfg := w.loader.GetCurrent(); cfg != nil {
+		if err := w.watchIncludeDir(cfg); err != nil {
+			return err
+		}
+	}
+
 	log.Printf("[Config] 开始监听配置文件: %s (监听目录: %s)", w.filename, dir)
 
 	go func() {
@@ -73,16 +88,22 @@ func (w *Watcher) Start(ctx context.Context) error {
 					return
 				}
 
-				// 只关心目标配置文件和 data/ 目录下 JSON 的写入/创建/重命名事件
+				// 只关心目标配置文件、data/ 目录下 JSON 和 include 目录下 YAML 的写入/创建/重命名事件
 				eventPath := filepath.Clean(event.Name) // 归一化事件路径
 				isConfigFile := eventPath == targetFile
 				isDataFile := strings.HasPrefix(eventPath, dataDirPrefix)
-				if !isConfigFile && !isDataFile {
+				isIncludeFile := w.isIncludeEvent(eventPath)
+				if !isConfigFile && !isDataFile && !isIncludeFile {
 					continue
 				}
 
 				// 监听 Write/Create/Rename 事件（vim/nano 等编辑器使用 rename 保存）
-				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				// include 文件被删除时同样需要重载
+				ops := fsnotify.Write | fsnotify.Create | fsnotify.Rename
+				if isIncludeFile {
+					ops |= fsnotify.Remove
+				}
+				if event.Op&ops != 0 {
 					// 防抖：延迟执行，避免编辑器多次写入
 					if debounceTimer != nil {
 						debounceTimer.Stop()
@@ -116,13 +137,30 @@ func (w *Watcher) Start(ctx context.Context) error {
 
 // reload 重新加载配置
 func (w *Watcher) reload() {
+	oldConfig := w.loader.GetCurrent()
 	newConfig, err := w.loader.LoadOrRollback(w.filename)
 	if err != nil {
 		log.Printf("[Config] 重载失败: %v", err)
+		if w.onError != nil {
+			w.onError(err)
+		}
+		return
+	}
+
+	diff := Diff(oldConfig, newConfig)
+	// 管理 API 写入配置文件后同样会触发文件事件，内容与已生效的配置一致时不再重复应用
+	if oldConfig != nil && newConfig.Version == oldConfig.Version && diff.Empty() {
+		log.Printf("[Config] 配置文件与当前生效的配置一致（版本 %s），跳过重载", newConfig.Version)
 		return
 	}
+	log.Printf("[Config] 热更新成功！已加载 %d 个监控任务（%s）", len(newConfig.Monitors), diff.Summary())
+	for _, line := range diff.Lines() {
+		log.Printf("[Config]   %s", line)
+	}
 
-	log.Printf("[Config] 热更新成功！已加载 %d 个监控任务", len(newConfig.Monitors))
+	if err := w.watchIncludeDir(newConfig); err != nil {
+		log.Printf("[Config] 监听 include 目录失败: %v", err)
+	}
 
 	// 回调通知
 	if w.onReload != nil {
@@ -162,6 +200,80 @@ func (w *Watcher) addWatch(dir string) error {
 	return nil
 }
 
+// watchIncludeDir 按配置监听 include 目录
+// include_dir 被移除或改为其他目录时取消对旧目录的监听，旧目录中的变更不再触发重载
+func (w *Watcher) watchIncludeDir(cfg *AppConfig) error {
+	dir, err := resolveIncludeDir(cfg.IncludeDir, w.filename)
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		if err := w.addWatch(dir); err != nil {
+			return err
+		}
+	}
+
+	w.watchMu.Lock()
+	old := w.includeDir
+	w.includeDir = dir
+	w.watchMu.Unlock()
+	if old == dir {
+		return nil
+	}
+
+	if old != "" {
+		if err := w.removeWatch(old); err != nil {
+			log.Printf("[Config] 取消监听 include 目录失败: %v", err)
+		} else {
+			log.Printf("[Config] 停止监听 include 目录: %s", old)
+		}
+	}
+	if dir != "" {
+		log.Printf("[Config] 开始监听 include 目录: %s", dir)
+	}
+	return nil
+}
+
+// removeWatch 取消对目录的监听（配置文件所在目录和 data 目录始终保留）
+func (w *Watcher) removeWatch(dir string) error {
+	dir = filepath.Clean(dir)
+	configDir := filepath.Clean(filepath.Dir(w.filename))
+	if absConfig, err := filepath.Abs(w.filename); err == nil {
+		configDir = filepath.Dir(absConfig)
+	}
+	if dir == configDir || dir == filepath.Join(configDir, "data") {
+		return nil
+	}
+
+	w.watchMu.Lock()
+	_, exists := w.watchedDirs[dir]
+	delete(w.watchedDirs, dir)
+	w.watchMu.Unlock()
+	if !exists {
+		return nil
+	}
+
+	// 目录已被删除时 fsnotify 会自动移除监听
+	if err := w.watcher.Remove(dir); err != nil && !errors.Is(err, fsnotify.ErrNonExistentWatch) {
+		return err
+	}
+	return nil
+}
+
+// isIncludeEvent 判断事件是否来自 include 目录中的配置文件
+// Kubernetes ConfigMap 挂载通过替换 ..data 符号链接更新文件，也视为 include 文件变更
+func (w *Watcher) isIncludeEvent(path string) bool {
+	w.watchMu.Lock()
+	dir := w.includeDir
+	w.watchMu.Unlock()
+
+	if dir == "" || filepath.Dir(path) != dir {
+		return false
+	}
+	name := filepath.Base(path)
+	return isIncludeFile(name) || name == "..data"
+}
+
 // rewatchPath 确保替换后的文件所在目录继续被监听
 func (w *Watcher) rewatchPath(path string) error {
 	if path == "" {
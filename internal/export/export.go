@@ -0,0 +1,213 @@
+// Package export 提供探测历史的流式导出（CSV / NDJSON / JSON）
+// 供 HTTP 导出接口和命令行 export 子命令共用
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// 支持的导出格式
+const (
+	FormatCSV    = "csv"    // 逗号分隔，首行为表头
+	FormatNDJSON = "ndjson" // 每行一个 JSON 对象
+	FormatJSON   = "json"   // 单个 JSON 数组
+)
+
+// Row 导出的单行数据（探测记录 + 监控项元数据）
+type Row struct {
+	ID           int64  `json:"id"`
+	Provider     string `json:"provider"`
+	ProviderSlug string `json:"provider_slug"`
+	Service      string `json:"service"`
+	Channel      string `json:"channel"`
+	Category     string `json:"category"`
+	Sponsor      string `json:"sponsor"`
+	Status       int    `json:"status"`
+	SubStatus    string `json:"sub_status"`
+	Latency      int    `json:"latency"`
+	Timestamp    int64  `json:"timestamp"`
+	Time         string `json:"time"`     // RFC3339（UTC），便于表格软件直接阅读
+	Region       string `json:"region"`   // 探测地域（本机探测为空）
+	Attempts     int    `json:"attempts"` // 探测尝试次数（含失败确认重试）
+}
+
+// csvHeader CSV 表头（顺序与 Row.csvRecord 保持一致）
+var csvHeader = []string{
+	"id", "provider", "provider_slug", "service", "channel", "category", "sponsor",
+	"status", "sub_status", "latency", "timestamp", "time", "region", "attempts",
+}
+
+// csvRecord 返回 CSV 行，文本字段经 csvText 处理（来自配置或探测节点上报，不可信）
+func (r *Row) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10),
+		csvText(r.Provider),
+		csvText(r.ProviderSlug),
+		csvText(r.Service),
+		csvText(r.Channel),
+		csvText(r.Category),
+		csvText(r.Sponsor),
+		strconv.Itoa(r.Status),
+		csvText(r.SubStatus),
+		strconv.Itoa(r.Latency),
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Time,
+		csvText(r.Region),
+		strconv.Itoa(r.Attempts),
+	}
+}
+
+// csvText 防止表格软件把文本当作公式执行（CSV 注入）：以 = + - @ 或制表符、回车开头的值前加单引号
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ParseFormat 校验并规范化导出格式（空字符串默认 CSV）
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON, "jsonl":
+		return FormatNDJSON, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("不支持的导出格式: %s (支持: csv, ndjson, json)", format)
+	}
+}
+
+// ContentType 返回导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// ParseTime 解析时间参数，支持 RFC3339、日期（2006-01-02，按 UTC）和 Unix 秒
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q（支持 RFC3339、2006-01-02、Unix 秒）", value)
+}
+
+// Export 按过滤条件流式导出探测记录到 w，返回导出的行数
+// monitors 用于补充 category/sponsor/provider_slug 等元数据（已下线的监控项元数据为空）
+func Export(store storage.Storage, monitors []config.ServiceConfig, filter storage.ExportFilter, format string, w io.Writer) (int, error) {
+	meta := make(map[string]config.ServiceConfig, len(monitors))
+	for _, m := range monitors {
+		meta[m.Provider+"/"+m.Service+"/"+m.Channel] = m
+	}
+
+	bw := bufio.NewWriter(w)
+	var rowWriter func(*Row) error
+	var finish func() error
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		if err := cw.Write(csvHeader); err != nil {
+			return 0, fmt.Errorf("写入 CSV 表头失败: %w", err)
+		}
+		rowWriter = func(r *Row) error { return cw.Write(r.csvRecord()) }
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+
+	case FormatNDJSON:
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		rowWriter = func(r *Row) error { return enc.Encode(r) }
+		finish = func() error { return nil }
+
+	case FormatJSON:
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		first := true
+		if _, err := bw.WriteString("["); err != nil {
+			return 0, err
+		}
+		rowWriter = func(r *Row) error {
+			if !first {
+				if _, err := bw.WriteString(","); err != nil {
+					return err
+				}
+			}
+			first = false
+			return enc.Encode(r)
+		}
+		finish = func() error {
+			_, err := bw.WriteString("]\n")
+			return err
+		}
+
+	default:
+		return 0, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+
+	count := 0
+	err := store.ExportRecords(filter, func(record *storage.ProbeRecord) error {
+		m := meta[record.Provider+"/"+record.Service+"/"+record.Channel]
+		row := &Row{
+			ID:           record.ID,
+			Provider:     record.Provider,
+			ProviderSlug: m.ProviderSlug,
+			Service:      record.Service,
+			Channel:      record.Channel,
+			Category:     m.Category,
+			Sponsor:      m.Sponsor,
+			Status:       record.Status,
+			SubStatus:    string(record.SubStatus),
+			Latency:      record.Latency,
+			Timestamp:    record.Timestamp,
+			Time:         time.Unix(record.Timestamp, 0).UTC().Format(time.RFC3339),
+			Region:       record.Region,
+			Attempts:     record.Attempts,
+		}
+		if err := rowWriter(row); err != nil {
+			return fmt.Errorf("写入导出数据失败: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := finish(); err != nil {
+		return count, fmt.Errorf("写入导出数据失败: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("写入导出数据失败: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,183 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"monitor/internal/config"
+	"monitor/internal/storage"
+)
+
+// fakeStorage 仅实现 ExportRecords 的测试存储
+type fakeStorage struct {
+	storage.Storage
+	records []*storage.ProbeRecord
+	filter  storage.ExportFilter
+}
+
+func (f *fakeStorage) ExportRecords(filter storage.ExportFilter, fn func(*storage.ProbeRecord) error) error {
+	f.filter = filter
+	for _, r := range f.records {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{
+		records: []*storage.ProbeRecord{
+			{ID: 1, Provider: "Demo", Service: "cc", Channel: "vip", Status: 1, Latency: 320, Timestamp: 1735689600},
+			{ID: 2, Provider: "Demo", Service: "cc", Channel: "vip", Status: 0, SubStatus: storage.SubStatusServerError, Timestamp: 1735689660},
+			{ID: 3, Provider: "Gone", Service: "cx", Status: 1, Latency: 100, Timestamp: 1735689720},
+		},
+	}
+}
+
+var testMonitors = []config.ServiceConfig{
+	{Provider: "Demo", ProviderSlug: "demo", Service: "cc", Channel: "vip", Category: "commercial", Sponsor: "Alice, Inc."},
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	count, err := Export(newFakeStorage(), testMonitors, storage.ExportFilter{}, FormatCSV, &buf)
+	if err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("count = %d, want 3", count)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV 解析失败: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("行数 = %d, want 4（含表头）", len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("表头 = %v", rows[0])
+	}
+
+	// 元数据来自 ServiceConfig，含逗号的字段需正确转义
+	want := []string{"2", "Demo", "demo", "cc", "vip", "commercial", "Alice, Inc.", "0", "server_error", "0", "1735689660", "2025-01-01T00:01:00Z", "", "0"}
+	if strings.Join(rows[2], "|") != strings.Join(want, "|") {
+		t.Errorf("第 2 行 = %v, want %v", rows[2], want)
+	}
+
+	// 已下线的监控项元数据为空
+	if rows[3][2] != "" || rows[3][5] != "" {
+		t.Errorf("未知监控项不应带元数据: %v", rows[3])
+	}
+}
+
+// TestExportCSVFormula 验证以公式字符开头的文本字段加单引号，NDJSON/JSON 保持原值
+func TestExportCSVFormula(t *testing.T) {
+	store := &fakeStorage{records: []*storage.ProbeRecord{
+		{ID: 1, Provider: "=HYPERLINK(\"http://evil\")", Service: "+cc", Channel: "-1", Region: "@sg", Timestamp: 1735689600},
+	}}
+	var buf bytes.Buffer
+	if _, err := Export(store, nil, storage.ExportFilter{}, FormatCSV, &buf); err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV 解析失败: %v", err)
+	}
+	want := []string{`'=HYPERLINK("http://evil")`, "'+cc", "'-1", "'@sg"}
+	got := []string{rows[1][1], rows[1][3], rows[1][4], rows[1][12]}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("CSV 字段 = %v, want %v", got, want)
+	}
+
+	buf.Reset()
+	if _, err := Export(store, nil, storage.ExportFilter{}, FormatNDJSON, &buf); err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"service":"+cc"`) {
+		t.Errorf("NDJSON 不应转义: %s", buf.String())
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Export(newFakeStorage(), testMonitors, storage.ExportFilter{}, FormatNDJSON, &buf); err != nil {
+		t.Fatalf("Export 失败: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("行数 = %d, want 3", len(lines))
+	}
+	var row Row
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatalf("NDJSON 解析失败: %v", err)
+	}
+	if row.Sponsor != "Alice, Inc." || row.Channel != "vip" || row.Latency != 320 {
+		t.Errorf("row = %+v", row)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []*storage.ProbeRecord
+		want    int
+	}{
+		{"有数据", newFakeStorage().records, 3},
+		{"无数据", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			store := &fakeStorage{records: tt.records}
+			if _, err := Export(store, testMonitors, storage.ExportFilter{}, FormatJSON, &buf); err != nil {
+				t.Fatalf("Export 失败: %v", err)
+			}
+
+			var rows []Row
+			if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+				t.Fatalf("JSON 解析失败: %v\n%s", err, buf.String())
+			}
+			if len(rows) != tt.want {
+				t.Errorf("len = %d, want %d", len(rows), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1735689600", 1735689600, false},
+		{"2025-01-01T08:00:00+08:00", 1735689600, false},
+		{"2025-01-01", 1735689600, false},
+		{"", 0, false},
+		{"yesterday", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if tt.want == 0 {
+			if !tt.wantErr && !got.IsZero() {
+				t.Errorf("ParseTime(%q) = %v, want zero", tt.input, got)
+			}
+			continue
+		}
+		if got.Unix() != tt.want {
+			t.Errorf("ParseTime(%q) = %d, want %d", tt.input, got.Unix(), tt.want)
+		}
+	}
+}
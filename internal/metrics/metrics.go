@@ -0,0 +1,360 @@
+// Package metrics 提供轻量的 Prometheus 文本格式指标（无第三方依赖）
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// collector 可输出 Prometheus 文本格式的指标
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]collector{}
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[c.name()]; exists {
+		panic("metrics: 重复注册指标 " + c.name())
+	}
+	registry[c.name()] = c
+}
+
+// CounterVec 带标签的累加计数器
+type CounterVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounterVec 创建并注册计数器
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{metricName: name, help: help, labels: labels, values: map[string]*series{}}
+	register(c)
+	return c
+}
+
+// Inc 计数加 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 v（v 必须为非负数）
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seriesFor(c.metricName, c.labels, c.values, labelValues).value += v
+}
+
+// Value 返回当前计数（主要用于测试）
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// seriesFor 返回标签值对应的序列，不存在时创建
+func seriesFor(name string, labels []string, values map[string]*series, labelValues []string) *series {
+	if len(labelValues) != len(labels) {
+		panic(fmt.Sprintf("metrics: %s 需要 %d 个标签值，实际 %d 个", name, len(labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := values[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		values[key] = s
+	}
+	return s
+}
+
+func (c *CounterVec) name() string { return c.metricName }
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return writeFamily(w, c.metricName, c.help, "counter", c.labels, c.values)
+}
+
+// GaugeVec 带标签的瞬时值
+type GaugeVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]*series
+}
+
+// NewGaugeVec 创建并注册瞬时值指标
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{metricName: name, help: help, labels: labels, values: map[string]*series{}}
+	register(g)
+	return g
+}
+
+// Set 设置当前值
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	seriesFor(g.metricName, g.labels, g.values, labelValues).value = v
+}
+
+// Add 当前值增加 v（可为负数）
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	seriesFor(g.metricName, g.labels, g.values, labelValues).value += v
+}
+
+// Value 返回当前值（主要用于测试）
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if s, ok := g.values[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// Retain 只保留 keep 返回 true 的序列（用于删除已移除对象的序列）
+func (g *GaugeVec) Retain(keep func(labelValues []string) bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for k, s := range g.values {
+		if !keep(s.labelValues) {
+			delete(g.values, k)
+		}
+	}
+}
+
+func (g *GaugeVec) name() string { return g.metricName }
+
+func (g *GaugeVec) write(w io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return writeFamily(w, g.metricName, g.help, "gauge", g.labels, g.values)
+}
+
+// InfoVec 值恒为 1 的信息指标，标签名由运行时决定（如按配置导出的自定义标签），每次整体替换
+// 使用方式：monitor_info * on(provider) group_left(label_tier) 其他指标
+type InfoVec struct {
+	metricName string
+	help       string
+
+	mu     sync.Mutex
+	labels []string
+	values map[string]*series
+}
+
+// NewInfoVec 创建并注册信息指标
+func NewInfoVec(name, help string) *InfoVec {
+	v := &InfoVec{metricName: name, help: help, values: map[string]*series{}}
+	register(v)
+	return v
+}
+
+// Replace 以新的标签名和序列替换全部内容，rows 的每一行与 labels 一一对应
+func (v *InfoVec) Replace(labels []string, rows [][]string) {
+	values := make(map[string]*series, len(rows))
+	for _, row := range rows {
+		seriesFor(v.metricName, labels, values, row).value = 1
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.labels = append([]string(nil), labels...)
+	v.values = values
+}
+
+func (v *InfoVec) name() string { return v.metricName }
+
+func (v *InfoVec) write(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return writeFamily(w, v.metricName, v.help, "gauge", v.labels, v.values)
+}
+
+// HistogramVec 带标签的直方图（累计分桶 + 总和 + 次数）
+type HistogramVec struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64 // 升序的桶上界，不含 +Inf
+
+	mu     sync.Mutex
+	values map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // 与 buckets 一一对应，最后一个为 +Inf
+	sum         float64
+	count       uint64
+}
+
+// NewHistogramVec 创建并注册直方图，buckets 为升序的桶上界
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{metricName: name, help: help, labels: labels, buckets: buckets, values: map[string]*histogramSeries{}}
+	register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s 需要 %d 个标签值，实际 %d 个", h.metricName, len(h.labels), len(labelValues)))
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogramSeries{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets)+1)}
+		h.values[key] = s
+	}
+	i := sort.SearchFloat64s(h.buckets, v)
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+// Count 返回观测次数（主要用于测试）
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.values[strings.Join(labelValues, "\xff")]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) name() string { return h.metricName }
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.metricName, escapeHelp(h.help), h.metricName); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, k := range keys {
+		s := h.values[k]
+		var cumulative uint64
+		for i, c := range s.counts {
+			cumulative += c
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = strconv.FormatFloat(h.buckets[i], 'g', -1, 64)
+			}
+			values := append(append([]string(nil), s.labelValues...), le)
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, formatLabels(bucketLabels, values), cumulative); err != nil {
+				return err
+			}
+		}
+		labels := formatLabels(h.labels, s.labelValues)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.metricName, labels, strconv.FormatFloat(s.sum, 'g', -1, 64), h.metricName, labels, s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteText 以 Prometheus 文本格式输出全部已注册指标（按名称排序）
+func WriteText(w io.Writer) error {
+	registryMu.RLock()
+	collectors := make([]collector, 0, len(registry))
+	for _, c := range registry {
+		collectors = append(collectors, c)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 返回输出全部指标的 HTTP 处理器
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = WriteText(w)
+	})
+}
+
+func writeFamily(w io.Writer, name, help, typ string, labels []string, values map[string]*series) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := values[k]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels, s.labelValues), strconv.FormatFloat(s.value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = n + `="` + escapeLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCounterVecWriteText 验证计数器的文本格式输出
+func TestCounterVecWriteText(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "测试计数", "route")
+	c.Inc("/api/status")
+	c.Add(2, "/api/status")
+	c.Inc(`/a"b`)
+
+	if got := c.Value("/api/status"); got != 3 {
+		t.Fatalf("Value = %v, want 3", got)
+	}
+
+	var sb strings.Builder
+	if err := WriteText(&sb); err != nil {
+		t.Fatalf("WriteText 失败: %v", err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{route="/api/status"} 3`,
+		`test_requests_total{route="/a\"b"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestGaugeAndHistogramWriteText 验证瞬时值和直方图的文本格式输出
+func TestGaugeAndHistogramWriteText(t *testing.T) {
+	g := NewGaugeVec("test_in_flight", "测试瞬时值")
+	g.Add(2)
+	g.Add(-1)
+
+	h := NewHistogramVec("test_lag_seconds", "测试直方图", []float64{0.1, 1}, "provider")
+	h.Observe(0.05, "a")
+	h.Observe(0.1, "a")
+	h.Observe(3, "a")
+
+	if got := g.Value(); got != 1 {
+		t.Fatalf("Gauge Value = %v, want 1", got)
+	}
+	if got := h.Count("a"); got != 3 {
+		t.Fatalf("Histogram Count = %v, want 3", got)
+	}
+
+	var sb strings.Builder
+	if err := WriteText(&sb); err != nil {
+		t.Fatalf("WriteText 失败: %v", err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE test_in_flight gauge",
+		"test_in_flight 1",
+		"# TYPE test_lag_seconds histogram",
+		`test_lag_seconds_bucket{provider="a",le="0.1"} 2`,
+		`test_lag_seconds_bucket{provider="a",le="1"} 2`,
+		`test_lag_seconds_bucket{provider="a",le="+Inf"} 3`,
+		`test_lag_seconds_sum{provider="a"} 3.15`,
+		`test_lag_seconds_count{provider="a"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestInfoVecReplace 验证信息指标整体替换标签名和序列
+func TestInfoVecReplace(t *testing.T) {
+	v := NewInfoVec("test_monitor_info", "测试信息指标")
+	v.Replace([]string{"provider", "label_tier"}, [][]string{{"a", "vip"}, {"b", ""}})
+	v.Replace([]string{"provider", "label_region"}, [][]string{{"a", "hk"}})
+
+	g := NewGaugeVec("test_probe_status", "测试瞬时值", "provider")
+	g.Set(1, "a")
+	g.Set(0, "b")
+	g.Retain(func(labelValues []string) bool { return labelValues[0] == "a" })
+
+	var sb strings.Builder
+	if err := WriteText(&sb); err != nil {
+		t.Fatalf("WriteText 失败: %v", err)
+	}
+	out := sb.String()
+
+	if !strings.Contains(out, `test_monitor_info{provider="a",label_region="hk"} 1`) {
+		t.Errorf("输出缺少替换后的序列:\n%s", out)
+	}
+	for _, unwanted := range []string{"label_tier", `test_probe_status{provider="b"}`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("输出不应包含 %q:\n%s", unwanted, out)
+		}
+	}
+}
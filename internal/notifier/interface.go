@@ -7,6 +7,9 @@ type Notifier interface {
 	// Send 发送告警通知
 	Send(ctx context.Context, alert *Alert) error
 
+	// SendMessage 发送与具体监控项无关的系统通知（如配置重载失败），content 为 Markdown
+	SendMessage(ctx context.Context, title, content string) error
+
 	// Close 关闭通知器，清理资源
 	Close() error
 }
@@ -18,6 +21,9 @@ type Alert struct {
 	Service  string // 服务类型（如 "cc"）
 	Channel  string // 业务通道（如 "vip-channel"）
 
+	// 监控项的自定义标签（如 tier=vip），用于告警路由和消息模板
+	Labels map[string]string
+
 	// 状态信息
 	Status         int    // 当前状态（0=红色不可用, 1=绿色正常, 2=黄色降级）
 	PreviousStatus int    // 上次状态
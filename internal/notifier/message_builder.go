@@ -21,6 +21,8 @@ type TemplateData struct {
 	Timestamp      string
 	FailureCount   int
 	Latency        int
+	Labels         map[string]string // 自定义标签，模板中使用 {{.Labels.tier}}（未设置时为空）
+	LabelText      string            // 按名称排序的 "k=v, k=v"（无标签时为空）
 }
 
 // MessageBuilder 消息构造器
@@ -67,7 +69,8 @@ func (mb *MessageBuilder) compileTemplates() error {
 
 // compileTemplate 编译单个模板
 func (mb *MessageBuilder) compileTemplate(name, content string) error {
-	tmpl, err := template.New(name).Parse(content)
+	// missingkey=zero：未设置的标签（如 {{.Labels.owner}}）渲染为空字符串而不是 <no value>
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(content)
 	if err != nil {
 		return fmt.Errorf("编译模板 %s 失败: %w", name, err)
 	}
@@ -128,6 +131,8 @@ func (mb *MessageBuilder) prepareTemplateData(alert *Alert) *TemplateData {
 		Timestamp:      timestamp,
 		FailureCount:   alert.FailureCount,
 		Latency:        alert.Latency,
+		Labels:         alert.Labels,
+		LabelText:      config.FormatLabels(alert.Labels),
 	}
 }
 
@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -184,3 +185,114 @@ func TestMessageBuilder_InvalidTemplate(t *testing.T) {
 		t.Errorf("应该返回语法错误")
 	}
 }
+
+func TestMessageBuilder_Labels(t *testing.T) {
+	templates := config.GetDefaultMessageTemplates()
+	templates.Down = &config.MessageTemplate{
+		Title:   "故障",
+		Content: "{{.Provider}} tier={{.Labels.tier}} owner={{.Labels.owner}} [{{.LabelText}}]",
+	}
+	builder, err := NewMessageBuilder(templates)
+	if err != nil {
+		t.Fatalf("创建 MessageBuilder 失败: %v", err)
+	}
+
+	msg, err := builder.BuildMessage(&Alert{
+		Provider:  "Demo",
+		Labels:    map[string]string{"tier": "vip", "region": "hk"},
+		AlertType: AlertTypeDown,
+	})
+	if err != nil {
+		t.Fatalf("构造消息失败: %v", err)
+	}
+	// 未设置的标签渲染为空字符串
+	if !strings.Contains(msg, "Demo tier=vip owner= [region=hk, tier=vip]") {
+		t.Errorf("标签渲染不正确: %s", msg)
+	}
+
+	// 无标签的监控项
+	msg, err = builder.BuildMessage(&Alert{Provider: "Demo", AlertType: AlertTypeDown})
+	if err != nil || !strings.Contains(msg, "Demo tier= owner= []") {
+		t.Errorf("无标签时渲染不正确: %s, %v", msg, err)
+	}
+}
+
+func TestManager_RouteTargets(t *testing.T) {
+	defaultCh, vip, hk, all := &fakeNotifier{"default"}, &fakeNotifier{"vip"}, &fakeNotifier{"hk"}, &fakeNotifier{"vip-hk"}
+	m := &Manager{
+		notifiers: []Notifier{defaultCh},
+		routes: []alertRoute{
+			{name: "hk", matchLabels: map[string]string{"region": "hk"}, cont: true, notifier: hk},
+			{name: "vip", matchLabels: map[string]string{"tier": "vip"}, notifier: vip},
+			{name: "vip-hk", matchLabels: map[string]string{"tier": "vip", "region": "hk"}, notifier: all},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []Notifier
+	}{
+		{"无标签发送到默认渠道", nil, []Notifier{defaultCh}},
+		{"第一个匹配的路由处停止", map[string]string{"tier": "vip", "region": "hk"}, []Notifier{hk, vip}},
+		{"continue 路由同时发送到默认渠道", map[string]string{"region": "hk"}, []Notifier{hk, defaultCh}},
+		{"值不同不匹配", map[string]string{"tier": "free"}, []Notifier{defaultCh}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.targets(tt.labels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("targets = %d 个渠道, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("targets[%d] = %s, want %s", i, got[i].(*fakeNotifier).name, tt.want[i].(*fakeNotifier).name)
+				}
+			}
+		})
+	}
+}
+
+// fakeNotifier 测试用通知器
+type fakeNotifier struct{ name string }
+
+func (f *fakeNotifier) Send(context.Context, *Alert) error                { return nil }
+func (f *fakeNotifier) SendMessage(context.Context, string, string) error { return nil }
+func (f *fakeNotifier) Close() error                                      { return nil }
+
+// TestManager_UpdateConfigRebuildsRoutes 验证热更新时重建告警路由，新配置无效时保留原渠道
+func TestManager_UpdateConfigRebuildsRoutes(t *testing.T) {
+	wecom := func(url string) config.WeComConfig {
+		return config.WeComConfig{Enabled: true, WebhookURL: url, Templates: config.GetDefaultMessageTemplates()}
+	}
+	cfg := &config.NotifierConfig{
+		Enabled: true,
+		WeCom:   wecom("https://example.com/default"),
+		Routes:  []config.NotifierRoute{{Name: "vip", MatchLabels: map[string]string{"tier": "vip"}, WeCom: wecom("https://example.com/vip")}},
+	}
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	webhook := func(labels map[string]string) string {
+		return m.targets(labels)[0].(*WeComNotifier).webhookURL
+	}
+
+	updated := *cfg
+	updated.Routes = []config.NotifierRoute{{Name: "hk", MatchLabels: map[string]string{"region": "hk"}, WeCom: wecom("https://example.com/hk")}}
+	m.UpdateConfig(&updated)
+	if got := webhook(map[string]string{"tier": "vip"}); got != "https://example.com/default" {
+		t.Errorf("删除 vip 路由后 tier=vip 发送到 %s, want 默认渠道", got)
+	}
+	if got := webhook(map[string]string{"region": "hk"}); got != "https://example.com/hk" {
+		t.Errorf("新增 hk 路由后 region=hk 发送到 %s, want hk 路由", got)
+	}
+
+	// 无效配置（路由缺少 webhook）不影响现有渠道
+	invalid := updated
+	invalid.Routes = []config.NotifierRoute{{Name: "bad", WeCom: wecom("")}}
+	m.UpdateConfig(&invalid)
+	if got := webhook(map[string]string{"region": "hk"}); got != "https://example.com/hk" {
+		t.Errorf("无效配置后 region=hk 发送到 %s, want 保留 hk 路由", got)
+	}
+}
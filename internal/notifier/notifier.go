@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"reflect"
 	"sync"
 	"time"
 
@@ -13,46 +14,96 @@ import (
 
 // Manager 通知管理器（支持多种通知渠道）
 type Manager struct {
-	notifiers    []Notifier
+	notifiers    []Notifier   // 默认渠道
+	routes       []alertRoute // 按标签路由的渠道（按配置顺序匹配）
 	stateTracker *StateTracker
 	config       *config.NotifierConfig
 	mu           sync.RWMutex
+
+	// 配置重载失败通知去重：相同错误在 min_notify_interval 内只通知一次
+	configErrMu     sync.Mutex
+	lastConfigErr   string
+	lastConfigErrAt time.Time
+}
+
+// alertRoute 告警路由：标签匹配的告警发送到 notifier
+type alertRoute struct {
+	name        string
+	matchLabels map[string]string
+	cont        bool
+	notifier    Notifier
 }
 
+// maxConfigErrorLen 配置错误通知中错误信息的最大长度（企业微信 Markdown 消息上限 4096 字节）
+const maxConfigErrorLen = 1500
+
 // NewManager 创建通知管理器
 func NewManager(cfg *config.NotifierConfig) (*Manager, error) {
 	if !cfg.Enabled {
 		return nil, fmt.Errorf("通知功能未启用")
 	}
 
-	manager := &Manager{
-		notifiers:    make([]Notifier, 0),
+	notifiers, routes, err := buildChannels(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		notifiers:    notifiers,
+		routes:       routes,
 		stateTracker: NewStateTracker(cfg),
 		config:       cfg,
-	}
+	}, nil
+}
+
+// buildChannels 按配置创建默认渠道和告警路由渠道（至少需要一个渠道）
+func buildChannels(cfg *config.NotifierConfig) ([]Notifier, []alertRoute, error) {
+	notifiers := make([]Notifier, 0)
+	var routes []alertRoute
 
 	// 初始化企业微信通知器
 	if cfg.WeCom.Enabled {
 		if cfg.WeCom.WebhookURL == "" {
-			return nil, fmt.Errorf("企业微信 webhook_url 不能为空")
+			return nil, nil, fmt.Errorf("企业微信 webhook_url 不能为空")
 		}
 		wecom, err := NewWeComNotifier(&cfg.WeCom)
 		if err != nil {
-			return nil, fmt.Errorf("初始化企业微信通知器失败: %w", err)
+			return nil, nil, fmt.Errorf("初始化企业微信通知器失败: %w", err)
 		}
-		manager.notifiers = append(manager.notifiers, wecom)
+		notifiers = append(notifiers, wecom)
 		log.Printf("[Notifier] 企业微信通知器已启用")
 	}
 
-	if len(manager.notifiers) == 0 {
-		return nil, fmt.Errorf("未配置任何通知渠道")
+	// 初始化告警路由
+	for i, r := range cfg.Routes {
+		if !r.WeCom.Enabled {
+			continue
+		}
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("routes[%d]", i)
+		}
+		wecom, err := NewWeComNotifier(&r.WeCom)
+		if err != nil {
+			return nil, nil, fmt.Errorf("初始化告警路由 %s 失败: %w", name, err)
+		}
+		routes = append(routes, alertRoute{
+			name:        name,
+			matchLabels: r.MatchLabels,
+			cont:        r.Continue,
+			notifier:    wecom,
+		})
+		log.Printf("[Notifier] 告警路由 %s 已启用（%s）", name, config.FormatLabels(r.MatchLabels))
 	}
 
-	return manager, nil
+	if len(notifiers) == 0 && len(routes) == 0 {
+		return nil, nil, fmt.Errorf("未配置任何通知渠道")
+	}
+	return notifiers, routes, nil
 }
 
-// NotifyIfNeeded 检查是否需要发送告警，如需要则异步发送
-func (m *Manager) NotifyIfNeeded(ctx context.Context, result *monitor.ProbeResult) {
+// NotifyIfNeeded 检查是否需要发送告警，如需要则按监控项标签选择渠道异步发送
+func (m *Manager) NotifyIfNeeded(ctx context.Context, result *monitor.ProbeResult, labels map[string]string) {
 	if m == nil {
 		return
 	}
@@ -65,9 +116,10 @@ func (m *Manager) NotifyIfNeeded(ctx context.Context, result *monitor.ProbeResul
 	if alert == nil {
 		return // 无需告警
 	}
+	alert.Labels = labels
 
 	// 异步发送通知（不阻塞探测流程）
-	for _, notifier := range m.notifiers {
+	for _, notifier := range m.targets(labels) {
 		n := notifier // 避免闭包问题
 		go func() {
 			if err := n.Send(ctx, alert); err != nil {
@@ -81,6 +133,67 @@ func (m *Manager) NotifyIfNeeded(ctx context.Context, result *monitor.ProbeResul
 	}
 }
 
+// targets 返回标签匹配的路由渠道；没有匹配的路由（或匹配的路由都设置了 continue）时包含默认渠道
+func (m *Manager) targets(labels map[string]string) []Notifier {
+	var targets []Notifier
+	for _, r := range m.routes {
+		if !config.MatchLabels(labels, r.matchLabels) {
+			continue
+		}
+		targets = append(targets, r.notifier)
+		if !r.cont {
+			return targets
+		}
+	}
+	return append(targets, m.notifiers...)
+}
+
+// allNotifiers 返回默认渠道和全部路由渠道
+func (m *Manager) allNotifiers() []Notifier {
+	all := append([]Notifier(nil), m.notifiers...)
+	for _, r := range m.routes {
+		all = append(all, r.notifier)
+	}
+	return all
+}
+
+// NotifyConfigError 异步通知配置重载失败（旧配置仍在运行），相同错误在冷却期内不重复通知
+func (m *Manager) NotifyConfigError(ctx context.Context, file string, reloadErr error) {
+	if m == nil || reloadErr == nil {
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	errText := reloadErr.Error()
+	m.configErrMu.Lock()
+	if errText == m.lastConfigErr && time.Since(m.lastConfigErrAt) < m.config.MinNotifyIntervalDuration {
+		m.configErrMu.Unlock()
+		return
+	}
+	m.lastConfigErr, m.lastConfigErrAt = errText, time.Now()
+	m.configErrMu.Unlock()
+
+	if r := []rune(errText); len(r) > maxConfigErrorLen {
+		errText = string(r[:maxConfigErrorLen]) + "…"
+	}
+	title := "⚠️ 配置重载失败"
+	content := fmt.Sprintf("**配置文件**：%s\n**时间**：%s\n**错误**：<font color=\"warning\">%s</font>\n> 已保持旧配置继续运行，请修正后重新保存",
+		file, time.Now().Format("2006-01-02 15:04:05"), errText)
+
+	for _, notifier := range m.allNotifiers() {
+		n := notifier
+		go func() {
+			if err := n.SendMessage(ctx, title, content); err != nil {
+				log.Printf("[Notifier] 发送配置重载失败通知失败: %v", err)
+			} else {
+				log.Printf("[Notifier] 配置重载失败通知已发送")
+			}
+		}()
+	}
+}
+
 // UpdateConfig 热更新配置
 func (m *Manager) UpdateConfig(cfg *config.NotifierConfig) {
 	if m == nil {
@@ -90,10 +203,24 @@ func (m *Manager) UpdateConfig(cfg *config.NotifierConfig) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// 渠道配置（企业微信 Webhook、告警路由）变化时重建渠道；新配置无效时保留原渠道
+	if !reflect.DeepEqual(m.config.WeCom, cfg.WeCom) || !reflect.DeepEqual(m.config.Routes, cfg.Routes) {
+		notifiers, routes, err := buildChannels(cfg)
+		if err != nil {
+			log.Printf("[Notifier] ⚠️ 重建通知渠道失败，继续使用原渠道: %v", err)
+		} else {
+			for _, notifier := range m.allNotifiers() {
+				if err := notifier.Close(); err != nil {
+					log.Printf("[Notifier] 关闭通知器失败: %v", err)
+				}
+			}
+			m.notifiers, m.routes = notifiers, routes
+			log.Printf("[Notifier] 通知渠道已重建（默认渠道 %d 个，告警路由 %d 条）", len(notifiers), len(routes))
+		}
+	}
+
 	m.config = cfg
 	m.stateTracker.UpdateConfig(cfg)
-
-	// TODO: 支持热更新通知器列表（企业微信 Webhook URL 等）
 	log.Printf("[Notifier] 配置已更新")
 }
 
@@ -106,7 +233,7 @@ func (m *Manager) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for _, notifier := range m.notifiers {
+	for _, notifier := range m.allNotifiers() {
 		if err := notifier.Close(); err != nil {
 			log.Printf("[Notifier] 关闭通知器失败: %v", err)
 		}
@@ -50,6 +50,16 @@ func (w *WeComNotifier) Send(ctx context.Context, alert *Alert) error {
 		return fmt.Errorf("构造消息失败: %w", err)
 	}
 
+	return w.sendMarkdown(ctx, msg)
+}
+
+// SendMessage 发送系统通知（标题 + Markdown 正文）
+func (w *WeComNotifier) SendMessage(ctx context.Context, title, content string) error {
+	return w.sendMarkdown(ctx, fmt.Sprintf("## %s\n%s", title, content))
+}
+
+// sendMarkdown 发送 Markdown 消息（支持重试）
+func (w *WeComNotifier) sendMarkdown(ctx context.Context, msg string) error {
 	// 构造请求体（企业微信 Webhook API 格式）
 	reqBody := map[string]interface{}{
 		"msgtype": "markdown",
@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronExpr 标准 5 段 cron 表达式：分 时 日 月 周
+type cronExpr struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // 日 / 周字段为 * 时，按另一字段匹配（与 Vixie cron 一致）
+}
+
+// cronMacros 常用别名
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+var monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+func parseCron(spec string) (*cronExpr, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	if macro, ok := cronMacros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("需要 5 个字段（分 时 日 月 周），实际 %d 个", len(fields))
+	}
+
+	var (
+		expr cronExpr
+		err  error
+	)
+	if expr.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("分钟字段: %w", err)
+	}
+	if expr.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("小时字段: %w", err)
+	}
+	if expr.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("日期字段: %w", err)
+	}
+	if expr.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("月份字段: %w", err)
+	}
+	if expr.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("星期字段: %w", err)
+	}
+	if expr.dow&(1<<7) != 0 {
+		expr.dow |= 1 // 7 与 0 都表示周日
+	}
+	expr.domAny = fields[2] == "*" || fields[2] == "?"
+	expr.dowAny = fields[4] == "*" || fields[4] == "?"
+	return &expr, nil
+}
+
+// parseField 解析单个字段：支持 *、?、n、a-b、*/s、a-b/s、n/s 以及逗号分隔的列表，names 为可选的名称别名
+func parseField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("步长 '%s' 无效", stepPart)
+			}
+			step = n
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(from, min, max, names); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(to, min, max, names); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("范围 '%s' 无效", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			if !hasStep {
+				end = v
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int, names []string) (int, error) {
+	if i := indexOf(names, s); i >= 0 && s != "" {
+		return i, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("取值 '%s' 超出范围 %d-%d", s, min, max)
+	}
+	return v, nil
+}
+
+// matchDay 按 Vixie cron 规则匹配日期：日、周字段都有限定时满足其一即可
+func (c *cronExpr) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// next 返回 (after, until] 内的第一个触发时刻（按 after 所在时区计算），不存在时返回零值
+func (c *cronExpr) next(after, until time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+
+	for !t.After(until) {
+		switch {
+		case c.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
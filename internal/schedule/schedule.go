@@ -0,0 +1,270 @@
+// Package schedule 解析监控项的探测日程：5 段 cron 表达式、时区以及活跃时段 / 活跃星期
+package schedule
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // 精简镜像（如 alpine）可能没有系统时区数据库
+)
+
+// Spec 解析后的探测日程（只读，可在多个 goroutine 间共享）
+type Spec struct {
+	cron    *cronExpr      // nil 表示按 interval 探测
+	loc     *time.Location // cron 与活跃时段使用的时区
+	windows []window       // 活跃时段（一天内的分钟区间），为空表示全天
+	days    uint8          // 活跃星期位图（bit0=周日），0 表示每天
+}
+
+// window 一天内的活跃区间 [start, end)，单位为分钟；end <= start 表示跨午夜
+type window struct {
+	start, end int
+}
+
+// Parse 解析探测日程
+// cronSpec 为空表示按 interval 探测；timezone 为空使用服务器本地时区；
+// activeHours 形如 "09:00-18:00" 或 "09:00-12:00,14:00-18:00"（可跨午夜，如 "22:00-06:00"）；
+// activeDays 为星期名称或范围，如 ["mon-fri"]、["sat", "sun"]
+func Parse(cronSpec, timezone, activeHours string, activeDays []string) (*Spec, error) {
+	spec := &Spec{loc: time.Local}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("timezone '%s' 无效: %w", timezone, err)
+		}
+		spec.loc = loc
+	}
+
+	if cronSpec != "" {
+		expr, err := parseCron(cronSpec)
+		if err != nil {
+			return nil, fmt.Errorf("cron '%s' 无效: %w", cronSpec, err)
+		}
+		spec.cron = expr
+	}
+
+	if activeHours != "" {
+		for _, part := range strings.Split(activeHours, ",") {
+			w, err := parseWindow(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("active_hours '%s' 无效: %w", activeHours, err)
+			}
+			spec.windows = append(spec.windows, w)
+		}
+	}
+
+	for _, d := range activeDays {
+		mask, err := parseDays(strings.ToLower(strings.TrimSpace(d)))
+		if err != nil {
+			return nil, fmt.Errorf("active_days '%s' 无效: %w", d, err)
+		}
+		spec.days |= mask
+	}
+
+	if spec.cron != nil {
+		ref := time.Date(2000, 1, 1, 0, 0, 0, 0, spec.loc)
+		if spec.next(ref, ref.AddDate(8, 0, 0)).IsZero() {
+			return nil, fmt.Errorf("cron '%s' 在活跃时段内永远不会触发", cronSpec)
+		}
+	} else if spec.days != 0 || len(spec.windows) > 0 {
+		ref := time.Date(2000, 1, 1, 0, 0, 0, 0, spec.loc)
+		if spec.nextActive(ref, ref.AddDate(0, 0, 8)).IsZero() {
+			return nil, fmt.Errorf("活跃时段为空")
+		}
+	}
+	return spec, nil
+}
+
+// HasCron 返回是否按 cron 表达式探测
+func (s *Spec) HasCron() bool {
+	return s != nil && s.cron != nil
+}
+
+// Location 返回日程使用的时区
+func (s *Spec) Location() *time.Location {
+	if s == nil {
+		return time.Local
+	}
+	return s.loc
+}
+
+// MaxPerHour 返回 cron 表达式在任意一小时内的最多触发次数（未配置 cron 时为 0）
+func (s *Spec) MaxPerHour() int {
+	if !s.HasCron() {
+		return 0
+	}
+	return bits.OnesCount64(s.cron.minute)
+}
+
+// Active 判断时刻 t 是否在活跃时段内
+func (s *Spec) Active(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	t = t.In(s.loc)
+	minute := t.Hour()*60 + t.Minute()
+	if len(s.windows) == 0 {
+		return s.dayAllowed(t.Weekday())
+	}
+	for _, w := range s.windows {
+		switch {
+		case w.start < w.end:
+			if minute >= w.start && minute < w.end && s.dayAllowed(t.Weekday()) {
+				return true
+			}
+		case minute >= w.start:
+			// 跨午夜区间的前半段，归属当天
+			if s.dayAllowed(t.Weekday()) {
+				return true
+			}
+		case minute < w.end:
+			// 跨午夜区间的后半段，归属前一天
+			if s.dayAllowed((t.Weekday() + 6) % 7) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Fired 判断 (after, until] 内是否有活跃时段内的 cron 触发时刻（未配置 cron 时返回 false）
+func (s *Spec) Fired(after, until time.Time) bool {
+	if !s.HasCron() {
+		return false
+	}
+	next := s.next(after, until)
+	return !next.IsZero()
+}
+
+// ScheduledBetween 判断 [start, end) 内是否安排了探测：
+// 配置 cron 时为区间内是否有活跃的触发时刻，否则为区间是否与活跃时段重叠
+func (s *Spec) ScheduledBetween(start, end time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if s.cron != nil {
+		return !s.next(start.Add(-time.Nanosecond), end.Add(-time.Nanosecond)).IsZero()
+	}
+	return !s.nextActive(start, end).IsZero()
+}
+
+func (s *Spec) dayAllowed(d time.Weekday) bool {
+	return s.days == 0 || s.days&(1<<d) != 0
+}
+
+// next 返回 (after, until] 内第一个处于活跃时段的 cron 触发时刻，不存在时返回零值
+func (s *Spec) next(after, until time.Time) time.Time {
+	t := after
+	for {
+		fire := s.cron.next(t.In(s.loc), until)
+		if fire.IsZero() || s.Active(fire) {
+			return fire
+		}
+		// 跳过非活跃时段，避免逐分钟遍历
+		resume := s.nextActive(fire, until)
+		if resume.IsZero() {
+			return time.Time{}
+		}
+		t = resume.Add(-time.Nanosecond)
+	}
+}
+
+// nextActive 返回 [from, until) 内第一个处于活跃时段的时刻（分钟精度），不存在时返回零值
+func (s *Spec) nextActive(from, until time.Time) time.Time {
+	if s.Active(from) {
+		if from.Before(until) {
+			return from
+		}
+		return time.Time{}
+	}
+
+	// 候选时刻：之后每一天的 00:00（仅限定星期时）和各活跃区间的起点
+	local := from.In(s.loc)
+	var best time.Time
+	for day := 0; day <= 8; day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, s.loc)
+		starts := []int{0}
+		for _, w := range s.windows {
+			starts = append(starts, w.start)
+		}
+		for _, m := range starts {
+			c := date.Add(time.Duration(m) * time.Minute)
+			if c.After(from) && s.Active(c) && (best.IsZero() || c.Before(best)) {
+				best = c
+			}
+		}
+		if !best.IsZero() {
+			break
+		}
+	}
+	if best.IsZero() || !best.Before(until) {
+		return time.Time{}
+	}
+	return best
+}
+
+// parseWindow 解析 "HH:MM-HH:MM"
+func parseWindow(s string) (window, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return window{}, fmt.Errorf("格式应为 HH:MM-HH:MM")
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return window{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return window{}, err
+	}
+	if start == end {
+		return window{}, fmt.Errorf("起止时间不能相同")
+	}
+	return window{start: start, end: end % (24 * 60)}, nil
+}
+
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("时间 '%s' 无效", s)
+	}
+	return h*60 + m, nil
+}
+
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseDays 解析星期名称或范围（如 "mon-fri"、"sat-sun"）
+func parseDays(s string) (uint8, error) {
+	from, to, isRange := strings.Cut(s, "-")
+	start := indexOf(dayNames, from)
+	end := start
+	if isRange {
+		end = indexOf(dayNames, to)
+	}
+	if start < 0 || end < 0 {
+		return 0, fmt.Errorf("可选值: %s", strings.Join(dayNames, ", "))
+	}
+
+	var mask uint8
+	for d := start; ; d = (d + 1) % 7 {
+		mask |= 1 << d
+		if d == end {
+			break
+		}
+	}
+	return mask, nil
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
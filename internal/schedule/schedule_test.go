@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec    string
+		after   string
+		want    string
+		wantErr bool
+	}{
+		{"*/15 * * * *", "2025-01-06 10:07", "2025-01-06 10:15", false},
+		{"0 3 * * *", "2025-01-06 10:07", "2025-01-07 03:00", false},
+		{"30 9 * * mon-fri", "2025-01-10 10:00", "2025-01-13 09:30", false}, // 周五之后跳到下周一
+		{"0 0 1,15 * *", "2025-01-02 00:00", "2025-01-15 00:00", false},
+		{"0 12 * jan-mar 7", "2025-03-30 13:00", "2026-01-04 12:00", false}, // 7 表示周日
+		{"@hourly", "2025-01-06 10:07", "2025-01-06 11:00", false},
+		{"0 0 30 2 *", "", "", true}, // 永远不会触发
+		{"60 * * * *", "", "", true},
+		{"* * *", "", "", true},
+		{"*/0 * * * *", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := Parse(tt.spec, "UTC", "", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			after := mustTime(t, tt.after)
+			got := spec.next(after, after.AddDate(2, 0, 0))
+			if !got.Equal(mustTime(t, tt.want)) {
+				t.Errorf("next(%s) = %v, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveWindows(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Parse("", "Asia/Shanghai", "09:00-12:00,22:00-02:00", []string{"mon-fri"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	loc := spec.Location()
+	at := func(s string) time.Time {
+		v, _ := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		return v
+	}
+
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2025-01-06 09:00", true},  // 周一
+		{"2025-01-06 12:00", false}, // 结束时间不含
+		{"2025-01-06 23:30", true},
+		{"2025-01-07 01:30", true},  // 周一晚间区间跨到周二凌晨
+		{"2025-01-11 01:30", true},  // 周五晚间区间跨到周六凌晨
+		{"2025-01-11 10:00", false}, // 周六
+		{"2025-01-12 23:00", false}, // 周日
+	}
+	for _, tt := range tests {
+		if got := spec.Active(at(tt.at)); got != tt.want {
+			t.Errorf("Active(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if !spec.ScheduledBetween(at("2025-01-06 08:00"), at("2025-01-06 09:01")) {
+		t.Error("与活跃时段重叠的区间应视为已排期")
+	}
+	if spec.ScheduledBetween(at("2025-01-11 02:00"), at("2025-01-13 00:00")) {
+		t.Error("周末应视为未排期")
+	}
+}
+
+func TestCronWithinActiveHours(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Parse("0 * * * *", "UTC", "09:00-18:00", nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !spec.Fired(mustTime(t, "2025-01-06 09:30"), mustTime(t, "2025-01-06 10:00")) {
+		t.Error("10:00 在活跃时段内应触发")
+	}
+	if spec.Fired(mustTime(t, "2025-01-06 18:00"), mustTime(t, "2025-01-07 08:59")) {
+		t.Error("活跃时段外不应触发")
+	}
+	if got := spec.next(mustTime(t, "2025-01-06 17:30"), mustTime(t, "2025-01-08 00:00")); !got.Equal(mustTime(t, "2025-01-07 09:00")) {
+		t.Errorf("next = %v, want 次日 09:00", got)
+	}
+	if spec.MaxPerHour() != 1 {
+		t.Errorf("MaxPerHour() = %d, want 1", spec.MaxPerHour())
+	}
+
+	if _, err := Parse("", "Mars/Olympus", "", nil); err == nil {
+		t.Error("无效时区应返回错误")
+	}
+	if _, err := Parse("", "", "9-18", nil); err == nil {
+		t.Error("无效活跃时段应返回错误")
+	}
+	if _, err := Parse("", "", "", []string{"weekday"}); err == nil {
+		t.Error("无效星期应返回错误")
+	}
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+	if err != nil {
+		t.Fatalf("解析时间 %q 失败: %v", s, err)
+	}
+	return v
+}
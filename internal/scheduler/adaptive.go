@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// budgetWindow 服务商探测预算的统计窗口
+const budgetWindow = time.Hour
+
+// adaptiveTracker 记录每个监控项的最近状态，按自适应探测频率判定计划探测是否到期
+// 状态仅保存在内存中，重启后所有监控项从常规间隔重新开始
+type adaptiveTracker struct {
+	mu        sync.Mutex
+	states    map[config.MonitorKey]*adaptiveState
+	extra     map[string][]time.Time // 服务商 -> 统计窗口内的额外探测时间
+	exhausted map[string]bool        // 服务商预算已用完（用于只记录一次日志）
+}
+
+// adaptiveState 单个监控项的调度状态
+type adaptiveState struct {
+	status     int       // 最近一次结果（-1 表示尚无结果）
+	greenSince time.Time // 连续绿色的起始时间
+	lastProbe  time.Time // 最近一次调度时间
+	lastBase   time.Time // 最近一次按常规间隔调度的时间
+	lastEval   time.Time // 最近一次判定 cron 触发的时间
+}
+
+func newAdaptiveTracker() *adaptiveTracker {
+	return &adaptiveTracker{
+		states:    make(map[config.MonitorKey]*adaptiveState),
+		extra:     make(map[string][]time.Time),
+		exhausted: make(map[string]bool),
+	}
+}
+
+// failing 最近一次结果是否为红色或黄色
+func (st *adaptiveState) failing() bool {
+	return st.status == 0 || st.status == 2
+}
+
+// interval 返回监控项当前生效的探测间隔
+func (st *adaptiveState) interval(policy config.AdaptiveConfig, base time.Duration, now time.Time) time.Duration {
+	switch {
+	case st.failing() && policy.IncidentIntervalDuration > 0:
+		return policy.IncidentIntervalDuration
+	case st.status == 1 && policy.BackoffIntervalDuration > 0 && now.Sub(st.greenSince) >= policy.BackoffAfterDuration:
+		return policy.BackoffIntervalDuration
+	}
+	return base
+}
+
+func (a *adaptiveTracker) state(key config.MonitorKey) *adaptiveState {
+	st, ok := a.states[key]
+	if !ok {
+		st = &adaptiveState{status: -1}
+		a.states[key] = st
+	}
+	return st
+}
+
+// plan 判定监控项在计划时刻 now（所在节拍的起点）是否需要探测，需要时记录调度时间
+// slack 为判定容差（监控项节拍的一半），抵消调度抖动；超出常规节奏的加速探测需占用服务商预算
+func (a *adaptiveTracker) plan(cfg *config.AppConfig, task *config.ServiceConfig, now time.Time, slack time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := a.state(config.KeyOf(*task))
+
+	// 探测日程：配置 cron 时在上次判定之后有触发时刻才探测（不参与自适应频率），否则活跃时段外不探测
+	spec := task.ScheduleSpec
+	if spec.HasCron() {
+		prev := st.lastEval
+		if prev.IsZero() {
+			prev = now.Add(-2 * slack)
+		}
+		st.lastEval = now
+		if !spec.Fired(prev, now) {
+			return false
+		}
+		st.lastProbe, st.lastBase = now, now
+		return true
+	}
+	if !spec.Active(now) {
+		return false
+	}
+
+	base := cfg.IntervalDuration
+	if !st.lastProbe.IsZero() && now.Sub(st.lastProbe) < st.interval(task.AdaptivePolicy, base, now)-slack {
+		return false
+	}
+
+	if !st.lastBase.IsZero() && now.Sub(st.lastBase) < base-slack {
+		if !a.takeExtraLocked(cfg, task.Provider, now) {
+			return false
+		}
+	} else {
+		st.lastBase = now
+	}
+	st.lastProbe = now
+	return true
+}
+
+// markProbed 记录一次不经到期判定的探测（启动或手动触发的全量巡检），视为常规探测
+func (a *adaptiveTracker) markProbed(key config.MonitorKey, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := a.state(key)
+	st.lastProbe = now
+	st.lastBase = now
+}
+
+// record 记录探测结果，用于决定下一次探测间隔
+// 返回监控项是否在正常与故障（红色或黄色）之间切换，切换后调度节拍随之变化
+func (a *adaptiveTracker) record(key config.MonitorKey, status int, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := a.state(key)
+	wasFailing := st.failing()
+	if status == 1 && st.status != 1 {
+		st.greenSince = now
+	}
+	st.status = status
+	return st.failing() != wasFailing
+}
+
+// failing 监控项最近一次结果是否为红色或黄色（尚无结果时为 false）
+func (a *adaptiveTracker) failing(key config.MonitorKey) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st, ok := a.states[key]
+	return ok && st.failing()
+}
+
+// takeExtra 为一次额外探测（如失败确认重试）占用服务商预算，预算不足时返回 false
+func (a *adaptiveTracker) takeExtra(cfg *config.AppConfig, provider string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.takeExtraLocked(cfg, provider, now)
+}
+
+func (a *adaptiveTracker) takeExtraLocked(cfg *config.AppConfig, provider string, now time.Time) bool {
+	limit, ok := cfg.ExtraProbeBudget(provider)
+	if !ok {
+		return true
+	}
+
+	window := a.extra[provider]
+	cutoff := now.Add(-budgetWindow)
+	drop := 0
+	for drop < len(window) && !window[drop].After(cutoff) {
+		drop++
+	}
+	window = window[drop:]
+
+	if len(window) >= limit {
+		a.extra[provider] = window
+		if !a.exhausted[provider] {
+			a.exhausted[provider] = true
+			log.Printf("[Scheduler] 服务商 %s 的额外探测预算已用完（%d 次/小时），暂按常规间隔探测", provider, limit)
+		}
+		return false
+	}
+	a.extra[provider] = append(window, now)
+	a.exhausted[provider] = false
+	return true
+}
+
+// prune 清理已从配置中移除的监控项状态
+func (a *adaptiveTracker) prune(cfg *config.AppConfig) {
+	keep := make(map[config.MonitorKey]bool, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		keep[config.KeyOf(m)] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key := range a.states {
+		if !keep[key] {
+			delete(a.states, key)
+		}
+	}
+}
+
+// monitorTick 返回监控项的调度节拍：常规间隔 interval，故障期间（failing）取与故障期间探测间隔中的较小值
+// 正常状态下按常规间隔排队，错峰偏移在整个巡检周期内展开，避免加速配置让全部监控项挤进同一个短节拍
+// 按 cron 探测时不参与自适应频率，节拍不超过 1 分钟（cron 的最小粒度）
+func monitorTick(cfg *config.AppConfig, task *config.ServiceConfig, failing bool) time.Duration {
+	tick := cfg.IntervalDuration
+	if tick <= 0 {
+		tick = time.Minute
+	}
+	if task.ScheduleSpec.HasCron() {
+		return min(tick, time.Minute)
+	}
+	if d := task.AdaptivePolicy.IncidentIntervalDuration; failing && d > 0 && d < tick {
+		tick = d
+	}
+	return tick
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// TestAdaptivePlan 验证故障加速、长期绿色降频和服务商预算
+func TestAdaptivePlan(t *testing.T) {
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		ProviderBudgets:  map[string]int{"Demo": 62}, // 常规探测 60 次/小时，额外探测 2 次
+		Monitors: []config.ServiceConfig{{
+			Provider: "Demo",
+			Service:  "cc",
+			AdaptivePolicy: config.AdaptiveConfig{
+				IncidentIntervalDuration: 15 * time.Second,
+				BackoffIntervalDuration:  5 * time.Minute,
+				BackoffAfterDuration:     time.Hour,
+			},
+		}},
+	}
+	task := &cfg.Monitors[0]
+	key := config.KeyOf(*task)
+	slack := monitorTick(cfg, task, true) / 2
+	if slack != 7500*time.Millisecond {
+		t.Fatalf("节拍应为故障加速间隔, slack = %v", slack)
+	}
+
+	a := newAdaptiveTracker()
+	start := time.Unix(1735689600, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	if !a.plan(cfg, task, at(0), slack) {
+		t.Fatal("首次巡检应探测")
+	}
+	if a.plan(cfg, task, at(15*time.Second), slack) {
+		t.Error("尚无故障时应按常规间隔探测")
+	}
+
+	// 故障期间按 15s 加速，额外探测受预算限制
+	a.record(key, 0, at(time.Second))
+	if !a.plan(cfg, task, at(15*time.Second), slack) || !a.plan(cfg, task, at(30*time.Second), slack) {
+		t.Fatal("故障期间应加速探测")
+	}
+	if a.plan(cfg, task, at(45*time.Second), slack) {
+		t.Error("额外探测预算用完后不应加速")
+	}
+	if !a.plan(cfg, task, at(time.Minute), slack) {
+		t.Error("预算用完后仍应按常规间隔探测")
+	}
+
+	// 恢复后按常规间隔，连续绿色超过 backoff_after 后降频
+	a.record(key, 1, at(time.Minute))
+	if a.plan(cfg, task, at(75*time.Second), slack) || !a.plan(cfg, task, at(2*time.Minute), slack) {
+		t.Error("恢复后应回到常规间隔")
+	}
+	if !a.plan(cfg, task, at(61*time.Minute), slack) {
+		t.Fatal("常规间隔到期应探测")
+	}
+	if a.plan(cfg, task, at(62*time.Minute), slack) {
+		t.Error("连续绿色超过 backoff_after 后应降频")
+	}
+	if !a.plan(cfg, task, at(66*time.Minute), slack) {
+		t.Error("降频间隔到期应探测")
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"sort"
+
+	"monitor/internal/config"
+	"monitor/internal/metrics"
+	"monitor/internal/monitor"
+)
+
+var (
+	// monitorInfo 每个监控项一条序列，携带分类和自定义标签（label_<名称>），用于与其他指标关联：
+	// monitor_probe_status * on(provider, service, channel) group_left(label_tier) monitor_info
+	monitorInfo = metrics.NewInfoVec(
+		"monitor_info",
+		"Configured monitors with their category and custom labels (label_<name>); always 1.",
+	)
+
+	// probeStatus 监控项最近一次探测的状态
+	probeStatus = metrics.NewGaugeVec(
+		"monitor_probe_status",
+		"Status of the latest probe of each monitor (1 = up, 2 = degraded, 0 = down).",
+		"provider", "service", "channel",
+	)
+
+	// probeLatency 监控项最近一次探测的延迟
+	probeLatency = metrics.NewGaugeVec(
+		"monitor_probe_latency_seconds",
+		"Latency of the latest probe of each monitor.",
+		"provider", "service", "channel",
+	)
+)
+
+// recordProbeMetrics 记录探测结果对应的指标
+func recordProbeMetrics(result *monitor.ProbeResult) {
+	probeStatus.Set(float64(result.Status), result.Provider, result.Service, result.Channel)
+	probeLatency.Set(float64(result.Latency)/1000, result.Provider, result.Service, result.Channel)
+}
+
+// updateMonitorMetrics 按配置重建 monitor_info，并删除已移除监控项的探测指标
+// 标签名取所有监控项自定义标签的并集，未设置该标签的监控项取空值
+func updateMonitorMetrics(cfg *config.AppConfig) {
+	keySet := make(map[string]bool)
+	monitors := make(map[config.MonitorKey]bool, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		for k := range m.Labels {
+			keySet[k] = true
+		}
+		monitors[config.KeyOf(m)] = true
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	labels := []string{"provider", "service", "channel", "category"}
+	for _, k := range keys {
+		labels = append(labels, "label_"+k)
+	}
+	rows := make([][]string, 0, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		row := []string{m.Provider, m.Service, m.Channel, m.Category}
+		for _, k := range keys {
+			row = append(row, m.Labels[k])
+		}
+		rows = append(rows, row)
+	}
+	monitorInfo.Replace(labels, rows)
+
+	keep := func(labelValues []string) bool {
+		return monitors[config.MonitorKey{Provider: labelValues[0], Service: labelValues[1], Channel: labelValues[2]}]
+	}
+	probeStatus.Retain(keep)
+	probeLatency.Retain(keep)
+}
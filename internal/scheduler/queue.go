@@ -0,0 +1,305 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"time"
+
+	"monitor/internal/config"
+	"monitor/internal/metrics"
+)
+
+var (
+	// schedulerLag 探测计划时刻到实际发起请求的延迟（含等待全局并发和主机/服务商限流的时间）
+	schedulerLag = metrics.NewHistogramVec(
+		"monitor_scheduler_lag_seconds",
+		"Delay between a probe's scheduled time and the moment it actually started.",
+		[]float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		"provider",
+	)
+
+	// schedulerQueued 已到期、正在等待并发名额或主机请求间隔的探测数
+	schedulerQueued = metrics.NewGaugeVec(
+		"monitor_scheduler_queued_probes",
+		"Probes that are due but waiting for a concurrency slot or host spacing.",
+	)
+
+	// schedulerInFlight 进行中的探测数（含失败确认重试）
+	schedulerInFlight = metrics.NewGaugeVec(
+		"monitor_scheduler_in_flight_probes",
+		"Monitors with a probe in progress, including confirmation retries.",
+	)
+
+	// schedulerOverruns 上一次探测尚未结束而跳过的计划探测数
+	schedulerOverruns = metrics.NewCounterVec(
+		"monitor_scheduler_overruns_total",
+		"Scheduled probes skipped because the previous probe of the same monitor was still running.",
+		"provider", "service", "channel",
+	)
+)
+
+// staggerSpread 错峰相位分布在节拍的前 80%，留出时间让探测在下一拍开始前完成
+const staggerSpread = 0.8
+
+// queueItem 调度队列中的监控项：每个监控项独立排队，到期后派发探测并立即排入下一个节拍
+type queueItem struct {
+	task int           // 在 queueCfg.Monitors 中的下标
+	tick time.Duration // 监控项的节拍
+	slot time.Time     // 所在节拍的起点（节拍的整数倍，对齐墙上时钟）
+	at   time.Time     // 计划检查时刻：slot + 错峰偏移
+}
+
+// probeQueue 按计划时刻排序的最小堆
+type probeQueue []*queueItem
+
+func (q probeQueue) Len() int           { return len(q) }
+func (q probeQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+func (q probeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *probeQueue) Push(x any)        { *q = append(*q, x.(*queueItem)) }
+func (q *probeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// loop 调度循环：等待队首监控项到期后派发探测，慢探测只影响自身，不阻塞其他监控项
+// 配置变化或调度器停止时由 wake 唤醒；队列为空时每个 interval 空转一次，用于健康检查
+func (s *Scheduler) loop(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	rebuild := true
+	for {
+		s.mu.Lock()
+		running, interval := s.running, s.interval
+		s.mu.Unlock()
+		if !running {
+			return
+		}
+
+		now := time.Now()
+		if rebuild {
+			s.rebuildQueue(now)
+			rebuild = false
+		}
+		next := now.Add(interval)
+		if len(s.queue) > 0 && s.queue[0].at.Before(next) {
+			next = s.queue[0].at
+		}
+		timer.Reset(next.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Scheduler] 调度器已停止")
+			s.mu.Lock()
+			s.running = false
+			s.mu.Unlock()
+			return
+
+		case <-s.wake:
+			rebuild = true
+
+		case <-timer.C:
+			s.dispatch(ctx, time.Now())
+		}
+	}
+}
+
+// rebuildQueue 按当前配置重建调度队列
+// 计划时刻只由监控项标识和节拍决定，重建不会改变已有监控项的探测节奏
+func (s *Scheduler) rebuildQueue(now time.Time) {
+	cfg := s.currentConfig()
+	s.queueCfg = cfg
+	s.queue = s.queue[:0]
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.Monitors {
+		task := &cfg.Monitors[i]
+		item := &queueItem{task: i, tick: monitorTick(cfg, task, s.adaptive.failing(config.KeyOf(*task)))}
+		s.scheduleItem(cfg, item, now)
+		s.queue = append(s.queue, item)
+	}
+	heap.Init(&s.queue)
+}
+
+// resizeSemLocked 按配置调整全局并发信号量（调用方需持有 s.mu）
+// 上限变化时替换信号量，进行中的探测在旧信号量上释放
+func (s *Scheduler) resizeSemLocked(cfg *config.AppConfig) {
+	// MaxConcurrency 语义：
+	// - -1: 无限制，自动扩容到监控项数量
+	// - >0: 硬上限，超过时到期的探测排队等待
+	size := cfg.MaxConcurrency
+	if size == -1 {
+		size = len(cfg.Monitors)
+	}
+	size = max(size, 1)
+
+	if s.sem != nil && cap(s.sem) == size {
+		return
+	}
+	s.sem = make(chan struct{}, size)
+	if cfg.MaxConcurrency == -1 {
+		log.Printf("[Scheduler] 并发模式: 无限制 (并发数=%d)", size)
+	} else if len(cfg.Monitors) > size {
+		log.Printf("[Scheduler] 并发模式: 硬上限 (上限=%d, 监控项=%d, 超出时排队)", size, len(cfg.Monitors))
+	} else {
+		log.Printf("[Scheduler] 并发模式: 正常 (并发数=%d, 监控项=%d)", size, len(cfg.Monitors))
+	}
+}
+
+// scheduleItem 计算监控项在 after 之后（含）的下一个计划时刻
+func (s *Scheduler) scheduleItem(cfg *config.AppConfig, item *queueItem, after time.Time) {
+	key := config.KeyOf(cfg.Monitors[item.task])
+	item.slot = after.Truncate(item.tick)
+	item.at = item.slot.Add(s.staggerDelay(cfg, key, item.tick))
+	if item.at.Before(after) {
+		item.slot = item.slot.Add(item.tick)
+		item.at = item.slot.Add(s.staggerDelay(cfg, key, item.tick))
+	}
+}
+
+// dispatch 派发所有已到期的监控项，并把它们排入各自的下一个节拍
+func (s *Scheduler) dispatch(ctx context.Context, now time.Time) {
+	s.checkMu.Lock()
+	s.lastDispatch = now
+	standby := s.standby
+	s.checkMu.Unlock()
+
+	cfg := s.queueCfg
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		item := s.queue[0]
+		slot, at := item.slot, item.at
+
+		// 调度循环被长时间阻塞（如系统休眠）时跳过错过的节拍
+		next := slot.Add(item.tick)
+		if now.After(next) {
+			next = now
+		}
+		s.scheduleItem(cfg, item, next)
+		heap.Fix(&s.queue, 0)
+
+		if standby {
+			continue // 备节点不巡检，由主节点负责
+		}
+		task := &cfg.Monitors[item.task]
+		s.launch(ctx, cfg, task, at, func() bool {
+			// 自适应探测频率与探测日程：按节拍起点判定，错峰偏移和抖动不影响到期判断
+			return s.adaptive.plan(cfg, task, slot, item.tick/2)
+		})
+	}
+}
+
+// runAll 立即探测全部处于活跃时段的监控项（启动、手动触发、转为主节点时）
+func (s *Scheduler) runAll(ctx context.Context) {
+	if s.isStandby() {
+		return
+	}
+	cfg := s.currentConfig()
+	if cfg == nil || len(cfg.Monitors) == 0 {
+		return
+	}
+
+	now := time.Now()
+	count := 0
+	for i := range cfg.Monitors {
+		task := &cfg.Monitors[i]
+		if !task.ScheduleSpec.Active(now) {
+			continue
+		}
+		if s.launch(ctx, cfg, task, now, func() bool {
+			s.adaptive.markProbed(config.KeyOf(*task), now)
+			return true
+		}) {
+			count++
+		}
+	}
+	log.Printf("[Scheduler] 立即探测 %d 个监控项（共 %d 个）", count, len(cfg.Monitors))
+}
+
+// launch 在独立的 goroutine 中探测单个监控项，返回是否已派发
+// 同一监控项的上一次探测尚未结束时跳过本次（记为 overrun）；due 返回 false 时表示本次无需探测
+func (s *Scheduler) launch(ctx context.Context, cfg *config.AppConfig, task *config.ServiceConfig, scheduled time.Time, due func() bool) bool {
+	// 分片模式：按最新成员列表判断归属
+	if s.taskFilter != nil && !s.taskFilter(*task) {
+		return false
+	}
+
+	key := config.KeyOf(*task)
+	s.checkMu.Lock()
+	if s.inFlight[key] {
+		s.checkMu.Unlock()
+		schedulerOverruns.Inc(task.Provider, task.Service, task.Channel)
+		log.Printf("[Scheduler] %s 上一次探测尚未结束，跳过本次", key)
+		return false
+	}
+	if !due() {
+		s.checkMu.Unlock()
+		return false
+	}
+	s.inFlight[key] = true
+	s.checkMu.Unlock()
+
+	s.mu.Lock()
+	sem := s.sem
+	s.mu.Unlock()
+
+	schedulerInFlight.Add(1)
+	go func() {
+		defer func() {
+			schedulerInFlight.Add(-1)
+			s.checkMu.Lock()
+			delete(s.inFlight, key)
+			s.lastProbeEnd = time.Now()
+			s.checkMu.Unlock()
+		}()
+
+		// 执行探测（红色结果按失败确认策略重试）
+		result, attempts := s.probeWithConfirm(ctx, cfg, task, sem, scheduled)
+		if result == nil {
+			return // context 取消
+		}
+		if s.adaptive.record(key, result.Status, time.Now()) {
+			// 进入或离开故障状态后节拍变化，唤醒调度循环按新节拍重排
+			s.mu.Lock()
+			s.wakeLocked()
+			s.mu.Unlock()
+		}
+
+		// 保存结果并触发告警检查
+		if err := s.handleResult(ctx, result, attempts, task.Labels); err != nil {
+			log.Printf("[Scheduler] 保存结果失败 %s-%s-%s: %v",
+				task.Provider, task.Service, task.Channel, err)
+		}
+	}()
+	return true
+}
+
+// staggerDelay 返回监控项在节拍内的错峰偏移，未启用错峰时为 0
+func (s *Scheduler) staggerDelay(cfg *config.AppConfig, key config.MonitorKey, tick time.Duration) time.Duration {
+	if !cfg.ShouldStaggerProbes() {
+		return 0
+	}
+	return s.computeStaggerDelay(staggerPhase(key), tick, cfg.StaggerJitterDuration)
+}
+
+// computeStaggerDelay 计算错峰偏移：固定相位（见 staggerPhase）乘以节拍的前 80%
+// 配置 stagger_jitter 时叠加 ±jitter 的随机抖动，结果限制在节拍的前 80% 内
+func (s *Scheduler) computeStaggerDelay(phase float64, tick, jitter time.Duration) time.Duration {
+	spread := time.Duration(float64(tick) * staggerSpread)
+	delay := time.Duration(phase * float64(spread))
+	if jitter > 0 && s.rnd != nil {
+		delay += time.Duration(s.rnd.Int63n(int64(jitter)*2+1)) - jitter
+	}
+	return min(max(delay, 0), spread)
+}
+
+// wakeLocked 唤醒调度循环（调用方需持有 s.mu）
+func (s *Scheduler) wakeLocked() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
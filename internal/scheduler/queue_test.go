@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"container/heap"
+	"math/rand"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// TestComputeStaggerDelay 验证错峰偏移由相位决定，抖动不超过配置范围
+func TestComputeStaggerDelay(t *testing.T) {
+	s := &Scheduler{}
+	tick := time.Minute
+	spread := tick * 4 / 5
+
+	offset := s.computeStaggerDelay(0.25, tick, 0)
+	if offset != spread/4 {
+		t.Fatalf("相位 0.25 的偏移应为 %v, got %v", spread/4, offset)
+	}
+	if got := s.computeStaggerDelay(0, tick, 0); got != 0 {
+		t.Errorf("相位 0 的偏移应为 0, got %v", got)
+	}
+
+	// 抖动不超过配置范围
+	s.rnd = rand.New(rand.NewSource(1))
+	jitter := 5 * time.Second
+	for range 100 {
+		d := s.computeStaggerDelay(0.25, tick, jitter)
+		if d < max(offset-jitter, 0) || d > min(offset+jitter, spread) {
+			t.Fatalf("抖动超出范围: offset=%v delay=%v", offset, d)
+		}
+	}
+}
+
+// TestProbeQueue 验证调度队列按计划时刻排序、各监控项按自己的节拍排队，且增删监控项不影响其他监控项
+func TestProbeQueue(t *testing.T) {
+	monitors := []config.ServiceConfig{
+		{Provider: "a", Service: "cc", URL: "https://a.example.com"},
+		{Provider: "b", Service: "cc", URL: "https://b.example.com", AdaptivePolicy: config.AdaptiveConfig{IncidentIntervalDuration: 15 * time.Second}},
+		{Provider: "c", Service: "cc", URL: "https://c.example.com"},
+	}
+	cfg := &config.AppConfig{IntervalDuration: time.Minute, Monitors: monitors}
+	s := &Scheduler{cfg: cfg, adaptive: newAdaptiveTracker()}
+	now := time.Unix(1735689600, 0).Add(20 * time.Second)
+
+	s.rebuildQueue(now)
+	if len(s.queue) != 3 {
+		t.Fatalf("队列长度 = %d, want 3", len(s.queue))
+	}
+
+	planned := make(map[config.MonitorKey]time.Time)
+	for _, item := range s.queue {
+		task := cfg.Monitors[item.task]
+		if item.tick != time.Minute {
+			t.Errorf("%s 正常状态下节拍 = %v, want 1m", task.Provider, item.tick)
+		}
+		if !item.slot.Equal(item.slot.Truncate(item.tick)) || item.at.Before(now) || item.at.Before(item.slot) ||
+			item.at.Sub(item.slot) >= item.tick*4/5 {
+			t.Errorf("%s 计划时刻异常: slot=%v at=%v", task.Provider, item.slot, item.at)
+		}
+		planned[config.KeyOf(task)] = item.at
+	}
+
+	// 出队顺序按计划时刻递增
+	var prev time.Time
+	q := append(probeQueue(nil), s.queue...)
+	for q.Len() > 0 {
+		item := heap.Pop(&q).(*queueItem)
+		if item.at.Before(prev) {
+			t.Fatalf("出队顺序错误: %v 在 %v 之后", item.at, prev)
+		}
+		prev = item.at
+	}
+
+	// 到期后排入下一个节拍，相位不变
+	item := s.queue[0]
+	at := item.at
+	s.scheduleItem(cfg, item, item.slot.Add(item.tick))
+	if got := item.at.Sub(at); got != item.tick {
+		t.Errorf("下一次计划时刻应相隔一个节拍, got %v", got)
+	}
+
+	// 新增其他主机的监控项后重建队列，已有监控项的计划时刻不变
+	s.cfg = &config.AppConfig{IntervalDuration: time.Minute, Monitors: append([]config.ServiceConfig{{Provider: "new", Service: "cc", URL: "https://new.example.com"}}, monitors...)}
+	s.rebuildQueue(now)
+	for _, item := range s.queue {
+		key := config.KeyOf(s.cfg.Monitors[item.task])
+		if want, ok := planned[key]; ok && !item.at.Equal(want) {
+			t.Errorf("%s 计划时刻变化: %v -> %v", key, want, item.at)
+		}
+	}
+}
+
+// TestProbeQueueIncidentTick 验证配置 incident_interval 时正常监控项仍在整个巡检周期内错峰，
+// 只有故障中的监控项改用短节拍
+func TestProbeQueueIncidentTick(t *testing.T) {
+	policy := config.AdaptiveConfig{IncidentIntervalDuration: 10 * time.Second}
+	var monitors []config.ServiceConfig
+	for _, p := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		monitors = append(monitors, config.ServiceConfig{Provider: p, Service: "cc", URL: "https://relay.example.com", AdaptivePolicy: policy})
+	}
+	cfg := &config.AppConfig{IntervalDuration: time.Minute, Monitors: monitors}
+	s := &Scheduler{cfg: cfg, adaptive: newAdaptiveTracker()}
+	now := time.Unix(1735689600, 0)
+
+	s.rebuildQueue(now)
+	var first, last time.Duration
+	for i, item := range s.queue {
+		offset := item.at.Sub(item.slot)
+		if i == 0 || offset < first {
+			first = offset
+		}
+		last = max(last, offset)
+	}
+	if last-first <= 10*time.Second {
+		t.Errorf("正常监控项的错峰偏移只分布在 %v 内，应覆盖整个巡检周期", last-first)
+	}
+
+	// 故障中的监控项改用 incident_interval 节拍
+	failing := config.KeyOf(monitors[0])
+	if !s.adaptive.record(failing, 0, now) {
+		t.Fatal("进入故障状态应返回 true")
+	}
+	s.rebuildQueue(now)
+	for _, item := range s.queue {
+		want := time.Minute
+		if config.KeyOf(cfg.Monitors[item.task]) == failing {
+			want = 10 * time.Second
+		}
+		if item.tick != want {
+			t.Errorf("%s 节拍 = %v, want %v", cfg.Monitors[item.task].Provider, item.tick, want)
+		}
+	}
+	if s.adaptive.record(failing, 2, now) || !s.adaptive.record(failing, 1, now) {
+		t.Error("红黄之间切换不改变节拍，恢复绿色应改变节拍")
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math/rand"
 	"sync"
@@ -16,11 +17,15 @@ import (
 // Scheduler 调度器
 type Scheduler struct {
 	prober   *monitor.Prober
+	store    storage.Storage
 	interval time.Duration
-	ticker   *time.Ticker
+	wake     chan struct{} // 配置变化或停止时唤醒调度循环
 	running  bool
 	mu       sync.Mutex
-	rnd      *rand.Rand // 用于错峰调度的随机数生成器
+	rnd      *rand.Rand        // 用于错峰抖动的随机数生成器（仅调度循环使用）
+	sem      chan struct{}     // 全局并发信号量（受 mu 保护，max_concurrency 变化时替换）
+	queue    probeQueue        // 调度队列（仅调度循环使用）
+	queueCfg *config.AppConfig // 构建调度队列时的配置（仅调度循环使用）
 
 	// 配置引用（支持热更新）
 	cfg   *config.AppConfig
@@ -30,19 +35,39 @@ type Scheduler struct {
 	notifier   *notifier.Manager
 	notifierMu sync.RWMutex
 
-	// 防止重复触发
-	checkInProgress bool
-	checkMu         sync.Mutex
+	// 运行状态（受 checkMu 保护）
+	checkMu      sync.Mutex
+	inFlight     map[config.MonitorKey]bool // 进行中的探测，同一监控项不会并行探测
+	standby      bool                       // 备节点：不执行周期巡检（多副本主备模式）
+	activeSince  time.Time                  // 启动或由备节点转为主节点的时间
+	lastDispatch time.Time                  // 调度循环最近一次运行时间
+	lastProbeEnd time.Time                  // 最近一次探测完成时间
 
 	// 保存context用于TriggerNow
 	ctx context.Context
+
+	// 监控项归属过滤（分片模式），返回 false 的监控项由其他节点负责
+	taskFilter func(config.ServiceConfig) bool
+
+	// 结果输出（探测节点模式），设置后结果交给 resultSink，不再入库和触发告警
+	resultSink func(result *monitor.ProbeResult, attempts int)
+
+	// 自适应探测频率状态
+	adaptive *adaptiveTracker
+
+	// 按主机和服务商的并发限制与请求间隔
+	throttle *throttle
 }
 
 // NewScheduler 创建调度器
 func NewScheduler(store storage.Storage, interval time.Duration) *Scheduler {
 	return &Scheduler{
 		prober:   monitor.NewProber(store),
+		store:    store,
 		interval: interval,
+		adaptive: newAdaptiveTracker(),
+		throttle: newThrottle(),
+		inFlight: make(map[config.MonitorKey]bool),
 		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
@@ -55,7 +80,11 @@ func (s *Scheduler) Start(ctx context.Context, cfg *config.AppConfig) {
 		return
 	}
 	s.running = true
-	s.ticker = time.NewTicker(s.interval)
+	s.wake = make(chan struct{}, 1)
+	s.resizeSemLocked(cfg)
+	s.checkMu.Lock()
+	s.activeSince = time.Now()
+	s.checkMu.Unlock()
 	s.ctx = ctx // 保存context用于TriggerNow
 
 	// 保存初始配置
@@ -63,141 +92,15 @@ func (s *Scheduler) Start(ctx context.Context, cfg *config.AppConfig) {
 	s.cfg = cfg
 	s.cfgMu.Unlock()
 	s.mu.Unlock()
+	updateMonitorMetrics(cfg)
 
 	// 立即执行一次（不错峰，确保启动时快速得出结论）
-	go s.runChecks(ctx, false)
-
-	// 定时执行
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				log.Println("[Scheduler] 调度器已停止")
-				s.mu.Lock()
-				s.ticker.Stop()
-				s.running = false
-				s.mu.Unlock()
-				return
-
-			case <-s.ticker.C:
-				s.runChecks(ctx, true) // 周期性巡检启用错峰
-			}
-		}
-	}()
-
-	log.Printf("[Scheduler] 调度器已启动，间隔: %v", s.interval)
-}
-
-// runChecks 执行所有检查（防重复）
-// allowStagger 为 true 时，会在当前巡检周期内为不同监控项注入错峰延迟
-func (s *Scheduler) runChecks(ctx context.Context, allowStagger bool) {
-	// 防止重复执行
-	s.checkMu.Lock()
-	if s.checkInProgress {
-		log.Println("[Scheduler] 上一轮检查尚未完成，跳过本次")
-		s.checkMu.Unlock()
-		return
-	}
-	s.checkInProgress = true
-	s.checkMu.Unlock()
-
-	defer func() {
-		s.checkMu.Lock()
-		s.checkInProgress = false
-		s.checkMu.Unlock()
-	}()
-
-	// 获取当前配置（支持热更新）
-	s.cfgMu.RLock()
-	cfg := s.cfg
-	s.cfgMu.RUnlock()
-
-	if cfg == nil || len(cfg.Monitors) == 0 {
-		return
-	}
-
-	log.Printf("[Scheduler] 开始巡检 %d 个监控项", len(cfg.Monitors))
-
-	var wg sync.WaitGroup
-
-	// 并发控制：根据配置决定并发策略
-	monitorCount := len(cfg.Monitors)
-	maxConcurrency := cfg.MaxConcurrency
-
-	// MaxConcurrency 语义：
-	// - -1: 无限制，自动扩容到监控项数量
-	// - >0: 硬上限，严格限制并发数
-	if maxConcurrency == -1 {
-		// 无限制模式：每个监控项一个 goroutine
-		maxConcurrency = monitorCount
-		log.Printf("[Scheduler] 并发模式: 无限制 (并发数=%d)", maxConcurrency)
-	} else if monitorCount > maxConcurrency {
-		// 硬上限模式：监控数超过上限时会排队
-		log.Printf("[Scheduler] 并发模式: 硬上限 (上限=%d, 监控项=%d, 将分批执行)",
-			maxConcurrency, monitorCount)
-	} else {
-		log.Printf("[Scheduler] 并发模式: 正常 (并发数=%d, 监控项=%d)",
-			maxConcurrency, monitorCount)
-	}
-
-	// 限制并发数
-	sem := make(chan struct{}, maxConcurrency)
-
-	// 错峰策略：在周期内均匀分散探测
-	useStagger := allowStagger && cfg.ShouldStaggerProbes() && monitorCount > 1 && s.interval > 0
-	var baseDelay time.Duration
-	var jitterRange time.Duration
-	if useStagger {
-		baseDelay = s.interval / time.Duration(monitorCount)
-		if baseDelay <= 0 {
-			useStagger = false
-		} else {
-			jitterRange = baseDelay / 5 // ±20% 抖动
-			log.Printf("[Scheduler] 探测将按 %v 间隔错峰，抖动±%v", baseDelay, jitterRange)
-		}
-	}
+	go s.runAll(ctx)
 
-	for idx, task := range cfg.Monitors {
-		wg.Add(1)
-		go func(t config.ServiceConfig, index int) {
-			defer wg.Done()
-
-			// 错峰延迟（在获取信号量之前）
-			if useStagger {
-				delay := s.computeStaggerDelay(baseDelay, jitterRange, index)
-				if delay > 0 && !sleepWithContext(ctx, delay) {
-					return // context 取消
-				}
-			}
-
-			// 获取信号量
-			select {
-			case sem <- struct{}{}:
-			case <-ctx.Done():
-				return
-			}
-			defer func() { <-sem }()
-
-			// 执行探测
-			result := s.prober.Probe(ctx, &t)
-
-			// 保存结果
-			if err := s.prober.SaveResult(result); err != nil {
-				log.Printf("[Scheduler] 保存结果失败 %s-%s-%s: %v",
-					t.Provider, t.Service, t.Channel, err)
-			}
-
-			// 触发告警检查
-			s.notifierMu.RLock()
-			if s.notifier != nil {
-				s.notifier.NotifyIfNeeded(ctx, result)
-			}
-			s.notifierMu.RUnlock()
-		}(task, idx)
-	}
+	// 各监控项按各自的节拍独立排队探测
+	go s.loop(ctx)
 
-	wg.Wait()
-	log.Println("[Scheduler] 巡检完成")
+	log.Printf("[Scheduler] 调度器已启动，间隔: %v", s.interval)
 }
 
 // UpdateConfig 更新配置（热更新时调用）
@@ -206,18 +109,19 @@ func (s *Scheduler) UpdateConfig(cfg *config.AppConfig) {
 	s.cfg = cfg
 	s.cfgMu.Unlock()
 
-	// 如果配置中带有新的巡检间隔，动态调整 ticker
-	if cfg.IntervalDuration > 0 {
-		s.mu.Lock()
-		if s.interval != cfg.IntervalDuration {
-			s.interval = cfg.IntervalDuration
-			if s.ticker != nil {
-				s.ticker.Reset(s.interval)
-				log.Printf("[Scheduler] 巡检间隔已更新为: %v", s.interval)
-			}
-		}
-		s.mu.Unlock()
+	// 唤醒调度循环按新配置重建队列（各监控项的计划时刻不变，新增监控项在下一个节拍开始排队）
+	s.mu.Lock()
+	if cfg.IntervalDuration > 0 && s.interval != cfg.IntervalDuration {
+		s.interval = cfg.IntervalDuration
+		log.Printf("[Scheduler] 巡检间隔已更新为: %v", s.interval)
+	}
+	s.resizeSemLocked(cfg)
+	if s.running {
+		s.wakeLocked()
 	}
+	s.mu.Unlock()
+	s.adaptive.prune(cfg)
+	updateMonitorMetrics(cfg)
 
 	// 更新通知器配置
 	s.notifierMu.RLock()
@@ -226,7 +130,7 @@ func (s *Scheduler) UpdateConfig(cfg *config.AppConfig) {
 	}
 	s.notifierMu.RUnlock()
 
-	log.Printf("[Scheduler] 配置已更新，下次巡检将使用新配置")
+	log.Printf("[Scheduler] 配置已更新，后续探测将使用新配置")
 }
 
 // SetNotifier 设置通知管理器
@@ -251,50 +155,240 @@ func (s *Scheduler) TriggerNow() {
 	s.mu.Unlock()
 
 	if running && ctx != nil {
-		go s.runChecks(ctx, false) // 手动触发不错峰
+		go s.runAll(ctx) // 手动触发不错峰
 		log.Printf("[Scheduler] 已触发即时巡检")
 	}
 }
 
-// Stop 停止调度器
-func (s *Scheduler) Stop() {
+var (
+	// ErrSchedulerStopped 调度器未运行（如 --no-scheduler）
+	ErrSchedulerStopped = errors.New("调度器未运行")
+	// ErrProbeInFlight 监控项正在探测中（周期巡检或另一次即时探测）
+	ErrProbeInFlight = errors.New("监控项正在探测中")
+	// ErrProbeBudgetExhausted 服务商的额外探测预算已用完
+	ErrProbeBudgetExhausted = errors.New("服务商探测预算已用完")
+	// ErrStandby 备节点不保存结果、不发送告警（主备模式下由主节点负责）
+	ErrStandby = errors.New("当前节点为备节点，结果由主节点保存")
+)
+
+// ProbeOnce 立即探测单个监控项（不影响周期巡检）
+// 与周期巡检共用主机/服务商限流、全局并发信号量，并占用一次服务商额外探测预算；同一监控项正在探测时返回 ErrProbeInFlight
+// 未能探测时返回的结果为 nil；persist=true 时与周期巡检一样保存结果并触发告警检查，保存失败时同时返回结果和错误
+// 备节点上 persist=true 返回 ErrStandby，只有主节点保存结果和发送告警
+func (s *Scheduler) ProbeOnce(ctx context.Context, task config.ServiceConfig, persist bool) (*monitor.ProbeResult, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	running := s.running
+	sem := s.sem
+	s.mu.Unlock()
+	cfg := s.currentConfig()
+	if !running || cfg == nil {
+		return nil, ErrSchedulerStopped
+	}
+	if persist && s.isStandby() {
+		return nil, ErrStandby
+	}
 
-	if s.running && s.ticker != nil {
-		s.ticker.Stop()
-		s.running = false
+	key := config.KeyOf(task)
+	s.checkMu.Lock()
+	if s.inFlight[key] {
+		s.checkMu.Unlock()
+		return nil, ErrProbeInFlight
+	}
+	if !s.adaptive.takeExtra(cfg, task.Provider, time.Now()) {
+		s.checkMu.Unlock()
+		return nil, ErrProbeBudgetExhausted
 	}
+	s.inFlight[key] = true
+	s.checkMu.Unlock()
 
-	s.prober.Close()
+	schedulerInFlight.Add(1)
+	defer func() {
+		schedulerInFlight.Add(-1)
+		s.checkMu.Lock()
+		delete(s.inFlight, key)
+		s.lastProbeEnd = time.Now()
+		s.checkMu.Unlock()
+	}()
+
+	result := s.probeThrottled(ctx, cfg, &task, sem, time.Now())
+	if result == nil {
+		return nil, ctx.Err()
+	}
+	if !persist {
+		return result, nil
+	}
+	if s.isStandby() {
+		return result, ErrStandby // 探测期间失去主节点身份
+	}
+	return result, s.handleResult(ctx, result, 1, task.Labels)
 }
 
-// computeStaggerDelay 计算错峰延迟时间
-// 基准延迟 + 随机抖动（±20%）
-func (s *Scheduler) computeStaggerDelay(baseDelay, jitterRange time.Duration, index int) time.Duration {
-	delay := baseDelay * time.Duration(index)
-	if jitterRange <= 0 || s.rnd == nil {
-		if delay < 0 {
-			return 0
-		}
-		return delay
+// probeWithConfirm 执行探测；结果为红色且细分状态在确认策略内时，间隔重试直到非红色或达到重试上限
+// 重试计入服务商探测预算，预算不足时停止重试；scheduled 为计划探测时刻，用于统计调度延迟
+// 返回最终结果和尝试次数，只有最终结果会被记录和用于告警判定；context 取消时结果为 nil
+func (s *Scheduler) probeWithConfirm(ctx context.Context, cfg *config.AppConfig, task *config.ServiceConfig, sem chan struct{}, scheduled time.Time) (*monitor.ProbeResult, int) {
+	policy := task.ConfirmPolicy
+	result := s.probeThrottled(ctx, cfg, task, sem, scheduled)
+	if result == nil {
+		return nil, 0
 	}
+	attempts := 1
 
-	max := int64(jitterRange)
-	if max <= 0 {
-		if delay < 0 {
-			return 0
+	for attempts <= policy.RetryCount() && result.Status == 0 && policy.ShouldRetry(string(result.SubStatus)) {
+		if !sleepWithContext(ctx, policy.DelayDuration) || !s.adaptive.takeExtra(cfg, task.Provider, time.Now()) {
+			break
+		}
+		log.Printf("[Scheduler] %s-%s-%s 探测失败（%s），第 %d 次重试确认",
+			task.Provider, task.Service, task.Channel, result.SubStatus, attempts)
+		retry := s.probeThrottled(ctx, cfg, task, sem, time.Now())
+		if retry == nil {
+			break
 		}
-		return delay
+		result = retry
+		attempts++
+	}
+	return result, attempts
+}
+
+// probeThrottled 在主机/服务商并发限制、主机请求间隔和全局并发信号量内执行一次探测
+// 每次尝试单独获取名额，重试等待期间不占用；获取名额后记录相对 ready 的调度延迟；context 取消时返回 nil
+func (s *Scheduler) probeThrottled(ctx context.Context, cfg *config.AppConfig, task *config.ServiceConfig, sem chan struct{}, ready time.Time) *monitor.ProbeResult {
+	schedulerQueued.Add(1)
+	release := s.throttle.acquire(ctx, cfg, task)
+	if release == nil {
+		schedulerQueued.Add(-1)
+		return nil
+	}
+	defer release()
+
+	select {
+	case sem <- struct{}{}:
+		schedulerQueued.Add(-1)
+	case <-ctx.Done():
+		schedulerQueued.Add(-1)
+		return nil
+	}
+	defer func() { <-sem }()
+
+	schedulerLag.Observe(max(time.Since(ready), 0).Seconds(), task.Provider)
+	return s.prober.Probe(ctx, task)
+}
+
+// handleResult 处理探测结果：探测节点模式交给 resultSink，否则保存并触发告警检查（labels 为监控项标签，用于告警路由）
+func (s *Scheduler) handleResult(ctx context.Context, result *monitor.ProbeResult, attempts int, labels map[string]string) error {
+	recordProbeMetrics(result)
+	if s.resultSink != nil {
+		s.resultSink(result, attempts)
+		return nil
+	}
+
+	err := s.store.SaveRecord(&storage.ProbeRecord{
+		Provider:  result.Provider,
+		Service:   result.Service,
+		Channel:   result.Channel,
+		Status:    result.Status,
+		SubStatus: result.SubStatus,
+		Latency:   result.Latency,
+		Timestamp: result.Timestamp,
+		Attempts:  attempts,
+	})
+
+	s.notifierMu.RLock()
+	if s.notifier != nil {
+		s.notifier.NotifyIfNeeded(ctx, result, labels)
+	}
+	s.notifierMu.RUnlock()
+	return err
+}
+
+// SetStandby 切换备节点状态（多副本主备模式下由选主结果驱动）
+// 备节点不执行周期巡检，也就不会保存结果和发送告警；转为主节点后立即触发一次巡检
+func (s *Scheduler) SetStandby(standby bool) {
+	s.checkMu.Lock()
+	changed := s.standby != standby
+	s.standby = standby
+	if changed && !standby {
+		s.activeSince = time.Now()
+	}
+	s.checkMu.Unlock()
+
+	if !changed {
+		return
+	}
+	if standby {
+		log.Printf("[Scheduler] 已切换为备节点，暂停周期巡检")
+		return
 	}
+	log.Printf("[Scheduler] 已切换为主节点，恢复周期巡检")
+	s.TriggerNow()
+}
+
+// currentConfig 返回当前配置
+func (s *Scheduler) currentConfig() *config.AppConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
+// SetTaskFilter 设置监控项归属过滤（分片模式，需在 Start 之前调用）
+func (s *Scheduler) SetTaskFilter(filter func(config.ServiceConfig) bool) {
+	s.taskFilter = filter
+}
+
+// SetResultSink 设置结果输出（探测节点模式，需在 Start 之前调用）
+// 设置后探测结果不再写入本地存储，也不触发告警，由中心服务统一处理
+func (s *Scheduler) SetResultSink(sink func(result *monitor.ProbeResult, attempts int)) {
+	s.resultSink = sink
+}
+
+func (s *Scheduler) isStandby() bool {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+	return s.standby
+}
+
+// Status 调度器运行状态
+type Status struct {
+	Running        bool          // 是否已启动（--no-scheduler 时为 false）
+	Standby        bool          // 是否为备节点（多副本主备模式）
+	Interval       time.Duration // 当前巡检间隔
+	ActiveSince    time.Time     // 启动或由备节点转为主节点的时间
+	LastDispatch   time.Time     // 调度循环最近一次运行时间（至少每个巡检间隔一次；零值表示尚未运行）
+	LastProbeEnd   time.Time     // 最近一次探测完成时间（零值表示尚未完成）
+	InFlight       int           // 进行中的探测数
+	NotifierActive bool          // 通知管理器是否已初始化
+}
+
+// Status 返回调度器运行状态（用于健康检查）
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	st := Status{Running: s.running, Interval: s.interval}
+	s.mu.Unlock()
 
-	// 随机抖动：±jitterRange
-	offset := s.rnd.Int63n(max*2+1) - max
-	delay += time.Duration(offset)
-	if delay < 0 {
-		return 0
+	s.checkMu.Lock()
+	st.Standby = s.standby
+	st.ActiveSince = s.activeSince
+	st.LastDispatch = s.lastDispatch
+	st.LastProbeEnd = s.lastProbeEnd
+	st.InFlight = len(s.inFlight)
+	s.checkMu.Unlock()
+
+	st.NotifierActive = s.GetNotifier() != nil
+
+	return st
+}
+
+// Stop 停止调度器
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		s.running = false
+		s.wakeLocked()
 	}
-	return delay
+
+	s.prober.Close()
 }
 
 // sleepWithContext 在指定时间内休眠，支持 context 取消
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// TestProbeOnceGuards 验证即时探测与周期巡检共用进行中标记、服务商预算和并发信号量
+func TestProbeOnceGuards(t *testing.T) {
+	task := config.ServiceConfig{Provider: "a", Service: "cc", URL: "http://127.0.0.1:1"}
+	budgeted := config.ServiceConfig{Provider: "b", Service: "cc", URL: "http://127.0.0.1:1"}
+	cfg := &config.AppConfig{
+		IntervalDuration: time.Minute,
+		Monitors:         []config.ServiceConfig{task, budgeted},
+		ProviderBudgets:  map[string]int{"b": 60}, // 常规探测已用满预算
+	}
+
+	s := NewScheduler(nil, time.Minute)
+	ctx := context.Background()
+	if _, err := s.ProbeOnce(ctx, task, false); !errors.Is(err, ErrSchedulerStopped) {
+		t.Fatalf("调度器未运行时 err = %v, want ErrSchedulerStopped", err)
+	}
+
+	s.running = true
+	s.cfg = cfg
+	s.sem = make(chan struct{}, 1)
+
+	s.inFlight[config.KeyOf(task)] = true
+	if result, err := s.ProbeOnce(ctx, task, false); result != nil || !errors.Is(err, ErrProbeInFlight) {
+		t.Errorf("探测进行中时 result=%v err=%v, want ErrProbeInFlight", result, err)
+	}
+	delete(s.inFlight, config.KeyOf(task))
+
+	if _, err := s.ProbeOnce(ctx, budgeted, false); !errors.Is(err, ErrProbeBudgetExhausted) {
+		t.Errorf("预算用完时 err = %v, want ErrProbeBudgetExhausted", err)
+	}
+
+	// 全局并发名额被占满时排队等待，超时后不执行探测
+	s.sem <- struct{}{}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if result, err := s.ProbeOnce(timeoutCtx, task, false); result != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("并发名额占满时 result=%v err=%v, want DeadlineExceeded", result, err)
+	}
+	<-s.sem
+
+	if result, err := s.ProbeOnce(ctx, task, false); result == nil || err != nil {
+		t.Fatalf("ProbeOnce() = %v, %v", result, err)
+	}
+	if len(s.inFlight) != 0 || len(s.sem) != 0 {
+		t.Errorf("探测结束后 inFlight=%v sem=%d，应已释放", s.inFlight, len(s.sem))
+	}
+}
+
+// TestProbeOnceStandby 验证备节点只能执行不保存的探测，保存结果和告警由主节点负责
+func TestProbeOnceStandby(t *testing.T) {
+	task := config.ServiceConfig{Provider: "a", Service: "cc", URL: "http://127.0.0.1:1"}
+	s := NewScheduler(nil, time.Minute)
+	s.running = true
+	s.cfg = &config.AppConfig{IntervalDuration: time.Minute, Monitors: []config.ServiceConfig{task}}
+	s.sem = make(chan struct{}, 1)
+	s.SetStandby(true)
+
+	ctx := context.Background()
+	if result, err := s.ProbeOnce(ctx, task, true); result != nil || !errors.Is(err, ErrStandby) {
+		t.Errorf("备节点 persist=true 时 result=%v err=%v, want ErrStandby", result, err)
+	}
+	if result, err := s.ProbeOnce(ctx, task, false); result == nil || err != nil {
+		t.Errorf("备节点不保存的探测应可执行: %v, %v", result, err)
+	}
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// throttle 按主机和服务商限制同时进行的探测，并保证同一主机相邻两次请求的最小间隔
+// 限制值每次获取时从配置读取，支持热更新
+type throttle struct {
+	mu       sync.Mutex
+	hosts    map[string]int       // 主机 -> 进行中的探测数
+	provider map[string]int       // 服务商 -> 进行中的探测数
+	nextAt   map[string]time.Time // 主机 -> 下一次允许发起请求的时间
+	changed  chan struct{}        // 有探测结束时关闭并替换，唤醒等待者
+}
+
+func newThrottle() *throttle {
+	return &throttle{
+		hosts:    make(map[string]int),
+		provider: make(map[string]int),
+		nextAt:   make(map[string]time.Time),
+		changed:  make(chan struct{}),
+	}
+}
+
+// acquire 等待主机和服务商的并发名额以及主机请求间隔，成功后返回释放函数
+// context 取消时返回 nil
+func (t *throttle) acquire(ctx context.Context, cfg *config.AppConfig, task *config.ServiceConfig) func() {
+	host := probeHost(task.URL)
+	for {
+		t.mu.Lock()
+		now := time.Now()
+		hostFree := cfg.MaxConcurrencyPerHost <= 0 || t.hosts[host] < cfg.MaxConcurrencyPerHost
+		providerFree := cfg.MaxConcurrencyPerProvider <= 0 || t.provider[task.Provider] < cfg.MaxConcurrencyPerProvider
+		wait := t.nextAt[host].Sub(now)
+
+		if hostFree && providerFree && wait <= 0 {
+			t.hosts[host]++
+			t.provider[task.Provider]++
+			if cfg.HostMinSpacingDuration > 0 {
+				t.nextAt[host] = now.Add(cfg.HostMinSpacingDuration)
+			}
+			t.mu.Unlock()
+			return func() { t.release(host, task.Provider) }
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		// 名额已满时等待其他探测结束；仅受请求间隔限制时等待到允许的时间
+		var tm *time.Timer
+		var timer <-chan time.Time
+		if hostFree && providerFree {
+			tm = time.NewTimer(wait)
+			timer = tm.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timer:
+		}
+		if tm != nil {
+			tm.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (t *throttle) release(host, provider string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.hosts[host]--; t.hosts[host] <= 0 {
+		delete(t.hosts, host)
+	}
+	if t.provider[provider]--; t.provider[provider] <= 0 {
+		delete(t.provider, provider)
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// probeHost 返回探测地址的主机（含端口，小写），用于按主机限流
+func probeHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Host)
+}
+
+// staggerPhase 返回监控项的错峰相位（[0, 1) 内，乘以错峰区间得到偏移）
+// 相位只由 provider/service/channel 的哈希决定，重启、重新分片和增删其他监控项都不改变探测节奏；
+// 同一主机的监控项可能落在相近时刻，由 host_min_spacing 负责拉开
+func staggerPhase(key config.MonitorKey) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key.String()))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor/internal/config"
+)
+
+// TestThrottleLimits 验证同一主机的并发上限和请求间隔
+func TestThrottleLimits(t *testing.T) {
+	cfg := &config.AppConfig{MaxConcurrencyPerHost: 1, HostMinSpacingDuration: 20 * time.Millisecond}
+	tasks := []config.ServiceConfig{
+		{Provider: "A", URL: "https://relay.example.com/v1/messages"},
+		{Provider: "A", URL: "https://RELAY.example.com/v1/chat/completions"},
+		{Provider: "B", URL: "https://other.example.com/v1/messages"},
+	}
+
+	th := newThrottle()
+	var (
+		wg      sync.WaitGroup
+		active  atomic.Int32
+		peak    atomic.Int32
+		mu      sync.Mutex
+		started []time.Time
+	)
+	for i := range tasks[:2] {
+		wg.Add(1)
+		go func(task *config.ServiceConfig) {
+			defer wg.Done()
+			release := th.acquire(context.Background(), cfg, task)
+			n := active.Add(1)
+			if n > peak.Load() {
+				peak.Store(n)
+			}
+			mu.Lock()
+			started = append(started, time.Now())
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			active.Add(-1)
+			release()
+		}(&tasks[i])
+	}
+	wg.Wait()
+
+	if peak.Load() != 1 {
+		t.Errorf("同一主机并发 = %d, want 1", peak.Load())
+	}
+	if gap := started[1].Sub(started[0]); gap < cfg.HostMinSpacingDuration {
+		t.Errorf("同一主机请求间隔 = %v, want >= %v", gap, cfg.HostMinSpacingDuration)
+	}
+
+	// 其他主机不受影响；context 取消时放弃等待
+	hold := th.acquire(context.Background(), cfg, &tasks[0])
+	if release := th.acquire(context.Background(), cfg, &tasks[2]); release == nil {
+		t.Fatal("其他主机应立即获得名额")
+	} else {
+		release()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if th.acquire(ctx, cfg, &tasks[1]) != nil {
+		t.Error("名额被占用且 context 取消时应返回 nil")
+	}
+	hold()
+}
+
+// TestStaggerPhase 验证相位只取决于监控项标识，增加同一主机的监控项不改变其他监控项的相位
+func TestStaggerPhase(t *testing.T) {
+	monitors := []config.ServiceConfig{
+		{Provider: "a", Service: "cc", URL: "https://relay.example.com/1"},
+		{Provider: "a", Service: "cx", URL: "https://relay.example.com/2"},
+		{Provider: "b", Service: "cc", URL: "https://other.example.com/1"},
+	}
+	phases := make(map[config.MonitorKey]float64)
+	for _, m := range monitors {
+		key := config.KeyOf(m)
+		phases[key] = staggerPhase(key)
+		if p := phases[key]; p < 0 || p >= 1 {
+			t.Errorf("%s 相位超出 [0, 1): %v", key, p)
+		}
+	}
+	if phases[config.KeyOf(monitors[0])] == phases[config.KeyOf(monitors[1])] {
+		t.Error("同一主机的不同监控项相位不应相同")
+	}
+
+	// 同一主机新增监控项后重建调度队列，已有监控项的计划时刻不变
+	cfg := &config.AppConfig{IntervalDuration: time.Minute, Monitors: monitors}
+	s := NewScheduler(nil, time.Minute)
+	now := time.Date(2026, 1, 1, 0, 0, 10, 0, time.UTC)
+	s.cfg = cfg
+	s.rebuildQueue(now)
+	before := make(map[config.MonitorKey]time.Time)
+	for _, item := range s.queue {
+		before[config.KeyOf(cfg.Monitors[item.task])] = item.at
+	}
+
+	grown := cfg.Clone()
+	grown.Monitors = append(grown.Monitors, config.ServiceConfig{Provider: "c", Service: "cc", URL: "https://relay.example.com/3"})
+	s.cfg = grown
+	s.rebuildQueue(now)
+	for _, item := range s.queue {
+		key := config.KeyOf(grown.Monitors[item.task])
+		if at, ok := before[key]; ok && !item.at.Equal(at) {
+			t.Errorf("%s 计划时刻随同主机新增监控项变化: %v -> %v", key, at, item.at)
+		}
+	}
+}
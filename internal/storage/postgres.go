@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"monitor/internal/config"
@@ -102,7 +105,9 @@ func (s *PostgresStorage) Init() error {
 		status INTEGER NOT NULL,
 		sub_status TEXT NOT NULL DEFAULT '',
 		latency INTEGER NOT NULL,
-		timestamp BIGINT NOT NULL
+		timestamp BIGINT NOT NULL,
+		region TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 1
 	);
 	`
 
@@ -111,12 +116,55 @@ func (s *PostgresStorage) Init() error {
 		return fmt.Errorf("初始化 PostgreSQL 数据库失败: %w", err)
 	}
 
-	// 兼容旧数据库：添加缺失的列
-	if err := s.ensureSubStatusColumn(); err != nil {
-		return err
+	// 多副本选主租约表
+	leaseSchema := `
+	CREATE TABLE IF NOT EXISTS cluster_leases (
+		name TEXT PRIMARY KEY,
+		holder TEXT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+	`
+	if _, err := s.pool.Exec(ctx, leaseSchema); err != nil {
+		return fmt.Errorf("创建租约表失败: %w", err)
 	}
-	if err := s.ensureChannelColumn(); err != nil {
-		return err
+
+	// 分片模式成员心跳表
+	memberSchema := `
+	CREATE TABLE IF NOT EXISTS cluster_members (
+		node_id TEXT PRIMARY KEY,
+		heartbeat_at TIMESTAMPTZ NOT NULL
+	);
+	`
+	if _, err := s.pool.Exec(ctx, memberSchema); err != nil {
+		return fmt.Errorf("创建集群成员表失败: %w", err)
+	}
+
+	// 配置修订历史表
+	revisionSchema := `
+	CREATE TABLE IF NOT EXISTS config_revisions (
+		id BIGSERIAL PRIMARY KEY,
+		version TEXT NOT NULL,
+		node_id TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL,
+		summary TEXT NOT NULL DEFAULT '',
+		diff TEXT NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL
+	);
+	`
+	if _, err := s.pool.Exec(ctx, revisionSchema); err != nil {
+		return fmt.Errorf("创建配置修订表失败: %w", err)
+	}
+
+	// 兼容旧数据库：添加缺失的列
+	for _, col := range []struct{ name, definition string }{
+		{"sub_status", "TEXT NOT NULL DEFAULT ''"},
+		{"channel", "TEXT NOT NULL DEFAULT ''"},
+		{"region", "TEXT NOT NULL DEFAULT ''"},
+		{"attempts", "INTEGER NOT NULL DEFAULT 1"},
+	} {
+		if err := s.ensureColumn(col.name, col.definition); err != nil {
+			return err
+		}
 	}
 
 	// 在列迁移完成后创建索引
@@ -142,58 +190,34 @@ func (s *PostgresStorage) Init() error {
 	//
 	// 性能验证：EXPLAIN ANALYZE SELECT ... WHERE provider=? AND service=? AND channel=? AND timestamp>=?
 	indexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_probe_history_psc_ts_cover
+	CREATE INDEX IF NOT EXISTS idx_probe_history_psc_ts_region_cover
 	ON probe_history (provider, service, channel, timestamp DESC)
-	INCLUDE (status, sub_status, latency, id);
+	INCLUDE (status, sub_status, latency, id, region);
 	`
 	if _, err := s.pool.Exec(ctx, indexSQL); err != nil {
 		return fmt.Errorf("创建覆盖索引失败: %w", err)
 	}
 
-	return nil
-}
-
-// ensureSubStatusColumn 在旧表上添加 sub_status 列（向后兼容）
-func (s *PostgresStorage) ensureSubStatusColumn() error {
-	ctx := s.effectiveCtx()
-	// PostgreSQL 使用 information_schema 查询列是否存在
-	checkQuery := `
-		SELECT COUNT(*)
-		FROM information_schema.columns
-		WHERE table_name = 'probe_history' AND column_name = 'sub_status'
-	`
-
-	var count int
-	err := s.pool.QueryRow(ctx, checkQuery).Scan(&count)
-	if err != nil {
-		return fmt.Errorf("查询 PostgreSQL 表结构失败: %w", err)
-	}
-
-	if count > 0 {
-		return nil // 列已存在，无需添加
-	}
-
-	// 添加列
-	alterQuery := `ALTER TABLE probe_history ADD COLUMN sub_status TEXT NOT NULL DEFAULT ''`
-	if _, err := s.pool.Exec(ctx, alterQuery); err != nil {
-		return fmt.Errorf("添加 sub_status 列失败: %w", err)
+	// 旧版覆盖索引不含 region 列，新索引建好后删除
+	if _, err := s.pool.Exec(ctx, `DROP INDEX IF EXISTS idx_probe_history_psc_ts_cover`); err != nil {
+		return fmt.Errorf("删除旧覆盖索引失败: %w", err)
 	}
 
-	log.Println("[Storage] 已为 probe_history 表添加 sub_status 列 (PostgreSQL)")
 	return nil
 }
 
-// ensureChannelColumn 在旧表上添加 channel 列（向后兼容）
-func (s *PostgresStorage) ensureChannelColumn() error {
+// ensureColumn 在旧表上添加缺失的列（向后兼容），definition 为列类型及约束
+func (s *PostgresStorage) ensureColumn(column, definition string) error {
 	ctx := s.effectiveCtx()
+	// PostgreSQL 使用 information_schema 查询列是否存在
 	checkQuery := `
 		SELECT COUNT(*)
 		FROM information_schema.columns
-		WHERE table_name = 'probe_history' AND column_name = 'channel'
+		WHERE table_name = 'probe_history' AND column_name = $1
 	`
 
 	var count int
-	err := s.pool.QueryRow(ctx, checkQuery).Scan(&count)
+	err := s.pool.QueryRow(ctx, checkQuery, column).Scan(&count)
 	if err != nil {
 		return fmt.Errorf("查询 PostgreSQL 表结构失败: %w", err)
 	}
@@ -203,12 +227,12 @@ func (s *PostgresStorage) ensureChannelColumn() error {
 	}
 
 	// 添加列
-	alterQuery := `ALTER TABLE probe_history ADD COLUMN channel TEXT NOT NULL DEFAULT ''`
+	alterQuery := fmt.Sprintf(`ALTER TABLE probe_history ADD COLUMN %s %s`, column, definition)
 	if _, err := s.pool.Exec(ctx, alterQuery); err != nil {
-		return fmt.Errorf("添加 channel 列失败: %w", err)
+		return fmt.Errorf("添加 %s 列失败: %w", column, err)
 	}
 
-	log.Println("[Storage] 已为 probe_history 表添加 channel 列 (PostgreSQL)")
+	log.Printf("[Storage] 已为 probe_history 表添加 %s 列 (PostgreSQL)", column)
 	return nil
 }
 
@@ -277,12 +301,186 @@ func (s *PostgresStorage) Close() error {
 	return nil
 }
 
+// AcquireLease 获取或续约租约（过期判断使用数据库时间）
+func (s *PostgresStorage) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		INSERT INTO cluster_leases (name, holder, expires_at)
+		VALUES ($1, $2, now() + $3::float8 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+			SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+			WHERE cluster_leases.holder = EXCLUDED.holder OR cluster_leases.expires_at < now()
+		RETURNING holder
+	`
+
+	var current string
+	err := s.pool.QueryRow(ctx, query, name, holder, float64(ttl.Milliseconds())).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil // 租约由其他节点持有且未过期
+	}
+	if err != nil {
+		return false, fmt.Errorf("获取租约失败: %w", err)
+	}
+	return current == holder, nil
+}
+
+// ReleaseLease 释放租约（让其他副本立即接管）
+func (s *PostgresStorage) ReleaseLease(name, holder string) error {
+	ctx := s.effectiveCtx()
+	if _, err := s.pool.Exec(ctx, `DELETE FROM cluster_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return fmt.Errorf("释放租约失败: %w", err)
+	}
+	return nil
+}
+
+// GetLease 查询租约当前持有者
+func (s *PostgresStorage) GetLease(name string) (*Lease, error) {
+	ctx := s.effectiveCtx()
+	lease := &Lease{Name: name}
+	err := s.pool.QueryRow(ctx, `SELECT holder, expires_at FROM cluster_leases WHERE name = $1`, name).
+		Scan(&lease.Holder, &lease.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询租约失败: %w", err)
+	}
+	return lease, nil
+}
+
+// Heartbeat 登记或刷新节点心跳（同时清理长时间无心跳的成员记录）
+func (s *PostgresStorage) Heartbeat(nodeID string) error {
+	ctx := s.effectiveCtx()
+	query := `
+		INSERT INTO cluster_members (node_id, heartbeat_at) VALUES ($1, now())
+		ON CONFLICT (node_id) DO UPDATE SET heartbeat_at = EXCLUDED.heartbeat_at
+	`
+	if _, err := s.pool.Exec(ctx, query, nodeID); err != nil {
+		return fmt.Errorf("登记节点心跳失败: %w", err)
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM cluster_members WHERE heartbeat_at < now() - interval '1 day'`); err != nil {
+		log.Printf("[Storage] 清理过期集群成员失败: %v", err)
+	}
+	return nil
+}
+
+// ListMembers 返回最近 ttl 内有心跳的成员
+func (s *PostgresStorage) ListMembers(ttl time.Duration) ([]Member, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		SELECT node_id, heartbeat_at FROM cluster_members
+		WHERE heartbeat_at > now() - $1::float8 * interval '1 millisecond'
+		ORDER BY node_id
+	`
+	rows, err := s.pool.Query(ctx, query, float64(ttl.Milliseconds()))
+	if err != nil {
+		return nil, fmt.Errorf("查询集群成员失败: %w", err)
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.NodeID, &m.HeartbeatAt); err != nil {
+			return nil, fmt.Errorf("读取集群成员失败: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// RemoveMember 移除节点
+func (s *PostgresStorage) RemoveMember(nodeID string) error {
+	ctx := s.effectiveCtx()
+	if _, err := s.pool.Exec(ctx, `DELETE FROM cluster_members WHERE node_id = $1`, nodeID); err != nil {
+		return fmt.Errorf("移除集群成员失败: %w", err)
+	}
+	return nil
+}
+
+// SaveRevision 保存配置修订记录
+func (s *PostgresStorage) SaveRevision(rev *ConfigRevision) error {
+	ctx := s.effectiveCtx()
+	query := `
+		INSERT INTO config_revisions (version, node_id, source, summary, diff, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := s.pool.QueryRow(ctx, query, rev.Version, rev.NodeID, rev.Source, rev.Summary, rev.Diff, rev.CreatedAt.Unix()).
+		Scan(&rev.ID)
+	if err != nil {
+		return fmt.Errorf("保存配置修订失败: %w", err)
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM config_revisions WHERE id <= $1`, rev.ID-MaxConfigRevisions); err != nil {
+		log.Printf("[Storage] 清理旧配置修订失败: %v", err)
+	}
+	return nil
+}
+
+// ListRevisions 按时间倒序返回最近 limit 条配置修订记录
+func (s *PostgresStorage) ListRevisions(limit int) ([]ConfigRevision, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		SELECT id, version, node_id, source, summary, diff, created_at
+		FROM config_revisions
+		ORDER BY id DESC
+		LIMIT $1
+	`
+	rows, err := s.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询配置修订失败: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []ConfigRevision
+	for rows.Next() {
+		var rev ConfigRevision
+		var createdAt int64
+		if err := rows.Scan(&rev.ID, &rev.Version, &rev.NodeID, &rev.Source, &rev.Summary, &rev.Diff, &createdAt); err != nil {
+			return nil, fmt.Errorf("读取配置修订失败: %w", err)
+		}
+		rev.CreatedAt = time.Unix(createdAt, 0)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision 查询单条配置修订记录
+func (s *PostgresStorage) GetRevision(id int64) (*ConfigRevision, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		SELECT id, version, node_id, source, summary, diff, created_at
+		FROM config_revisions
+		WHERE id = $1
+	`
+	var rev ConfigRevision
+	var createdAt int64
+	err := s.pool.QueryRow(ctx, query, id).
+		Scan(&rev.ID, &rev.Version, &rev.NodeID, &rev.Source, &rev.Summary, &rev.Diff, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询配置修订失败: %w", err)
+	}
+	rev.CreatedAt = time.Unix(createdAt, 0)
+	return &rev, nil
+}
+
+// Ping 检查数据库连接
+func (s *PostgresStorage) Ping() error {
+	if err := s.pool.Ping(s.effectiveCtx()); err != nil {
+		return fmt.Errorf("数据库不可用: %w", err)
+	}
+	return nil
+}
+
 // SaveRecord 保存探测记录
 func (s *PostgresStorage) SaveRecord(record *ProbeRecord) error {
 	ctx := s.effectiveCtx()
 	query := `
-		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp, region, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id
 	`
 
@@ -294,6 +492,8 @@ func (s *PostgresStorage) SaveRecord(record *ProbeRecord) error {
 		string(record.SubStatus),
 		record.Latency,
 		record.Timestamp,
+		record.Region,
+		max(record.Attempts, 1),
 	).Scan(&record.ID)
 
 	if err != nil {
@@ -303,11 +503,65 @@ func (s *PostgresStorage) SaveRecord(record *ProbeRecord) error {
 	return nil
 }
 
+// SaveIngestBatch 在一个事务中保存节点上报的一批记录，跳过已存在的记录
+// 事务内先对 region 加事务级咨询锁，避免多个副本同时处理同一批重放请求时都判定为不存在
+func (s *PostgresStorage) SaveIngestBatch(records []*ProbeRecord) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	ctx := s.effectiveCtx()
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("开启 PostgreSQL 事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "ingest:"+records[0].Region); err != nil {
+		return 0, fmt.Errorf("获取上报锁失败: %w", err)
+	}
+
+	query := `
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp, region, attempts)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+		WHERE NOT EXISTS (
+			SELECT 1 FROM probe_history
+			WHERE provider = $1 AND service = $2 AND channel = $3 AND timestamp = $7 AND region = $8
+		)
+		RETURNING id
+	`
+	saved := 0
+	for _, record := range records {
+		err := tx.QueryRow(ctx, query,
+			record.Provider,
+			record.Service,
+			record.Channel,
+			record.Status,
+			string(record.SubStatus),
+			record.Latency,
+			record.Timestamp,
+			record.Region,
+			max(record.Attempts, 1),
+		).Scan(&record.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("保存 PostgreSQL 记录失败: %w", err)
+		}
+		saved++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("提交 PostgreSQL 事务失败: %w", err)
+	}
+	return saved, nil
+}
+
 // GetLatest 获取最新记录
 func (s *PostgresStorage) GetLatest(provider, service, channel string) (*ProbeRecord, error) {
 	ctx := s.effectiveCtx()
 	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region
 		FROM probe_history
 		WHERE provider = $1 AND service = $2 AND channel = $3
 		ORDER BY timestamp DESC
@@ -325,6 +579,7 @@ func (s *PostgresStorage) GetLatest(provider, service, channel string) (*ProbeRe
 		&subStatusStr,
 		&record.Latency,
 		&record.Timestamp,
+		&record.Region,
 	)
 
 	if err != nil {
@@ -345,7 +600,7 @@ func (s *PostgresStorage) GetHistory(provider, service, channel string, since ti
 	// 使用 ORDER BY timestamp DESC 以利用索引（索引是 timestamp DESC）
 	// 返回前在 Go 代码中反转为时间升序
 	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region
 		FROM probe_history
 		WHERE provider = $1 AND service = $2 AND channel = $3 AND timestamp >= $4
 		ORDER BY timestamp DESC
@@ -370,6 +625,7 @@ func (s *PostgresStorage) GetHistory(provider, service, channel string, since ti
 			&subStatusStr,
 			&record.Latency,
 			&record.Timestamp,
+			&record.Region,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("扫描 PostgreSQL 记录失败: %w", err)
@@ -409,3 +665,121 @@ func (s *PostgresStorage) CleanOldRecords(days int) error {
 
 	return nil
 }
+
+// ExportRecords 按过滤条件流式导出探测记录（id 游标分批读取）
+func (s *PostgresStorage) ExportRecords(filter ExportFilter, fn func(*ProbeRecord) error) error {
+	ctx := s.effectiveCtx()
+
+	// $1 固定为游标 id
+	conditions := []string{"id > $1", "timestamp >= $2"}
+	baseArgs := []interface{}{filter.Since.Unix()}
+	addCondition := func(column string, value interface{}) {
+		baseArgs = append(baseArgs, value)
+		conditions = append(conditions, fmt.Sprintf("%s $%d", column, len(baseArgs)+1))
+	}
+	if !filter.Until.IsZero() {
+		addCondition("timestamp <", filter.Until.Unix())
+	}
+	if filter.Provider != "" {
+		addCondition("provider =", filter.Provider)
+	}
+	if filter.Service != "" {
+		addCondition("service =", filter.Service)
+	}
+	if filter.Channel != "" {
+		addCondition("channel =", filter.Channel)
+	}
+	if filter.Region != "" {
+		addCondition("region =", filter.Region)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region, attempts
+		FROM probe_history
+		WHERE %s
+		ORDER BY id ASC
+		LIMIT %d
+	`, strings.Join(conditions, " AND "), exportBatchSize)
+
+	var lastID int64
+	for {
+		args := append([]interface{}{lastID}, baseArgs...)
+		batch, err := s.queryExportBatch(ctx, query, args)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range batch {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// queryExportBatch 读取一批导出记录（读取完毕即归还连接）
+func (s *PostgresStorage) queryExportBatch(ctx context.Context, query string, args []interface{}) ([]*ProbeRecord, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("导出 PostgreSQL 记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make([]*ProbeRecord, 0, exportBatchSize)
+	for rows.Next() {
+		var record ProbeRecord
+		var subStatusStr string
+		if err := rows.Scan(
+			&record.ID,
+			&record.Provider,
+			&record.Service,
+			&record.Channel,
+			&record.Status,
+			&subStatusStr,
+			&record.Latency,
+			&record.Timestamp,
+			&record.Region,
+			&record.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("扫描 PostgreSQL 导出记录失败: %w", err)
+		}
+		record.SubStatus = SubStatus(subStatusStr)
+		batch = append(batch, &record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代 PostgreSQL 导出记录失败: %w", err)
+	}
+
+	return batch, nil
+}
+
+// Stats 返回按监控项分组的记录统计
+func (s *PostgresStorage) Stats() (*DBStats, error) {
+	ctx := s.effectiveCtx()
+
+	rows, err := s.pool.Query(ctx, statsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("查询 PostgreSQL 统计信息失败: %w", err)
+	}
+	defer rows.Close()
+
+	var series []SeriesStats
+	for rows.Next() {
+		var ss SeriesStats
+		if err := rows.Scan(&ss.Provider, &ss.Service, &ss.Channel, &ss.Count, &ss.Oldest, &ss.Newest); err != nil {
+			return nil, fmt.Errorf("读取 PostgreSQL 统计信息失败: %w", err)
+		}
+		series = append(series, ss)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取 PostgreSQL 统计信息失败: %w", err)
+	}
+
+	return newDBStats(series), nil
+}
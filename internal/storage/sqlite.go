@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite" // 纯Go实现的SQLite驱动
@@ -64,7 +65,9 @@ func (s *SQLiteStorage) Init() error {
 		status INTEGER NOT NULL,
 		sub_status TEXT NOT NULL DEFAULT '',
 		latency INTEGER NOT NULL,
-		timestamp INTEGER NOT NULL
+		timestamp INTEGER NOT NULL,
+		region TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 1
 	);
 	`
 
@@ -74,11 +77,15 @@ func (s *SQLiteStorage) Init() error {
 	}
 
 	// 兼容旧数据库：添加缺失的列
-	if err := s.ensureSubStatusColumn(); err != nil {
-		return err
-	}
-	if err := s.ensureChannelColumn(); err != nil {
-		return err
+	for _, col := range []struct{ name, definition string }{
+		{"sub_status", "TEXT NOT NULL DEFAULT ''"},
+		{"channel", "TEXT NOT NULL DEFAULT ''"},
+		{"region", "TEXT NOT NULL DEFAULT ''"},
+		{"attempts", "INTEGER NOT NULL DEFAULT 1"},
+	} {
+		if err := s.ensureColumn(col.name, col.definition); err != nil {
+			return err
+		}
 	}
 
 	// 在列迁移完成后创建索引
@@ -87,7 +94,7 @@ func (s *SQLiteStorage) Init() error {
 	// - 复合索引专为核心查询优化：GetLatest() 和 GetHistory()
 	// - 所有业务查询都包含完整的 (provider, service, channel) 等值条件
 	// - timestamp DESC 支持时间范围查询和排序，避免额外排序开销
-	// - 包含查询所需的所有字段（status, sub_status, latency），尽量减少回表
+	// - 包含查询所需的所有字段（status, sub_status, latency, region），尽量减少回表
 	// - 列顺序遵循 B-Tree 最佳实践：等值列在前，范围/排序列在后
 	//
 	// 性能优化：
@@ -102,63 +109,39 @@ func (s *SQLiteStorage) Init() error {
 	//
 	// 性能验证：EXPLAIN QUERY PLAN SELECT ... WHERE provider=? AND service=? AND channel=? AND timestamp>=?
 	indexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_probe_history_psc_ts_cover
-	ON probe_history(provider, service, channel, timestamp DESC, status, sub_status, latency);
+	CREATE INDEX IF NOT EXISTS idx_probe_history_psc_ts_region_cover
+	ON probe_history(provider, service, channel, timestamp DESC, status, sub_status, latency, region);
 	`
 	if _, err := s.db.ExecContext(ctx, indexSQL); err != nil {
 		return fmt.Errorf("创建覆盖索引失败: %w", err)
 	}
 
-	return nil
-}
-
-// ensureSubStatusColumn 在旧表上添加 sub_status 列（向后兼容）
-func (s *SQLiteStorage) ensureSubStatusColumn() error {
-	ctx := s.effectiveCtx()
-	rows, err := s.db.QueryContext(ctx, `PRAGMA table_info(probe_history)`)
-	if err != nil {
-		return fmt.Errorf("查询表结构失败: %w", err)
+	// 旧版覆盖索引不含 region 列，新索引建好后删除
+	if _, err := s.db.ExecContext(ctx, `DROP INDEX IF EXISTS idx_probe_history_psc_ts_cover`); err != nil {
+		return fmt.Errorf("删除旧覆盖索引失败: %w", err)
 	}
-	defer rows.Close()
 
-	hasColumn := false
-	for rows.Next() {
-		var (
-			cid          int
-			name         string
-			colType      string
-			notNull      int
-			defaultValue sql.NullString
-			pk           int
-		)
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
-			return fmt.Errorf("扫描表结构失败: %w", err)
-		}
-		if name == "sub_status" {
-			hasColumn = true
-			break
-		}
-	}
-
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("遍历表结构失败: %w", err)
-	}
-
-	if hasColumn {
-		return nil // 列已存在，无需添加
-	}
-
-	// 添加列
-	if _, err := s.db.ExecContext(ctx, `ALTER TABLE probe_history ADD COLUMN sub_status TEXT NOT NULL DEFAULT ''`); err != nil {
-		return fmt.Errorf("添加 sub_status 列失败: %w", err)
+	// 配置修订历史表
+	revisionSchema := `
+	CREATE TABLE IF NOT EXISTS config_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version TEXT NOT NULL,
+		node_id TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL,
+		summary TEXT NOT NULL DEFAULT '',
+		diff TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL
+	);
+	`
+	if _, err := s.db.ExecContext(ctx, revisionSchema); err != nil {
+		return fmt.Errorf("创建配置修订表失败: %w", err)
 	}
 
-	fmt.Println("[Storage] 已为 probe_history 表添加 sub_status 列")
 	return nil
 }
 
-// ensureChannelColumn 在旧表上添加 channel 列（向后兼容）
-func (s *SQLiteStorage) ensureChannelColumn() error {
+// ensureColumn 在旧表上添加缺失的列（向后兼容），definition 为列类型及约束
+func (s *SQLiteStorage) ensureColumn(column, definition string) error {
 	ctx := s.effectiveCtx()
 	rows, err := s.db.QueryContext(ctx, `PRAGMA table_info(probe_history)`)
 	if err != nil {
@@ -179,7 +162,7 @@ func (s *SQLiteStorage) ensureChannelColumn() error {
 		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
 			return fmt.Errorf("扫描表结构失败: %w", err)
 		}
-		if name == "channel" {
+		if name == column {
 			hasColumn = true
 			break
 		}
@@ -194,11 +177,11 @@ func (s *SQLiteStorage) ensureChannelColumn() error {
 	}
 
 	// 添加列
-	if _, err := s.db.ExecContext(ctx, `ALTER TABLE probe_history ADD COLUMN channel TEXT NOT NULL DEFAULT ''`); err != nil {
-		return fmt.Errorf("添加 channel 列失败: %w", err)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE probe_history ADD COLUMN %s %s`, column, definition)); err != nil {
+		return fmt.Errorf("添加 %s 列失败: %w", column, err)
 	}
 
-	fmt.Println("[Storage] 已为 probe_history 表添加 channel 列")
+	fmt.Printf("[Storage] 已为 probe_history 表添加 %s 列\n", column)
 	return nil
 }
 
@@ -265,17 +248,94 @@ func (s *SQLiteStorage) MigrateChannelData(mappings []ChannelMigrationMapping) e
 	return nil
 }
 
+// SaveRevision 保存配置修订记录
+func (s *SQLiteStorage) SaveRevision(rev *ConfigRevision) error {
+	ctx := s.effectiveCtx()
+	query := `
+		INSERT INTO config_revisions (version, node_id, source, summary, diff, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := s.db.ExecContext(ctx, query, rev.Version, rev.NodeID, rev.Source, rev.Summary, rev.Diff, rev.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("保存配置修订失败: %w", err)
+	}
+	rev.ID, _ = result.LastInsertId()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM config_revisions WHERE id <= ?`, rev.ID-MaxConfigRevisions); err != nil {
+		log.Printf("[Storage] 清理旧配置修订失败: %v", err)
+	}
+	return nil
+}
+
+// ListRevisions 按时间倒序返回最近 limit 条配置修订记录
+func (s *SQLiteStorage) ListRevisions(limit int) ([]ConfigRevision, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		SELECT id, version, node_id, source, summary, diff, created_at
+		FROM config_revisions
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询配置修订失败: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []ConfigRevision
+	for rows.Next() {
+		var rev ConfigRevision
+		var createdAt int64
+		if err := rows.Scan(&rev.ID, &rev.Version, &rev.NodeID, &rev.Source, &rev.Summary, &rev.Diff, &createdAt); err != nil {
+			return nil, fmt.Errorf("读取配置修订失败: %w", err)
+		}
+		rev.CreatedAt = time.Unix(createdAt, 0)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision 查询单条配置修订记录
+func (s *SQLiteStorage) GetRevision(id int64) (*ConfigRevision, error) {
+	ctx := s.effectiveCtx()
+	query := `
+		SELECT id, version, node_id, source, summary, diff, created_at
+		FROM config_revisions
+		WHERE id = ?
+	`
+	var rev ConfigRevision
+	var createdAt int64
+	err := s.db.QueryRowContext(ctx, query, id).
+		Scan(&rev.ID, &rev.Version, &rev.NodeID, &rev.Source, &rev.Summary, &rev.Diff, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询配置修订失败: %w", err)
+	}
+	rev.CreatedAt = time.Unix(createdAt, 0)
+	return &rev, nil
+}
+
 // Close 关闭数据库
 func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
+// Ping 检查数据库连接（执行一次查询，确认数据库文件可读）
+func (s *SQLiteStorage) Ping() error {
+	var one int
+	if err := s.db.QueryRowContext(s.effectiveCtx(), `SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("数据库不可用: %w", err)
+	}
+	return nil
+}
+
 // SaveRecord 保存探测记录
 func (s *SQLiteStorage) SaveRecord(record *ProbeRecord) error {
 	ctx := s.effectiveCtx()
 	query := `
-		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp, region, attempts)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := s.db.ExecContext(ctx, query,
@@ -286,6 +346,8 @@ func (s *SQLiteStorage) SaveRecord(record *ProbeRecord) error {
 		string(record.SubStatus),
 		record.Latency,
 		record.Timestamp,
+		record.Region,
+		max(record.Attempts, 1),
 	)
 
 	if err != nil {
@@ -297,11 +359,61 @@ func (s *SQLiteStorage) SaveRecord(record *ProbeRecord) error {
 	return nil
 }
 
+// SaveIngestBatch 在一个事务中保存节点上报的一批记录，跳过已存在的记录
+func (s *SQLiteStorage) SaveIngestBatch(records []*ProbeRecord) (int, error) {
+	ctx := s.effectiveCtx()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO probe_history (provider, service, channel, status, sub_status, latency, timestamp, region, attempts)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM probe_history
+			WHERE provider = ? AND service = ? AND channel = ? AND timestamp = ? AND region = ?
+		)
+	`
+	saved := 0
+	for _, record := range records {
+		result, err := tx.ExecContext(ctx, query,
+			record.Provider,
+			record.Service,
+			record.Channel,
+			record.Status,
+			string(record.SubStatus),
+			record.Latency,
+			record.Timestamp,
+			record.Region,
+			max(record.Attempts, 1),
+			record.Provider,
+			record.Service,
+			record.Channel,
+			record.Timestamp,
+			record.Region,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("保存记录失败: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			record.ID, _ = result.LastInsertId()
+			saved++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return saved, nil
+}
+
 // GetLatest 获取最新记录
 func (s *SQLiteStorage) GetLatest(provider, service, channel string) (*ProbeRecord, error) {
 	ctx := s.effectiveCtx()
 	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region
 		FROM probe_history
 		WHERE provider = ? AND service = ? AND channel = ?
 		ORDER BY timestamp DESC
@@ -319,6 +431,7 @@ func (s *SQLiteStorage) GetLatest(provider, service, channel string) (*ProbeReco
 		&subStatusStr,
 		&record.Latency,
 		&record.Timestamp,
+		&record.Region,
 	)
 
 	if err == sql.ErrNoRows {
@@ -339,7 +452,7 @@ func (s *SQLiteStorage) GetHistory(provider, service, channel string, since time
 	// 使用 ORDER BY timestamp DESC 以利用索引（索引是 timestamp DESC）
 	// 返回前在 Go 代码中反转为时间升序
 	query := `
-		SELECT id, provider, service, channel, status, sub_status, latency, timestamp
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region
 		FROM probe_history
 		WHERE provider = ? AND service = ? AND channel = ? AND timestamp >= ?
 		ORDER BY timestamp DESC
@@ -364,6 +477,7 @@ func (s *SQLiteStorage) GetHistory(provider, service, channel string, since time
 			&subStatusStr,
 			&record.Latency,
 			&record.Timestamp,
+			&record.Region,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("扫描记录失败: %w", err)
@@ -403,3 +517,122 @@ func (s *SQLiteStorage) CleanOldRecords(days int) error {
 
 	return nil
 }
+
+// ExportRecords 按过滤条件流式导出探测记录（id 游标分批读取）
+// 每批读取完成后再回调，避免长时间占用 SQLite 唯一连接阻塞调度器写入
+func (s *SQLiteStorage) ExportRecords(filter ExportFilter, fn func(*ProbeRecord) error) error {
+	ctx := s.effectiveCtx()
+
+	conditions := []string{"id > ?", "timestamp >= ?"}
+	baseArgs := []interface{}{filter.Since.Unix()}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		baseArgs = append(baseArgs, filter.Until.Unix())
+	}
+	if filter.Provider != "" {
+		conditions = append(conditions, "provider = ?")
+		baseArgs = append(baseArgs, filter.Provider)
+	}
+	if filter.Service != "" {
+		conditions = append(conditions, "service = ?")
+		baseArgs = append(baseArgs, filter.Service)
+	}
+	if filter.Channel != "" {
+		conditions = append(conditions, "channel = ?")
+		baseArgs = append(baseArgs, filter.Channel)
+	}
+	if filter.Region != "" {
+		conditions = append(conditions, "region = ?")
+		baseArgs = append(baseArgs, filter.Region)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, provider, service, channel, status, sub_status, latency, timestamp, region, attempts
+		FROM probe_history
+		WHERE %s
+		ORDER BY id ASC
+		LIMIT %d
+	`, strings.Join(conditions, " AND "), exportBatchSize)
+
+	var lastID int64
+	for {
+		args := append([]interface{}{lastID}, baseArgs...)
+		batch, err := s.queryExportBatch(ctx, query, args)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range batch {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// queryExportBatch 读取一批导出记录（读取完毕即释放连接）
+func (s *SQLiteStorage) queryExportBatch(ctx context.Context, query string, args []interface{}) ([]*ProbeRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("导出记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make([]*ProbeRecord, 0, exportBatchSize)
+	for rows.Next() {
+		var record ProbeRecord
+		var subStatusStr string
+		if err := rows.Scan(
+			&record.ID,
+			&record.Provider,
+			&record.Service,
+			&record.Channel,
+			&record.Status,
+			&subStatusStr,
+			&record.Latency,
+			&record.Timestamp,
+			&record.Region,
+			&record.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("扫描导出记录失败: %w", err)
+		}
+		record.SubStatus = SubStatus(subStatusStr)
+		batch = append(batch, &record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("迭代导出记录失败: %w", err)
+	}
+
+	return batch, nil
+}
+
+// Stats 返回按监控项分组的记录统计
+func (s *SQLiteStorage) Stats() (*DBStats, error) {
+	ctx := s.effectiveCtx()
+
+	rows, err := s.db.QueryContext(ctx, statsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("查询统计信息失败: %w", err)
+	}
+	defer rows.Close()
+
+	var series []SeriesStats
+	for rows.Next() {
+		var ss SeriesStats
+		if err := rows.Scan(&ss.Provider, &ss.Service, &ss.Channel, &ss.Count, &ss.Oldest, &ss.Newest); err != nil {
+			return nil, fmt.Errorf("读取统计信息失败: %w", err)
+		}
+		series = append(series, ss)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取统计信息失败: %w", err)
+	}
+
+	return newDBStats(series), nil
+}
@@ -30,18 +30,23 @@ type ProbeRecord struct {
 	SubStatus SubStatus // 细分状态（黄色/红色原因）
 	Latency   int       // ms
 	Timestamp int64     // Unix时间戳
+	Region    string    // 探测地域（本机探测为空，远程探测节点上报时为节点所属地域）
+	Attempts  int       // 探测尝试次数（含失败确认重试），仅导出时读取，GetLatest/GetHistory 不返回
 }
 
 // TimePoint 时间轴数据点（用于前端展示）
 type TimePoint struct {
 	Time         string       `json:"time"`          // 格式化时间标签（如 "15:04" 或 "2006-01-02"）
 	Timestamp    int64        `json:"timestamp"`     // Unix 时间戳（秒），用于前端精确时间计算
-	Status       int          `json:"status"`        // 状态码：1=绿，0=红，2=黄，-1=缺失（bucket内最后一条记录）
+	Status       int          `json:"status"`        // 状态码：1=绿，0=红，2=黄，-1=缺失，-2=未排期（bucket内最后一条记录）
 	Latency      int          `json:"latency"`       // 平均延迟（毫秒）
 	Availability float64      `json:"availability"`  // 可用率百分比（0-100），缺失时为 -1
 	StatusCounts StatusCounts `json:"status_counts"` // 各状态计数
 }
 
+// StatusNotScheduled 时间块内按探测日程未安排探测（区别于应有数据却缺失的 -1）
+const StatusNotScheduled = -2
+
 // StatusCounts 记录一个时间块内各状态出现次数
 type StatusCounts struct {
 	Available   int `json:"available"`   // 绿色（可用）次数
@@ -69,6 +74,61 @@ type ChannelMigrationMapping struct {
 	Channel  string
 }
 
+// ExportFilter 导出过滤条件
+// Provider/Service/Channel/Region 为空表示不过滤该字段
+type ExportFilter struct {
+	Provider string
+	Service  string
+	Channel  string
+	Region   string
+	Since    time.Time // 起始时间（含）
+	Until    time.Time // 结束时间（不含），零值表示不限
+}
+
+// SeriesStats 单个监控项（provider/service/channel）的记录统计
+type SeriesStats struct {
+	Provider string
+	Service  string
+	Channel  string
+	Count    int64
+	Oldest   int64 // 最早记录的 Unix 时间戳
+	Newest   int64 // 最新记录的 Unix 时间戳
+}
+
+// DBStats 数据库统计信息
+type DBStats struct {
+	TotalRecords int64
+	Oldest       int64 // 最早记录的 Unix 时间戳（无记录时为 0）
+	Newest       int64 // 最新记录的 Unix 时间戳（无记录时为 0）
+	Series       []SeriesStats
+}
+
+// newDBStats 由各监控项统计汇总出整体统计
+func newDBStats(series []SeriesStats) *DBStats {
+	stats := &DBStats{Series: series}
+	for _, ss := range series {
+		stats.TotalRecords += ss.Count
+		if stats.Oldest == 0 || ss.Oldest < stats.Oldest {
+			stats.Oldest = ss.Oldest
+		}
+		if ss.Newest > stats.Newest {
+			stats.Newest = ss.Newest
+		}
+	}
+	return stats
+}
+
+// statsQuery 按监控项分组统计记录数和时间范围（SQLite 与 PostgreSQL 通用）
+const statsQuery = `
+	SELECT provider, service, channel, COUNT(*), MIN(timestamp), MAX(timestamp)
+	FROM probe_history
+	GROUP BY provider, service, channel
+	ORDER BY provider, service, channel
+`
+
+// exportBatchSize 导出时每批读取的记录数（游标分页）
+const exportBatchSize = 1000
+
 // Storage 存储接口
 //
 // 索引依赖说明：
@@ -82,6 +142,9 @@ type Storage interface {
 	// Close 关闭存储
 	Close() error
 
+	// Ping 检查数据库连接是否可用（用于就绪探针，超时由 WithContext 控制）
+	Ping() error
+
 	// WithContext 返回绑定指定 context 的存储实例
 	// 用于支持请求级别的超时和取消，不修改原实例，便于并发请求安全复用
 	WithContext(ctx context.Context) Storage
@@ -104,4 +167,90 @@ type Storage interface {
 	// MigrateChannelData 将 channel 为空的历史记录迁移到最新配置
 	// 注意：一次性操作，无需索引优化
 	MigrateChannelData(mappings []ChannelMigrationMapping) error
+
+	// ExportRecords 按过滤条件流式导出探测记录（按 id 升序，逐条回调）
+	// 使用 id 游标分批读取，不会一次性加载全部数据，也不会长时间占用连接
+	// 注意：过滤条件不一定命中索引，属于低频运维操作（与 CleanOldRecords 类似）
+	ExportRecords(filter ExportFilter, fn func(*ProbeRecord) error) error
+
+	// Stats 返回按监控项分组的记录统计（全表聚合，仅用于运维命令）
+	Stats() (*DBStats, error)
+}
+
+// Lease 分布式租约（多副本选主）
+type Lease struct {
+	Name      string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// LeaseStore 分布式租约存储（可选能力，仅 PostgreSQL 实现）
+// 过期判断使用数据库时间，避免各副本时钟偏差导致同时持有租约
+type LeaseStore interface {
+	// AcquireLease 获取或续约租约：租约空闲、已过期或已由 holder 持有时成功，返回是否持有
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+
+	// ReleaseLease 释放 holder 持有的租约（未持有时为空操作）
+	ReleaseLease(name, holder string) error
+
+	// GetLease 查询租约当前持有者（不存在时返回 nil）
+	GetLease(name string) (*Lease, error)
+}
+
+// Member 集群成员（分片模式）
+type Member struct {
+	NodeID      string
+	HeartbeatAt time.Time
+}
+
+// MemberStore 集群成员心跳存储（可选能力，仅 PostgreSQL 实现）
+type MemberStore interface {
+	// Heartbeat 登记或刷新节点心跳
+	Heartbeat(nodeID string) error
+
+	// ListMembers 返回最近 ttl 内有心跳的成员（按 node_id 排序，使用数据库时间判断）
+	ListMembers(ttl time.Duration) ([]Member, error)
+
+	// RemoveMember 移除节点（正常退出时调用，便于其他节点立即接管其分片）
+	RemoveMember(nodeID string) error
+}
+
+// 配置修订来源
+const (
+	RevisionSourceStartup = "startup" // 服务启动（配置版本与上次记录不同）
+	RevisionSourceReload  = "reload"  // 配置文件热更新
+	RevisionSourceAdmin   = "admin"   // 管理 API 修改
+)
+
+// MaxConfigRevisions 保留的配置修订记录数量上限（超出后删除最旧的记录）
+const MaxConfigRevisions = 1000
+
+// ConfigRevision 配置修订记录（每次配置生效时记录与上一版本的差异）
+type ConfigRevision struct {
+	ID        int64
+	Version   string    // 配置版本（配置文件内容哈希）
+	NodeID    string    // 记录该修订的节点（cluster.node_id，单实例模式为空）
+	Source    string    // 变更来源：startup / reload / admin
+	Summary   string    // 变更摘要
+	Diff      string    // 结构化差异（JSON，密钥已脱敏），启动记录为空
+	CreatedAt time.Time // 生效时间
+}
+
+// RevisionStore 配置修订历史存储（可选能力，SQLite 与 PostgreSQL 均实现）
+type RevisionStore interface {
+	// SaveRevision 保存修订记录（填充 ID，并清理超出 MaxConfigRevisions 的旧记录）
+	SaveRevision(rev *ConfigRevision) error
+
+	// ListRevisions 按时间倒序返回最近 limit 条修订记录
+	ListRevisions(limit int) ([]ConfigRevision, error)
+
+	// GetRevision 查询单条修订记录（不存在时返回 nil）
+	GetRevision(id int64) (*ConfigRevision, error)
+}
+
+// IngestStore 批量保存探测节点上报结果（可选能力，SQLite 与 PostgreSQL 均实现）
+type IngestStore interface {
+	// SaveIngestBatch 在一个事务中保存一批记录，跳过 (provider, service, channel, region, timestamp) 已存在的记录，
+	// 返回实际写入的条数；任一条写入失败时整批回滚，节点重放或重试同一批结果不会产生重复记录
+	SaveIngestBatch(records []*ProbeRecord) (int, error)
 }